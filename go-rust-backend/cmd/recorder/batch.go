@@ -0,0 +1,126 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path/filepath"
+	"time"
+
+	"github.com/vedantwpatil/Screen-Capture/internal/config"
+	"github.com/vedantwpatil/Screen-Capture/internal/editing"
+	"github.com/vedantwpatil/Screen-Capture/internal/recording"
+	"github.com/vedantwpatil/Screen-Capture/internal/tracking"
+)
+
+// batchResultJSON is the --json form of one clip's outcome
+type batchResultJSON struct {
+	Input       string  `json:"input"`
+	Output      string  `json:"output"`
+	OK          bool    `json:"ok"`
+	Error       string  `json:"error,omitempty"`
+	ElapsedSecs float64 `json:"elapsed_secs"`
+}
+
+// pendingEdits finds every recording in dir that has cursor data but no
+// edited version yet
+func pendingEdits(dir string) ([]editing.Request, error) {
+	library, err := recording.NewLibrary(dir)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := library.List()
+	if err != nil {
+		return nil, err
+	}
+
+	var reqs []editing.Request
+	// Oldest first, so clips are edited in the order they were recorded
+	for i := len(entries) - 1; i >= 0; i-- {
+		e := entries[i]
+		if e.CursorPath == "" || e.EditedPath != "" {
+			continue
+		}
+		history, startTime, err := tracking.LoadHistory(e.CursorPath)
+		if err != nil {
+			return nil, err
+		}
+		reqs = append(reqs, editing.Request{
+			InputPath:    e.Path,
+			OutputPath:   editing.EditedPath(e.Path),
+			MouseHistory: history,
+			StartTime:    startTime,
+			SkipClicks:   savedSkips(e.Path),
+		})
+	}
+	return reqs, nil
+}
+
+// editAll edits every pending recording in dir and prints a summary to out.
+// It returns an error if any clip failed; the others are still edited.
+func editAll(ctx context.Context, cfg *config.Config, dir string, out io.Writer, jsonOut bool) error {
+	reqs, err := pendingEdits(dir)
+	if err != nil {
+		return err
+	}
+	if len(reqs) == 0 {
+		if jsonOut {
+			return printJSON([]batchResultJSON{})
+		}
+		fmt.Fprintf(out, "Nothing to edit in %s: every recording with cursor data already has an edit\n", dir)
+		return nil
+	}
+
+	workers := 1
+	if cfg.Processing.Parallel {
+		workers = cfg.Processing.Workers
+	}
+	if workers > 1 {
+		// Interleaved per-clip progress lines would be unreadable
+		for i := range reqs {
+			reqs[i].Progress = func(float32) {}
+		}
+	}
+
+	results := editing.EditBatch(ctx, cfg, reqs, workers, func(position, total int, req editing.Request) {
+		if !jsonOut {
+			fmt.Fprintf(out, "\n▶ Clip %d/%d: %s\n", position, total, filepath.Base(req.InputPath))
+		}
+	})
+
+	failed := 0
+	summary := make([]batchResultJSON, 0, len(results))
+	for _, r := range results {
+		item := batchResultJSON{
+			Input:       r.Request.InputPath,
+			Output:      r.Request.OutputPath,
+			OK:          r.Err == nil,
+			ElapsedSecs: r.Elapsed.Seconds(),
+		}
+		if r.Err != nil {
+			failed++
+			item.Error = r.Err.Error()
+		}
+		summary = append(summary, item)
+	}
+
+	if jsonOut {
+		if err := printJSON(summary); err != nil {
+			return err
+		}
+	} else {
+		fmt.Fprintf(out, "\nEdited %d/%d clip(s)\n", len(results)-failed, len(results))
+		for _, r := range results {
+			if r.Err != nil {
+				fmt.Fprintf(out, "  ✗ %s: %v\n", filepath.Base(r.Request.InputPath), r.Err)
+			} else {
+				fmt.Fprintf(out, "  ✓ %s (%s)\n", filepath.Base(r.Request.OutputPath), r.Elapsed.Round(time.Second))
+			}
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d clip(s) failed", failed, len(results))
+	}
+	return nil
+}
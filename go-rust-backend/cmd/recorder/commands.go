@@ -0,0 +1,484 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"image"
+	"io"
+	"io/fs"
+	"log/slog"
+	"os"
+	"os/signal"
+	"runtime"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/vedantwpatil/Screen-Capture/internal/config"
+	"github.com/vedantwpatil/Screen-Capture/internal/diagnostics"
+	"github.com/vedantwpatil/Screen-Capture/internal/editing"
+	"github.com/vedantwpatil/Screen-Capture/internal/markers"
+	"github.com/vedantwpatil/Screen-Capture/internal/media"
+	"github.com/vedantwpatil/Screen-Capture/internal/progress"
+	"github.com/vedantwpatil/Screen-Capture/internal/project"
+	"github.com/vedantwpatil/Screen-Capture/internal/recording"
+	"github.com/vedantwpatil/Screen-Capture/internal/session"
+	"github.com/vedantwpatil/Screen-Capture/internal/tracking"
+	"github.com/vedantwpatil/Screen-Capture/internal/video"
+	"github.com/vedantwpatil/Screen-Capture/pkg/focusframe"
+)
+
+// Exit codes shared by every subcommand
+const (
+	exitOK          = 0
+	exitFailure     = 1
+	exitUsage       = 2 // Bad arguments or configuration
+	exitInterrupted = 130
+)
+
+// command is a parsed non-interactive subcommand
+type command struct {
+	name string
+	args []string // Positional arguments after the subcommand name
+
+	json        bool
+	duration    time.Duration // record: stop after this long; 0 waits for Ctrl+C
+	cursorPath  string        // edit, export-markers: cursor data, defaults to the recording's sidecar
+	cursorSpace image.Point   // edit: screen size the cursor data was recorded on, when not the video's
+	outputPath  string        // edit: defaults to <name>-edited.mp4; export-markers: <name>.<format>
+	format      string        // export-markers: edl, csv or fcpxml
+
+	progressJSON bool   // edit: stream progress events as JSON lines instead of the human summary
+	prune        string // list: delete recordings older than this age
+	all          bool   // edit: the argument is a directory of recordings
+	preview      bool   // edit: render a quick low-resolution preview instead
+}
+
+var commandUsage = []string{
+	"record <name> [--duration 2m] [--json]   record until Ctrl+C or the duration elapses",
+	"edit <file.mp4> [--cursor data.json] [--cursor-space 1440x900] [--output out.mp4] [--preview] [--json]",
+	"edit --all <dir> [--json]                edit every recording with cursor data and no edit",
+	"list [--prune 30d] [--json]              list recordings, optionally deleting old ones",
+	"export-markers <file.mp4> [--format edl|csv|fcpxml] [--cursor data.json] [--output file]",
+	"devices [--json]                         list capture devices",
+	"version                                  print the build version",
+	"doctor [--json]                          print version and environment details for bug reports",
+	"probe <file> [--json]                    show stream information",
+	"stitch <name> <take.mp4[@5s-1m20s]>...   join takes into one recording and edit it",
+}
+
+// parseCommand reads the subcommand and its flags from args. Flags may come
+// before or after the positional arguments.
+func parseCommand(args []string) (*command, error) {
+	cmd := &command{name: args[0]}
+	fs := flag.NewFlagSet(cmd.name, flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	fs.BoolVar(&cmd.json, "json", false, "print machine-readable JSON to stdout")
+
+	wantArgs := 0
+	switch cmd.name {
+	case "record":
+		fs.DurationVar(&cmd.duration, "duration", 0, "stop recording after this long")
+		wantArgs = 1
+	case "edit":
+		fs.StringVar(&cmd.cursorPath, "cursor", "", "cursor data file")
+		fs.Func("cursor-space", "screen size the cursor data was recorded on, e.g. 1440x900", func(value string) error {
+			var err error
+			cmd.cursorSpace, err = parseSize(value)
+			return err
+		})
+		fs.StringVar(&cmd.outputPath, "output", "", "edited video path")
+		fs.BoolVar(&cmd.all, "all", false, "edit every pending recording in the directory")
+		fs.BoolVar(&cmd.preview, "preview", false, "render a quick 480p preview to <name>-preview.mp4")
+		wantArgs = 1
+	case "list":
+		fs.StringVar(&cmd.prune, "prune", "", "delete recordings older than this age, e.g. 30d")
+	case "export-markers":
+		fs.StringVar(&cmd.format, "format", "edl", "marker file format")
+		fs.StringVar(&cmd.cursorPath, "cursor", "", "cursor data file")
+		fs.StringVar(&cmd.outputPath, "output", "", "marker file path")
+		wantArgs = 1
+	case "devices", "version", "doctor":
+	case "probe":
+		wantArgs = 1
+	case "stitch":
+		wantArgs = -1 // A name and at least two clips
+	default:
+		return nil, fmt.Errorf("unknown command %q", cmd.name)
+	}
+
+	rest := args[1:]
+	for {
+		if err := fs.Parse(rest); err != nil {
+			return nil, fmt.Errorf("%s: %w", cmd.name, err)
+		}
+		if fs.NArg() == 0 {
+			break
+		}
+		cmd.args = append(cmd.args, fs.Arg(0))
+		rest = fs.Args()[1:]
+	}
+	if cmd.name == "stitch" && len(cmd.args) < 3 {
+		return nil, fmt.Errorf("stitch: expected a name and at least two recordings, got %d argument(s)", len(cmd.args))
+	}
+	if wantArgs >= 0 && len(cmd.args) != wantArgs {
+		return nil, fmt.Errorf("%s: expected %d argument(s), got %d", cmd.name, wantArgs, len(cmd.args))
+	}
+	if cmd.all && (cmd.cursorPath != "" || cmd.outputPath != "" || cmd.cursorSpace != (image.Point{})) {
+		return nil, errors.New("edit: --cursor, --cursor-space and --output cannot be used with --all")
+	}
+	if cmd.all && cmd.preview {
+		return nil, errors.New("edit: --preview cannot be used with --all")
+	}
+	if cmd.prune != "" {
+		if _, err := parseAge(cmd.prune); err != nil {
+			return nil, fmt.Errorf("list: --prune: %w", err)
+		}
+	}
+	if cmd.format != "" && !markers.ValidFormat(cmd.format) {
+		return nil, fmt.Errorf("export-markers: --format must be one of %v, got %q", markers.Formats, cmd.format)
+	}
+	// Subfolders depend on the config, which Start checks; the rest can fail now
+	if cmd.name == "record" || cmd.name == "stitch" {
+		if err := recording.ValidateName(cmd.args[0], true); err != nil {
+			return nil, fmt.Errorf("%s: %w", cmd.name, err)
+		}
+	}
+	if cmd.duration < 0 {
+		return nil, fmt.Errorf("record: --duration must not be negative, got %s", cmd.duration)
+	}
+	return cmd, nil
+}
+
+// needsConfig reports whether the command records or renders anything
+func (c *command) needsConfig() bool {
+	return c.name == "record" || c.name == "edit" || c.name == "list" || c.name == "stitch"
+}
+
+// run executes the command and returns the process exit code
+func (c *command) run(cfg *config.Config, logger *slog.Logger) int {
+	var err error
+	switch c.name {
+	case "record":
+		err = c.record(cfg, logger)
+	case "edit":
+		err = c.edit(cfg)
+	case "list":
+		err = c.list(cfg)
+	case "export-markers":
+		err = c.exportMarkers()
+	case "devices":
+		err = c.devices(logger)
+	case "probe":
+		err = c.probe()
+	case "stitch":
+		err = c.stitch(cfg)
+	case "version":
+		fmt.Printf("FocusFrame %s (%s engine, %s %s/%s)\n", version, video.Engine, runtime.Version(), runtime.GOOS, runtime.GOARCH)
+	case "doctor":
+		report := diagnostics.Collect(context.Background(), version)
+		if c.json {
+			err = printJSON(report)
+		} else {
+			fmt.Print(report)
+		}
+	}
+
+	switch {
+	case err == nil:
+		return exitOK
+	case errors.Is(err, context.Canceled):
+		logger.Warn("Interrupted", "command", c.name)
+		return exitInterrupted
+	default:
+		logger.Error("Command failed", "command", c.name, "err", err)
+		return exitFailure
+	}
+}
+
+func (c *command) record(cfg *config.Config, logger *slog.Logger) error {
+	// Ctrl+C stops the recording cleanly instead of killing the process
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(sigChan)
+
+	// Starting over would overwrite what a crashed run left to recover
+	if state, err := session.Load(); err == nil && state != nil && !state.OwnerRunning() {
+		if alive, _ := state.FFmpegRunning(); alive {
+			return fmt.Errorf("an orphaned ffmpeg (pid %d) is still recording %s; run without a command to recover it",
+				state.FFmpegPID, state.OutputPath)
+		}
+	}
+
+	recorder, err := focusframe.NewRecorder(cfg, logger)
+	if err != nil {
+		return err
+	}
+	if err := recorder.Start(c.args[0]); err != nil {
+		return err
+	}
+
+	var timeout <-chan time.Time
+	if c.duration > 0 {
+		timer := time.NewTimer(c.duration)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	select {
+	case sig := <-sigChan:
+		logger.Info("Received signal, stopping recording", "signal", sig)
+	case <-timeout:
+		logger.Info("Duration reached, stopping recording", "duration", c.duration)
+	case <-recorder.Done():
+	}
+	rec, err := recorder.Stop()
+	if err != nil {
+		return err
+	}
+
+	result := struct {
+		Output        string   `json:"output"`
+		Cursor        string   `json:"cursor"`
+		Project       string   `json:"project,omitempty"`
+		DurationSecs  float64  `json:"duration_secs"`
+		CursorSamples int      `json:"cursor_samples"`
+		Warnings      []string `json:"warnings,omitempty"`
+	}{
+		Output:        rec.VideoPath,
+		Cursor:        rec.CursorPath,
+		Project:       rec.ProjectPath,
+		DurationSecs:  rec.Duration.Seconds(),
+		CursorSamples: len(recorder.CursorSamples()),
+		Warnings:      rec.Warnings,
+	}
+	if c.json {
+		return printJSON(result)
+	}
+	fmt.Printf("Recording saved to %s\nCursor data saved to %s\n", result.Output, result.Cursor)
+	if result.Project != "" {
+		fmt.Printf("Project saved to %s\n", result.Project)
+	}
+	for _, warning := range result.Warnings {
+		fmt.Printf("Warning: %s\n", warning)
+	}
+	return nil
+}
+
+func (c *command) edit(cfg *config.Config) error {
+	// Ctrl+C cancels the edit and removes the partial output
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if c.all {
+		return editAll(ctx, cfg, c.args[0], os.Stdout, c.json)
+	}
+
+	var req editing.Request
+	if project.IsBundle(c.args[0]) {
+		var err error
+		if req, err = projectRequest(c.args[0]); err != nil {
+			return err
+		}
+	} else {
+		cursorPath := c.cursorPath
+		if cursorPath == "" {
+			cursorPath = tracking.HistoryPath(c.args[0])
+		}
+		// Another tool's recording may come without cursor data, in which
+		// case only the passes that don't need it run
+		history, startTime, err := tracking.LoadHistory(cursorPath)
+		if err != nil && (c.cursorPath != "" || !errors.Is(err, fs.ErrNotExist)) {
+			return err
+		}
+		req = editing.Request{
+			InputPath:    c.args[0],
+			OutputPath:   editing.EditedPath(c.args[0]),
+			MouseHistory: history,
+			StartTime:    startTime,
+			CursorSpace:  c.cursorSpace,
+			SkipClicks:   savedSkips(c.args[0]),
+		}
+	}
+	if c.preview {
+		req.Preview = true
+		req.OutputPath = editing.PreviewPath(req.OutputPath)
+	}
+	if c.outputPath != "" {
+		req.OutputPath = c.outputPath
+	}
+	inputPath, outputPath := c.args[0], req.OutputPath
+	if c.progressJSON {
+		req.Reporter = progress.NewJSON(os.Stdout)
+	}
+
+	if err := editing.Edit(ctx, cfg, req); err != nil {
+		return err
+	}
+	if c.progressJSON {
+		// The done event already carries the output path
+		announceResult(cfg, slog.Default(), outputPath)
+		return nil
+	}
+
+	if c.json {
+		return printJSON(struct {
+			Input  string `json:"input"`
+			Output string `json:"output"`
+		}{inputPath, outputPath})
+	}
+	if c.preview {
+		fmt.Printf("Preview saved to %s\n", outputPath)
+	} else {
+		fmt.Printf("Edited video saved to %s\n", outputPath)
+	}
+	announceResult(cfg, slog.Default(), outputPath)
+	return nil
+}
+
+func (c *command) list(cfg *config.Config) error {
+	library, err := recording.NewLibrary(cfg.Recording.OutputDir)
+	if err != nil {
+		return err
+	}
+
+	if c.prune != "" {
+		maxAge, _ := parseAge(c.prune)
+		removed, err := library.Prune(maxAge)
+		if c.json {
+			if jsonErr := printJSON(struct {
+				Removed []recordingJSON `json:"removed"`
+			}{toRecordingJSON(removed)}); jsonErr != nil {
+				return jsonErr
+			}
+		} else {
+			for _, e := range removed {
+				fmt.Printf("Deleted %s (recorded %s)\n", e.Name, e.Recorded.Format("2006-01-02"))
+			}
+			fmt.Printf("Pruned %d recording(s) older than %s\n", len(removed), c.prune)
+		}
+		return err
+	}
+
+	entries, err := library.List()
+	if err != nil {
+		return err
+	}
+	if c.json {
+		return printJSON(toRecordingJSON(entries))
+	}
+	printRecordings(os.Stdout, entries, false)
+	return nil
+}
+
+func (c *command) exportMarkers() error {
+	videoPath := c.args[0]
+	cursorPath := c.cursorPath
+	if cursorPath == "" {
+		cursorPath = tracking.HistoryPath(videoPath)
+	}
+	outputPath := c.outputPath
+	if outputPath == "" {
+		outputPath = markers.PathFor(videoPath, c.format)
+	}
+
+	history, _, err := tracking.LoadHistory(cursorPath)
+	if err != nil {
+		return err
+	}
+	found := markers.FromClicks(history, markers.DefaultGap)
+	if err := markers.ExportFile(videoPath, found, c.format, outputPath); err != nil {
+		return err
+	}
+
+	if c.json {
+		return printJSON(struct {
+			Output  string `json:"output"`
+			Markers int    `json:"markers"`
+		}{outputPath, len(found)})
+	}
+	fmt.Printf("Wrote %d marker(s) to %s\n", len(found), outputPath)
+	return nil
+}
+
+func (c *command) devices(logger *slog.Logger) error {
+	devices, err := recording.ListDevices(logger)
+	if err != nil {
+		return err
+	}
+	if c.json {
+		if devices == nil {
+			devices = []recording.Device{}
+		}
+		return printJSON(devices)
+	}
+	for _, d := range devices {
+		fmt.Printf("%-6s [%d] %s\n", d.Kind, d.Index, d.Name)
+	}
+	return nil
+}
+
+func (c *command) probe() error {
+	info, err := media.Probe(c.args[0])
+	if err != nil {
+		return err
+	}
+	if c.json {
+		return printJSON(struct {
+			Width        int     `json:"width"`
+			Height       int     `json:"height"`
+			FPS          float64 `json:"fps"`
+			DurationSecs float64 `json:"duration_secs"`
+			VideoCodec   string  `json:"video_codec,omitempty"`
+			AudioCodec   string  `json:"audio_codec,omitempty"`
+			VideoStreams int     `json:"video_streams"`
+			AudioStreams int     `json:"audio_streams"`
+		}{info.Width, info.Height, info.FPS, info.Duration.Seconds(), info.VideoCodec,
+			info.AudioCodec, info.VideoStreams, info.AudioStreams})
+	}
+	fmt.Printf("Resolution: %s\nFrame rate: %.2f fps\nDuration:   %s\nVideo:      %s (%d stream(s))\nAudio:      %s (%d stream(s))\n",
+		info.Resolution(), info.FPS, info.Duration.Round(time.Millisecond),
+		info.VideoCodec, info.VideoStreams, info.AudioCodec, info.AudioStreams)
+	return nil
+}
+
+// parseSize reads a frame size such as 1440x900
+func parseSize(value string) (image.Point, error) {
+	w, h, ok := strings.Cut(value, "x")
+	width, errW := strconv.Atoi(w)
+	height, errH := strconv.Atoi(h)
+	if !ok || errW != nil || errH != nil || width <= 0 || height <= 0 {
+		return image.Point{}, fmt.Errorf("invalid size %q, expected WIDTHxHEIGHT such as 1440x900", value)
+	}
+	return image.Point{X: width, Y: height}, nil
+}
+
+func printJSON(v any) error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// projectRequest edits a .focusframe bundle with the current settings,
+// writing <name>-edited.mp4 next to the bundle
+func projectRequest(dir string) (editing.Request, error) {
+	p, err := project.Load(dir)
+	if err != nil {
+		return editing.Request{}, err
+	}
+	history, startTime, err := tracking.LoadHistory(p.CursorPath)
+	if err != nil {
+		return editing.Request{}, err
+	}
+	return editing.Request{
+		InputPath:    p.VideoPath,
+		OutputPath:   p.EditedPath(),
+		MouseHistory: history,
+		StartTime:    startTime,
+		SkipClicks:   p.SkippedClicks,
+	}, nil
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/vedantwpatil/Screen-Capture/internal/recording"
+)
+
+// recordingJSON is the --json form of a library entry
+type recordingJSON struct {
+	Name         string    `json:"name"`
+	Path         string    `json:"path"`
+	Size         int64     `json:"size"`
+	TotalSize    int64     `json:"total_size"`
+	Recorded     time.Time `json:"recorded"`
+	DurationSecs float64   `json:"duration_secs"`
+	Width        int       `json:"width"`
+	Height       int       `json:"height"`
+	Cursor       string    `json:"cursor,omitempty"`
+	Edited       string    `json:"edited,omitempty"`
+	Project      string    `json:"project,omitempty"`
+	ProbeError   string    `json:"probe_error,omitempty"`
+}
+
+func toRecordingJSON(entries []recording.Entry) []recordingJSON {
+	out := make([]recordingJSON, 0, len(entries))
+	for _, e := range entries {
+		out = append(out, recordingJSON{
+			Name:         e.Name,
+			Path:         e.Path,
+			Size:         e.Size,
+			TotalSize:    e.TotalSize,
+			Recorded:     e.Recorded,
+			DurationSecs: e.Duration.Seconds(),
+			Width:        e.Width,
+			Height:       e.Height,
+			Cursor:       e.CursorPath,
+			Edited:       e.EditedPath,
+			Project:      e.ProjectPath,
+			ProbeError:   e.ProbeError,
+		})
+	}
+	return out
+}
+
+// printRecordings writes an aligned table; numbered adds a selection column
+func printRecordings(w io.Writer, entries []recording.Entry, numbered bool) {
+	if len(entries) == 0 {
+		fmt.Fprintln(w, "No recordings found")
+		return
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	if numbered {
+		fmt.Fprint(tw, "#\t")
+	}
+	fmt.Fprintln(tw, "NAME\tRECORDED\tDURATION\tRESOLUTION\tSIZE\tCURSOR\tEDITED")
+
+	var total int64
+	for i, e := range entries {
+		if numbered {
+			fmt.Fprintf(tw, "%d\t", i+1)
+		}
+		duration, resolution := "?", "?"
+		if e.ProbeError == "" {
+			duration = e.Duration.Round(time.Second).String()
+			resolution = fmt.Sprintf("%dx%d", e.Width, e.Height)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			e.Name, e.Recorded.Format("2006-01-02 15:04"), duration, resolution,
+			formatSize(e.Size), yesNo(e.CursorPath != ""), yesNo(e.EditedPath != ""))
+		total += e.TotalSize
+	}
+	tw.Flush()
+	fmt.Fprintf(w, "%d recording(s), %s on disk including sidecars and edits\n", len(entries), formatSize(total))
+}
+
+func formatSize(bytes int64) string {
+	const unit = 1024
+	if bytes < unit {
+		return fmt.Sprintf("%d B", bytes)
+	}
+	div, exp := int64(unit), 0
+	for n := bytes / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(bytes)/float64(div), "KMGTPE"[exp])
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
+
+// parseAge accepts Go durations plus a day suffix, e.g. "30d" or "36h"
+func parseAge(value string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("invalid age %q, expected e.g. 30d or 12h", value)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid age %q, expected e.g. 30d or 12h", value)
+	}
+	return d, nil
+}
+
+// parseSelection turns "1 3 4" (or "1,3") into zero-based indexes
+func parseSelection(input string, count int) ([]int, error) {
+	var indexes []int
+	for _, field := range strings.FieldsFunc(input, func(r rune) bool { return r == ' ' || r == ',' }) {
+		n, err := strconv.Atoi(field)
+		if err != nil || n < 1 || n > count {
+			return nil, fmt.Errorf("%q is not a number between 1 and %d", field, count)
+		}
+		indexes = append(indexes, n-1)
+	}
+	return indexes, nil
+}
@@ -1,31 +1,64 @@
 package main
 
 import (
+	"bufio"
 	"context"
+	"errors"
+	"flag"
 	"fmt"
-	"log"
+	"io"
+	"log/slog"
 	"os"
 	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
 	"syscall"
+	"time"
 
 	"github.com/vedantwpatil/Screen-Capture/internal/config"
+	"github.com/vedantwpatil/Screen-Capture/internal/diagnostics"
 	"github.com/vedantwpatil/Screen-Capture/internal/editing"
+	"github.com/vedantwpatil/Screen-Capture/internal/logging"
+	"github.com/vedantwpatil/Screen-Capture/internal/platform"
+	"github.com/vedantwpatil/Screen-Capture/internal/project"
 	"github.com/vedantwpatil/Screen-Capture/internal/recording"
+	"github.com/vedantwpatil/Screen-Capture/internal/server"
+	"github.com/vedantwpatil/Screen-Capture/internal/session"
+	"github.com/vedantwpatil/Screen-Capture/internal/tracking"
 )
 
+// version is set at build time with -ldflags "-X main.version=..."
+var version = "dev"
+
 type Application struct {
-	config   *config.Config
-	recorder *recording.Recorder
-	ctx      context.Context
-	cancel   context.CancelFunc
+	config     *config.Config
+	loadConfig func() (*config.Config, error) // Re-reads the config before each recording or edit
+	logger     *slog.Logger
+	recorder   *recording.Recorder  // Guarded by mu
+	input      *bufio.Reader        // Whole lines of input, so stray words never leak into the next prompt
+	lines      chan inputLine       // Fed by readInput once the first prompt is shown
+	notices    chan recording.Event // Warnings and failures from the running recording
+	ctx        context.Context
+	cancel     context.CancelFunc
+
+	// The signal goroutine reads these while the menu changes them
+	mu         sync.Mutex
+	editCancel context.CancelFunc // Aborts the edit in progress, if any
 }
 
-func NewApplication() *Application {
+// NewApplication reads the user's answers from input, normally os.Stdin
+func NewApplication(cfg *config.Config, loadConfig func() (*config.Config, error), logger *slog.Logger, input io.Reader) *Application {
 	ctx, cancel := context.WithCancel(context.Background())
 	return &Application{
-		config: config.NewConfig(),
-		ctx:    ctx,
-		cancel: cancel,
+		config:     cfg,
+		loadConfig: loadConfig,
+		logger:     logger,
+		input:      bufio.NewReader(input),
+		notices:    make(chan recording.Event, 4),
+		ctx:        ctx,
+		cancel:     cancel,
 	}
 }
 
@@ -35,43 +68,132 @@ func (app *Application) Run() error {
 	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
 
 	// Handle signals
-	go app.handleSignals(sigChan)
+	go handleInterrupts(sigChan, app, app.logger, time.Now, func() { os.Exit(exitInterrupted) })
+	defer func() {
+		signal.Stop(sigChan)
+		close(sigChan)
+	}()
+
+	err := app.loop()
+
+	// However the loop ended, don't leave ffmpeg running or cursor data unsaved
+	if recorder := app.activeRecorder(); recorder != nil && recorder.IsRecording() {
+		app.logger.Info("Stopping recording before exit")
+		if stopErr := app.stopRecording(recorder); stopErr != nil {
+			err = errors.Join(err, stopErr)
+		}
+	}
+	app.cancel()
+	return err
+}
+
+func (app *Application) loop() error {
+	if err := app.recoverSession(); err != nil {
+		if errors.Is(err, io.EOF) || errors.Is(err, context.Canceled) {
+			return nil
+		}
+		app.logger.Error("Could not recover the previous session", "err", err)
+	}
 
-	// Main application loop
 	for {
 		if err := app.showMenu(); err != nil {
+			if errors.Is(err, context.Canceled) {
+				app.logger.Info("Operation cancelled")
+				return nil
+			}
+			if errors.Is(err, io.EOF) {
+				// stdin closed (e.g. piped input ran out); leave as if Exit was chosen
+				fmt.Println()
+				return nil
+			}
 			return err
 		}
+		if app.ctx.Err() != nil {
+			return nil
+		}
 	}
 }
 
 func (app *Application) showMenu() error {
 	fmt.Println("\nCommands:")
 	fmt.Println("1. Start recording")
-	fmt.Println("2. Edit video after recording")
-	fmt.Println("3. Exit")
-	fmt.Print("Choose an option: ")
+	fmt.Println("2. Preview edit (quick 480p render)")
+	fmt.Println("3. Edit video, full render (this session's recording or a file)")
+	fmt.Println("4. Stop and discard recording")
+	fmt.Println("5. Manage recordings")
+	fmt.Println("6. Stitch takes into one video")
+	fmt.Println("7. Exit")
 
-	var choice int
-	if _, err := fmt.Scanln(&choice); err != nil {
-		return fmt.Errorf("invalid input: %w", err)
+	line, err := app.readLine("Choose an option: ")
+	if err != nil {
+		return err
+	}
+	choice, err := strconv.Atoi(line)
+	if err != nil {
+		fmt.Printf("Invalid option %q, enter a number from the list\n", line)
+		return nil
 	}
 
 	switch choice {
 	case 1:
+		if !app.refreshConfig() {
+			return nil
+		}
 		return app.startRecording()
-	case 2:
-		return app.editVideo()
-	case 3:
-		return app.cleanup()
+	case 2, 3:
+		if !app.refreshConfig() {
+			return nil
+		}
+		err := app.editVideo(choice == 2)
+		if errors.Is(err, context.Canceled) && app.ctx.Err() == nil {
+			// Only the edit was cancelled; stay in the menu
+			fmt.Println("\nEditing cancelled")
+			return nil
+		}
+		return err
+	case 4:
+		return app.confirmDiscard()
+	case 5:
+		if !app.refreshConfig() {
+			return nil
+		}
+		return app.manageRecordings()
+	case 6:
+		if !app.refreshConfig() {
+			return nil
+		}
+		err := app.stitchTakes()
+		if errors.Is(err, context.Canceled) && app.ctx.Err() == nil {
+			fmt.Println("\nStitching cancelled")
+			return nil
+		}
+		return err
+	case 7:
+		app.cancel()
+		return nil
 	default:
 		fmt.Println("Invalid option")
 		return nil
 	}
 }
 
+// refreshConfig picks up edits made to the config file mid-session. On
+// problems the old config is kept and the user is sent back to the menu.
+func (app *Application) refreshConfig() bool {
+	if app.loadConfig == nil {
+		return true
+	}
+	cfg, err := app.loadConfig()
+	if err != nil {
+		fmt.Printf("Configuration problems, fix them and try again:\n%v\n", err)
+		return false
+	}
+	app.config = cfg
+	return true
+}
+
 func (app *Application) startRecording() error {
-	if app.recorder != nil && app.recorder.IsRecording() {
+	if recorder := app.activeRecorder(); recorder != nil && recorder.IsRecording() {
 		fmt.Println("Already recording")
 		return nil
 	}
@@ -81,86 +203,557 @@ func (app *Application) startRecording() error {
 		return err
 	}
 
-	app.recorder = recording.NewRecorder(app.config)
-	return app.recorder.Start(baseName)
+	recorder := recording.NewRecorder(app.config, app.logger)
+	events, unsubscribe := recorder.Subscribe()
+	if err := recorder.Start(baseName); err != nil {
+		unsubscribe()
+		return err
+	}
+	app.mu.Lock()
+	app.recorder = recorder
+	app.mu.Unlock()
+
+	go func() {
+		defer unsubscribe()
+		for event := range events {
+			switch event.Kind {
+			case recording.EventWarning, recording.EventError:
+				select {
+				case app.notices <- event:
+				default: // Earlier notices haven't been shown yet
+				}
+			}
+			if event.Kind == recording.EventStopped || event.Kind == recording.EventError {
+				return
+			}
+		}
+	}()
+	return nil
 }
 
 func (app *Application) getBaseName() (string, error) {
-	fmt.Print("Enter the name you wish to save the file under (Don't include the file format ex .mp4): ")
-	var baseName string
-	if _, err := fmt.Scanln(&baseName); err != nil {
-		return "", fmt.Errorf("failed to read base name: %w", err)
+	for {
+		baseName, err := app.readLine("Enter the name you wish to save the file under (Don't include the file format ex .mp4): ")
+		if err != nil {
+			return "", err
+		}
+		err = recording.ValidateName(baseName, app.config.Recording.AllowSubdirs)
+		if err == nil {
+			return baseName, nil
+		}
+		fmt.Println(err)
 	}
-	return baseName, nil
 }
 
-func (app *Application) editVideo() error {
-	if app.recorder == nil || !app.recorder.IsDone() {
-		fmt.Println("No completed recording available for editing")
-		return nil
-	}
+type inputLine struct {
+	text string
+	err  error
+}
 
-	fmt.Println("\nStarting video processing...")
+// readLine prompts and returns the next line of input without surrounding
+// whitespace. io.EOF is returned once the input is exhausted, and the
+// context's error as soon as the application is asked to exit. Warnings from
+// the recording, or it failing in the background, are reported straight away
+// and the prompt repeated.
+func (app *Application) readLine(prompt string) (string, error) {
+	if app.lines == nil {
+		app.lines = make(chan inputLine)
+		go app.readInput()
+	}
+	for {
+		fmt.Print(prompt)
+		select {
+		case line := <-app.lines:
+			return line.text, line.err
+		case event := <-app.notices:
+			if event.Kind == recording.EventError {
+				fmt.Printf("\n❌ Recording failed: %v\n", event.Err)
+			} else {
+				fmt.Printf("\n⚠️  Recording: %s\n", event.Message)
+			}
+		case <-app.ctx.Done():
+			return "", app.ctx.Err()
+		}
+	}
+}
 
-	inputPath := app.recorder.GetOutputPath()
-	outputPath := inputPath[:len(inputPath)-4] + "-edited.mp4"
-	mouseHistory := app.recorder.GetCursorHistory()
+// readInput forwards the input line by line; a blocked read cannot be
+// interrupted, so it runs on its own goroutine
+func (app *Application) readInput() {
+	for {
+		line, err := app.input.ReadString('\n')
+		if err != nil && !(errors.Is(err, io.EOF) && line != "") {
+			app.lines <- inputLine{err: err}
+			return
+		}
+		app.lines <- inputLine{text: strings.TrimSpace(line)}
+	}
+}
 
-	fmt.Printf("Input: %s\n", inputPath)
-	fmt.Printf("Output: %s\n", outputPath)
-	fmt.Printf("Mouse events captured: %d\n", len(mouseHistory))
+// editVideo edits this session's recording or one the user names. A preview
+// renders a quick low-resolution version to check the camera before the
+// full render.
+func (app *Application) editVideo(preview bool) error {
+	recorder := app.activeRecorder()
+	if recorder != nil && recorder.IsRecording() {
+		answer, err := app.readLine("A recording is still running. Stop it and edit it? [y/N]: ")
+		if err != nil {
+			return err
+		}
+		if !strings.EqualFold(answer, "y") && !strings.EqualFold(answer, "yes") {
+			return nil
+		}
+		if err := app.stopRecording(recorder); err != nil {
+			return err
+		}
+	}
 
-	// Check if we have enough mouse data
-	if len(mouseHistory) < 4 {
-		return fmt.Errorf("not enough mouse data for smoothing (need at least 4 points, got %d)", len(mouseHistory))
+	var req editing.Request
+	if recorder != nil && recorder.IsDone() {
+		req = editing.Request{
+			InputPath:    recorder.GetOutputPath(),
+			MouseHistory: recorder.GetCursorHistory(),
+			StartTime:    recorder.GetStartTime(),
+			SkipClicks:   savedSkips(recorder.GetOutputPath()),
+		}
+	} else {
+		// Fall back to a recording from an earlier run, via its cursor sidecar
+		fmt.Println("No completed recording in this session — record first or pass a file path")
+		path, err := app.readLine("Recording to edit, or a folder to edit all unedited recordings (empty to go back): ")
+		if err != nil || path == "" {
+			return err
+		}
+		if project.IsBundle(path) {
+			if req, err = projectRequest(path); err != nil {
+				fmt.Printf("Cannot edit %s: %v\n", path, err)
+				return nil
+			}
+		} else if info, err := os.Stat(path); err == nil && info.IsDir() {
+			if preview {
+				fmt.Println("Previews are made one recording at a time; choose a file")
+				return nil
+			}
+			return app.editAll(path)
+		} else {
+			history, startTime, err := tracking.LoadHistory(tracking.HistoryPath(path))
+			if err != nil {
+				fmt.Printf("Cannot edit %s: %v\n", path, err)
+				return nil
+			}
+			req = editing.Request{InputPath: path, MouseHistory: history, StartTime: startTime, SkipClicks: savedSkips(path)}
+		}
+	}
+	if req.OutputPath == "" {
+		req.OutputPath = editing.EditedPath(req.InputPath)
+	}
+	if preview {
+		req.Preview = true
+		req.OutputPath = editing.PreviewPath(req.OutputPath)
+	}
+	if app.config.Effects.Zoom.Review {
+		if err := app.reviewClicks(&req); err != nil {
+			return err
+		}
 	}
 
-	// Process the video
-	err := editing.ProcessEffect(
-		inputPath,
-		outputPath,
-		mouseHistory,
-		int16(app.config.Recording.TargetFPS),
-	)
-	if err != nil {
-		return fmt.Errorf("video processing failed: %w", err)
+	// Ctrl+C while editing cancels just this edit
+	ctx, cancel := context.WithCancel(app.ctx)
+	app.setEditCancel(cancel)
+	defer func() {
+		app.setEditCancel(nil)
+		cancel()
+	}()
+
+	if err := editing.Edit(ctx, app.config, req); err != nil {
+		return err
 	}
 
+	if preview {
+		fmt.Printf("\n👀 Preview saved to: %s\n", req.OutputPath)
+		fmt.Println("Choose the full render when the camera looks right")
+		announceResult(app.config, app.logger, req.OutputPath)
+		return nil
+	}
 	fmt.Println("\n✨ Video processing complete!")
-	fmt.Printf("📁 Edited video saved to: %s\n", outputPath)
+	fmt.Printf("📁 Edited video saved to: %s\n", req.OutputPath)
+	announceResult(app.config, app.logger, req.OutputPath)
 
 	return nil
 }
 
-func (app *Application) cleanup() error {
-	if app.recorder != nil {
-		if err := app.recorder.Stop(); err != nil {
+// recoverSession deals with a capture left running by a crashed run: it offers
+// to stop the orphaned ffmpeg and then checks whether the file can be used
+func (app *Application) recoverSession() error {
+	state, err := session.Load()
+	if err != nil || state == nil {
+		return err
+	}
+	if state.OwnerRunning() {
+		app.logger.Warn("Another FocusFrame instance is recording", "pid", state.PID, "output", state.OutputPath)
+		return nil
+	}
+
+	fmt.Printf("\nThe previous session crashed while recording %s (started %s).\n",
+		state.OutputPath, state.StartedAt.Format("2006-01-02 15:04"))
+	if alive, verified := state.FFmpegRunning(); alive && !verified {
+		fmt.Printf("Process %d may still be recording it, but could not be confirmed as ffmpeg; stop it manually.\n", state.FFmpegPID)
+		return nil
+	} else if alive {
+		answer, err := app.readLine(fmt.Sprintf("ffmpeg (pid %d) is still recording. Stop it and keep the file? [Y/n]: ", state.FFmpegPID))
+		if err != nil {
+			return err
+		}
+		if strings.EqualFold(answer, "n") || strings.EqualFold(answer, "no") {
+			return nil
+		}
+		if err := state.TerminateFFmpeg(10 * time.Second); err != nil {
 			return err
 		}
 	}
-	app.cancel()
-	return nil
+
+	path, err := session.Salvage(app.ctx, state.OutputPath)
+	if err != nil {
+		fmt.Println(err)
+	} else if path == state.OutputPath {
+		fmt.Printf("Recording %s is intact (no cursor data was saved for it)\n", path)
+	} else {
+		fmt.Printf("Recovered the partial recording to %s\n", path)
+	}
+	return session.Clear()
 }
 
-func (app *Application) handleSignals(sigChan chan os.Signal) {
-	for sig := range sigChan {
-		fmt.Printf("\nReceived signal: %v\n", sig)
-		if app.recorder != nil && app.recorder.IsRecording() {
-			fmt.Println("Stopping recording...")
-			if err := app.recorder.Stop(); err != nil {
-				log.Printf("Error stopping recording: %v", err)
+// manageRecordings lists the output directory and deletes the recordings the
+// user picks, after confirmation
+func (app *Application) manageRecordings() error {
+	library, err := recording.NewLibrary(app.config.Recording.OutputDir)
+	if err != nil {
+		return err
+	}
+	entries, err := library.List()
+	if err != nil {
+		return err
+	}
+	fmt.Println()
+	printRecordings(os.Stdout, entries, true)
+	if len(entries) == 0 {
+		return nil
+	}
+
+	for {
+		input, err := app.readLine("Delete which recordings? (numbers, empty to go back): ")
+		if err != nil || input == "" {
+			return err
+		}
+		selected, err := parseSelection(input, len(entries))
+		if err != nil {
+			fmt.Println(err)
+			continue
+		}
+
+		names := make([]string, len(selected))
+		for i, index := range selected {
+			names[i] = entries[index].Name
+		}
+		answer, err := app.readLine(fmt.Sprintf("Delete %s and their cursor data? [y/N]: ", strings.Join(names, ", ")))
+		if err != nil {
+			return err
+		}
+		if !strings.EqualFold(answer, "y") && !strings.EqualFold(answer, "yes") {
+			return nil
+		}
+		for _, index := range selected {
+			if err := library.Delete(entries[index]); err != nil {
+				fmt.Printf("Could not delete %s: %v\n", entries[index].Name, err)
+			} else {
+				fmt.Printf("Deleted %s\n", entries[index].Name)
 			}
-		} else {
-			fmt.Println("Exiting application...")
-			app.cancel()
-			return
 		}
+		return nil
+	}
+}
+
+// editAll batch-edits a folder; failed clips are reported without leaving the menu
+func (app *Application) editAll(dir string) error {
+	ctx, cancel := context.WithCancel(app.ctx)
+	app.setEditCancel(cancel)
+	defer func() {
+		app.setEditCancel(nil)
+		cancel()
+	}()
+
+	err := editAll(ctx, app.config, dir, os.Stdout, false)
+	if err != nil && ctx.Err() == nil {
+		fmt.Println(err)
+		return nil
+	}
+	return err
+}
+
+func (app *Application) activeRecorder() *recording.Recorder {
+	app.mu.Lock()
+	defer app.mu.Unlock()
+	return app.recorder
+}
+
+// stopRecording finalizes the capture and saves its cursor data alongside it
+func (app *Application) stopRecording(recorder *recording.Recorder) error {
+	if err := recorder.Stop(); err != nil {
+		return err
+	}
+	if warnings := recorder.Warnings(); len(warnings) > 0 {
+		fmt.Printf("⚠️  %d warning(s) during this recording:\n", len(warnings))
+		for _, warning := range warnings {
+			fmt.Printf("   - %s\n", warning)
+		}
+	}
+	if path, err := recorder.SaveCursorHistory(); err != nil {
+		app.logger.Warn("Failed to save cursor data", "err", err)
+	} else {
+		app.logger.Info("Saved cursor data", "file", path)
 	}
+	if path, err := recorder.SaveProject(); err != nil {
+		app.logger.Warn("Failed to save project", "err", err)
+	} else if path != "" {
+		app.logger.Info("Saved project", "dir", path)
+	}
+	return nil
+}
+
+func (app *Application) setEditCancel(cancel context.CancelFunc) {
+	app.mu.Lock()
+	app.editCancel = cancel
+	app.mu.Unlock()
+}
+
+func (app *Application) stopActiveRecording() bool {
+	recorder := app.activeRecorder()
+	if recorder == nil || !recorder.IsRecording() {
+		return false
+	}
+	if err := app.stopRecording(recorder); err != nil {
+		app.logger.Error("Error stopping recording", "err", err)
+	}
+	return true
+}
+
+// confirmDiscard asks before the menu discards this session's recording, as
+// it deletes the whole take
+func (app *Application) confirmDiscard() error {
+	recorder := app.activeRecorder()
+	if recorder == nil || recorder.GetOutputPath() == "" {
+		fmt.Println("No recording to discard")
+		return nil
+	}
+	name := filepath.Base(recorder.GetOutputPath())
+	prompt := fmt.Sprintf("Delete %s and its cursor data? [y/N]: ", name)
+	if recorder.IsRecording() {
+		prompt = fmt.Sprintf("Stop and delete %s and its cursor data? [y/N]: ", name)
+	}
+	answer, err := app.readLine(prompt)
+	if err != nil {
+		return err
+	}
+	if !strings.EqualFold(answer, "y") && !strings.EqualFold(answer, "yes") {
+		fmt.Println("Recording kept")
+		return nil
+	}
+	// A second Ctrl+C may have discarded it while the prompt was up
+	if !app.discardRecording() {
+		fmt.Println("No recording to discard")
+	}
+	return nil
+}
+
+func (app *Application) discardRecording() bool {
+	recorder := app.activeRecorder()
+	if recorder == nil || recorder.GetOutputPath() == "" {
+		return false
+	}
+	if err := recorder.Discard(); err != nil {
+		app.logger.Error("Error discarding recording", "err", err)
+	} else {
+		fmt.Println("\n🗑  Recording discarded")
+	}
+	app.mu.Lock()
+	app.recorder = nil
+	app.mu.Unlock()
+	return true
+}
+
+func (app *Application) exit() {
+	app.cancel()
+}
+
+// cancelEdit aborts the running edit and reports whether there was one
+func (app *Application) cancelEdit() bool {
+	app.mu.Lock()
+	defer app.mu.Unlock()
+	if app.editCancel == nil {
+		return false
+	}
+	app.editCancel()
+	app.editCancel = nil
+	return true
 }
 
 func main() {
-	app := NewApplication()
-	if err := app.Run(); err != nil {
-		log.Fatalf("Application error: %v", err)
+	quiet := flag.Bool("quiet", false, "only print final results, warnings and errors")
+	logFile := flag.String("log-file", "", "append all log output (including ffmpeg command lines) to this file")
+	configPath := flag.String("config", "", "config file (default: ./"+config.FileName+", then the user config dir)")
+	writeConfig := flag.String("write-config", "", "write the effective config to this file as a template and exit")
+	serve := flag.String("serve", "", "serve the HTTP control API on this address (e.g. 127.0.0.1:7878) instead of the menu")
+	progressFormat := flag.String("progress-format", "human", "edit progress: human, or json for one event object per line on stdout")
+	profile := flag.String("profile", "", "apply a named profile from the config file or built-ins (fast, final)")
+	overrides := config.BindFlags(flag.CommandLine, config.NewConfig())
+	flag.Usage = func() {
+		out := flag.CommandLine.Output()
+		fmt.Fprintf(out, "Usage: %s [flags] [command]\n\n", os.Args[0])
+		fmt.Fprintln(out, "Settings are resolved as: flag > environment > profile > config file > built-in default.")
+		fmt.Fprintln(out, "Without a command the interactive menu starts with the loaded config.")
+		fmt.Fprintln(out, "\nCommands (flags above go before the command name):")
+		for _, line := range commandUsage {
+			fmt.Fprintf(out, "  %s\n", line)
+		}
+		fmt.Fprintln(out)
+		flag.PrintDefaults()
+		fmt.Fprintln(out, "\nEnvironment variables (FOCUSFRAME_FPS and FOCUSFRAME_OUTPUT_DIR are short aliases):")
+		for _, v := range config.EnvVars() {
+			fmt.Fprintf(out, "  %-44s %-6s %s\n", v.Name, v.Type, v.Path)
+		}
+	}
+	flag.Parse()
+	if *progressFormat != "human" && *progressFormat != "json" {
+		fmt.Fprintf(os.Stderr, "--progress-format must be human or json, got %q\n", *progressFormat)
+		os.Exit(exitUsage)
+	}
+
+	var cmd *command
+	if flag.NArg() > 0 {
+		if *serve != "" {
+			fmt.Fprintln(os.Stderr, "--serve cannot be combined with a command")
+			os.Exit(exitUsage)
+		}
+		var err error
+		if cmd, err = parseCommand(flag.Args()); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			flag.Usage()
+			os.Exit(exitUsage)
+		}
+		// Keep stdout parseable; progress output is only drawn at info level
+		*quiet = *quiet || cmd.json
+		cmd.progressJSON = *progressFormat == "json"
+	}
+
+	logger, closeLog, err := logging.New(logging.Options{Quiet: *quiet, LogFile: *logFile})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to set up logging: %v\n", err)
+		os.Exit(1)
+	}
+	slog.SetDefault(logger)
+
+	if cmd != nil && !cmd.needsConfig() {
+		code := cmd.run(nil, logger)
+		closeLog()
+		os.Exit(code)
+	}
+
+	loadConfig := func() (*config.Config, error) {
+		return resolveConfig(*configPath, *profile, overrides)
+	}
+	cfg, err := loadConfig()
+	if err != nil {
+		logger.Error("Configuration error", "err", err)
+		closeLog()
+		os.Exit(exitUsage)
+	}
+	if cfg.Source != "" {
+		logger.Info("Loaded config", "file", cfg.Source, "profile", cfg.Profile)
+	} else {
+		logger.Info("No config file found, using defaults", "profile", cfg.Profile)
+	}
+
+	if cmd != nil {
+		code := cmd.run(cfg, logger)
+		closeLog()
+		os.Exit(code)
+	}
+
+	if *writeConfig != "" {
+		err = cfg.Save(*writeConfig)
+		if err == nil {
+			fmt.Printf("Config written to %s\n", *writeConfig)
+		}
+	} else if *serve != "" {
+		err = runServer(*serve, loadConfig, logger)
+	} else {
+		app := NewApplication(cfg, loadConfig, logger, os.Stdin)
+		err = app.Run()
+	}
+	if err != nil {
+		// Logged before the log file closes, so it ends up in bug reports
+		logger.Error("Application error", "err", err)
+		closeLog()
+		fmt.Fprintf(os.Stderr, "\nPlease include these details when reporting this error:\n%s",
+			diagnostics.Collect(context.Background(), version))
+		os.Exit(1)
+	}
+	closeLog()
+}
+
+// announceResult reveals and/or copies a finished edit, as configured.
+// Failures are only logged; the edit itself succeeded.
+func announceResult(cfg *config.Config, logger *slog.Logger, path string) {
+	if cfg.Recording.RevealOnComplete {
+		if err := platform.Reveal(path); err != nil {
+			logger.Warn("Could not reveal output", "err", err)
+		}
+	}
+	if cfg.Recording.CopyPathOnComplete {
+		if err := platform.CopyPath(path); err != nil {
+			logger.Warn("Could not copy output path", "err", err)
+		} else {
+			logger.Info("Copied output path to clipboard")
+		}
+	}
+}
+
+// runServer serves the control API until Ctrl+C, printing the access token
+// clients need first
+func runServer(addr string, loadConfig func() (*config.Config, error), logger *slog.Logger) error {
+	srv, err := server.New(loadConfig, logger)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("API token: %s\n", srv.Token())
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	return srv.ListenAndServe(ctx, addr)
+}
+
+// resolveConfig layers the config file, profile, environment and flags, then checks
+// the result as a whole
+func resolveConfig(path, profile string, overrides *config.FlagOverrides) (*config.Config, error) {
+	cfg, err := config.Load(path)
+	if err != nil {
+		return nil, err
+	}
+	if profile != "" {
+		if err := cfg.ApplyProfile(profile); err != nil {
+			return nil, err
+		}
+	}
+	if err := config.ApplyEnv(cfg); err != nil {
+		return nil, fmt.Errorf("invalid environment:\n%w", err)
+	}
+	if err := overrides.Apply(cfg); err != nil {
+		return nil, fmt.Errorf("invalid flags:\n%w", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		if cfg.Source != "" {
+			return nil, fmt.Errorf("invalid config (%s):\n%w", cfg.Source, err)
+		}
+		return nil, fmt.Errorf("invalid config:\n%w", err)
 	}
+	return cfg, nil
 }
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vedantwpatil/Screen-Capture/internal/config"
+	"github.com/vedantwpatil/Screen-Capture/internal/tracking"
+)
+
+// scriptedApp runs the menu on script as stdin, with one recording, demo, in
+// a fresh output directory, and returns that recording's path
+func scriptedApp(t *testing.T, script string) (*Application, string) {
+	t.Helper()
+	// Keep the crashed-session check away from the real cache directory
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+
+	cfg := config.NewConfig()
+	cfg.Recording.OutputDir = t.TempDir()
+	video := filepath.Join(cfg.Recording.OutputDir, "demo.mp4")
+	if err := os.WriteFile(video, []byte("video"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := tracking.SaveHistory(tracking.HistoryPath(video), time.Now(), []tracking.CursorPosition{{X: 1, Y: 2}}); err != nil {
+		t.Fatal(err)
+	}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	return NewApplication(cfg, nil, logger, strings.NewReader(script)), video
+}
+
+func TestMenu(t *testing.T) {
+	tests := []struct {
+		name     string
+		script   string
+		exited   bool // Chose Exit rather than running out of input
+		wantKept bool
+	}{
+		{"exit", "7\n", true, true},
+		{"input runs out", "", false, true},
+		{"last line without a newline", "7", true, true},
+		{"invalid choices are asked again", "x\n0\n 7 \n", true, true},
+		{"discard with nothing recorded", "4\n7\n", true, true},
+		{"delete declined", "5\n1\nn\n7\n", true, true},
+		{"delete by default answer", "5\n1\n\n7\n", true, true},
+		{"delete confirmed", "5\n1\ny\n7\n", true, false},
+		{"bad selection asked again", "5\n9\n1\nyes\n7\n", true, false},
+		{"back out of deleting", "5\n\n7\n", true, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app, video := scriptedApp(t, tt.script)
+			done := make(chan error, 1)
+			go func() { done <- app.loop() }()
+			select {
+			case err := <-done:
+				if err != nil {
+					t.Fatalf("loop: %v", err)
+				}
+			case <-time.After(10 * time.Second):
+				t.Fatal("menu still waiting for input after the script ended")
+			}
+
+			if exited := app.ctx.Err() != nil; exited != tt.exited {
+				t.Errorf("exited = %v, want %v", exited, tt.exited)
+			}
+			_, err := os.Stat(video)
+			if kept := err == nil; kept != tt.wantKept {
+				t.Errorf("recording kept = %v, want %v", kept, tt.wantKept)
+			}
+			_, err = os.Stat(tracking.HistoryPath(video))
+			if kept := err == nil; kept != tt.wantKept {
+				t.Errorf("cursor data kept = %v, want %v", kept, tt.wantKept)
+			}
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/vedantwpatil/Screen-Capture/internal/editing"
+	"github.com/vedantwpatil/Screen-Capture/internal/markers"
+	"github.com/vedantwpatil/Screen-Capture/internal/project"
+)
+
+// savedSkips returns the click groups skipped in an earlier review of the
+// recording, kept in its project
+func savedSkips(videoPath string) []time.Duration {
+	dir := project.Find(videoPath)
+	if dir == "" {
+		return nil
+	}
+	skipped, err := project.SkippedClicks(dir)
+	if err != nil {
+		slog.Warn("Failed to read skipped clicks, zooming on every click", "project", dir, "err", err)
+	}
+	return skipped
+}
+
+// reviewClicks lists the click groups the camera zooms on and lets the user
+// skip misclicks with "skip 3,7" or bring them back with "keep 3". The
+// choices are saved in the recording's project, when it has one, so later
+// renders make them too.
+func (app *Application) reviewClicks(req *editing.Request) error {
+	groups := markers.FromClicks(req.MouseHistory, markers.DefaultGap)
+	if len(groups) == 0 {
+		return nil
+	}
+	skip := make([]bool, len(groups))
+	for _, at := range req.SkipClicks {
+		if i := markers.Index(groups, at); i >= 0 {
+			skip[i] = true
+		}
+	}
+
+	for {
+		fmt.Println()
+		for i, g := range groups {
+			note := ""
+			if skip[i] {
+				note = "  (skipped)"
+			}
+			fmt.Printf("%3d  %-10s %d click(s)%s\n", i+1, g.At.Round(100*time.Millisecond), g.Clicks, note)
+		}
+		input, err := app.readLine(`Zoom on these clicks? "skip 3,7", "keep 3", or empty to render: `)
+		if err != nil {
+			return err
+		}
+		if input == "" {
+			break
+		}
+		verb, list, _ := strings.Cut(input, " ")
+		if verb != "skip" && verb != "keep" {
+			fmt.Println(`Expected "skip" or "keep" followed by click numbers`)
+			continue
+		}
+		selected, err := parseSelection(list, len(groups))
+		if err != nil {
+			fmt.Println(err)
+			continue
+		}
+		for _, i := range selected {
+			skip[i] = verb == "skip"
+		}
+	}
+
+	req.SkipClicks = nil
+	for i, g := range groups {
+		if skip[i] {
+			req.SkipClicks = append(req.SkipClicks, g.At)
+		}
+	}
+	if dir := project.Find(req.InputPath); dir != "" {
+		if err := project.SetSkippedClicks(dir, req.SkipClicks); err != nil {
+			fmt.Printf("Could not save the skipped clicks to %s: %v\n", dir, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"log/slog"
+	"os"
+	"time"
+)
+
+// discardGrace is how long after a Ctrl+C stops a recording a second one
+// throws that recording away instead of exiting
+const discardGrace = 3 * time.Second
+
+// interruptTarget is what a Ctrl+C can act on
+type interruptTarget interface {
+	// stopActiveRecording finalizes a running recording and reports whether there was one
+	stopActiveRecording() bool
+	// discardRecording deletes the last recording and reports whether there was one
+	discardRecording() bool
+	// cancelEdit aborts a running edit and reports whether there was one
+	cancelEdit() bool
+	// exit asks the application to clean up and leave
+	exit()
+}
+
+// handleInterrupts turns each signal into one action, in priority order:
+// stop an active recording, discard it if the signal follows the stop
+// within discardGrace, cancel a running edit, otherwise exit. Signals keep
+// being handled after exit was requested, and one more while cleanup is
+// still running calls forceExit. now tells the time, so tests can step it.
+func handleInterrupts(sigChan <-chan os.Signal, target interruptTarget, logger *slog.Logger, now func() time.Time, forceExit func()) {
+	exiting := false
+	var stoppedAt time.Time
+	for sig := range sigChan {
+		logger.Info("Received signal", "signal", sig)
+		switch {
+		case target.stopActiveRecording():
+			stoppedAt = now()
+			logger.Info("Recording stopped; press Ctrl+C again within " + discardGrace.String() + " to discard it")
+		case !stoppedAt.IsZero() && now().Sub(stoppedAt) < discardGrace:
+			stoppedAt = time.Time{}
+			target.discardRecording()
+		case target.cancelEdit():
+			logger.Info("Cancelling edit")
+		case exiting:
+			logger.Warn("Exiting without waiting for cleanup")
+			forceExit()
+		default:
+			logger.Info("Exiting application")
+			exiting = true
+			target.exit()
+		}
+	}
+}
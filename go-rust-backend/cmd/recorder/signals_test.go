@@ -0,0 +1,102 @@
+package main
+
+import (
+	"io"
+	"log/slog"
+	"os"
+	"slices"
+	"testing"
+	"time"
+)
+
+// fakeTarget records what each signal did. Every signal first asks to stop
+// a recording, so that is when the clock moves to the signal's time.
+type fakeTarget struct {
+	recording bool // Until the first stop
+	editing   bool // Until the first cancel
+	times     []time.Duration
+	now       time.Time
+	acts      []string // One per signal
+}
+
+func (f *fakeTarget) stopActiveRecording() bool {
+	f.now = time.Unix(0, 0).Add(f.times[0])
+	f.times = f.times[1:]
+	if !f.recording {
+		return false
+	}
+	f.recording = false
+	f.acts = append(f.acts, "stop")
+	return true
+}
+
+func (f *fakeTarget) discardRecording() bool {
+	f.acts = append(f.acts, "discard")
+	return true
+}
+
+func (f *fakeTarget) cancelEdit() bool {
+	if !f.editing {
+		return false
+	}
+	f.editing = false
+	f.acts = append(f.acts, "cancel edit")
+	return true
+}
+
+func (f *fakeTarget) exit() {
+	f.acts = append(f.acts, "exit")
+}
+
+func TestHandleInterrupts(t *testing.T) {
+	tests := []struct {
+		name      string
+		recording bool
+		editing   bool
+		times     []time.Duration // When each signal arrives
+		want      []string
+	}{
+		{
+			name:      "second signal within the grace discards",
+			recording: true,
+			times:     []time.Duration{0, time.Second, 2 * time.Second, 3 * time.Second},
+			want:      []string{"stop", "discard", "exit", "force exit"},
+		},
+		{
+			name:      "signal after the grace exits",
+			recording: true,
+			times:     []time.Duration{0, discardGrace, discardGrace + time.Second},
+			want:      []string{"stop", "exit", "force exit"},
+		},
+		{
+			name:    "edit is cancelled before exiting",
+			editing: true,
+			times:   []time.Duration{0, time.Second, 2 * time.Second},
+			want:    []string{"cancel edit", "exit", "force exit"},
+		},
+		{
+			name:  "idle exits, then forces it",
+			times: []time.Duration{0, time.Minute},
+			want:  []string{"exit", "force exit"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target := &fakeTarget{recording: tt.recording, editing: tt.editing, times: tt.times}
+			signals := make(chan os.Signal, len(tt.times))
+			for range tt.times {
+				signals <- os.Interrupt
+			}
+			close(signals)
+
+			logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+			now := func() time.Time { return target.now }
+			forceExit := func() { target.acts = append(target.acts, "force exit") }
+			handleInterrupts(signals, target, logger, now, forceExit)
+
+			if !slices.Equal(target.acts, tt.want) {
+				t.Errorf("signals did %q, want %q", target.acts, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,198 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/vedantwpatil/Screen-Capture/internal/config"
+	"github.com/vedantwpatil/Screen-Capture/internal/editing"
+	"github.com/vedantwpatil/Screen-Capture/internal/media"
+	"github.com/vedantwpatil/Screen-Capture/internal/recording"
+	"github.com/vedantwpatil/Screen-Capture/internal/timeline"
+)
+
+// parseClip reads a stitch argument: a recording or project, optionally
+// trimmed with an @ suffix such as take1.mp4@5s-1m20s
+func parseClip(arg string) (timeline.Clip, error) {
+	path, trim := arg, ""
+	if at := strings.LastIndex(arg, "@"); at >= 0 {
+		if _, _, err := timeline.ParseTrim(arg[at+1:]); err == nil {
+			path, trim = arg[:at], arg[at+1:]
+		}
+	}
+	clip, err := timeline.LoadClip(path)
+	if err != nil {
+		return timeline.Clip{}, err
+	}
+	if trim != "" {
+		clip.In, clip.Out, _ = timeline.ParseTrim(trim)
+	}
+	return clip, clip.Validate()
+}
+
+// stitch exports the timeline as a new recording called name, with merged
+// cursor data, then edits it like any other recording. It returns the
+// stitched recording and its edit.
+func stitch(ctx context.Context, cfg *config.Config, t *timeline.Timeline, name string) (string, string, error) {
+	outputPath, err := recording.OutputPath(cfg.Recording.OutputDir, name, cfg.Recording.AllowSubdirs)
+	if err != nil {
+		return "", "", err
+	}
+	if _, err := os.Stat(outputPath); err == nil {
+		return "", "", fmt.Errorf("%s already exists", outputPath)
+	}
+
+	// The stitched video is only an input to the edit, so it keeps nearly
+	// all the quality; it must stay MP4 like every other recording
+	encode, _ := media.IntermediateEncodeOptions(media.IntermediateNearLossless)
+	if err := timeline.Export(ctx, t, outputPath, encode); err != nil {
+		return "", "", err
+	}
+
+	req := editing.Request{
+		InputPath:    outputPath,
+		OutputPath:   editing.EditedPath(outputPath),
+		MouseHistory: t.CursorHistory(),
+		StartTime:    t.Clips[0].StartTime,
+	}
+	if err := editing.Edit(ctx, cfg, req); err != nil {
+		return outputPath, "", err
+	}
+	return outputPath, req.OutputPath, nil
+}
+
+func (c *command) stitch(cfg *config.Config) error {
+	// Ctrl+C cancels the export and edit and removes partial output
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	var t timeline.Timeline
+	for _, arg := range c.args[1:] {
+		clip, err := parseClip(arg)
+		if err != nil {
+			return err
+		}
+		t.Clips = append(t.Clips, clip)
+	}
+	stitched, edited, err := stitch(ctx, cfg, &t, c.args[0])
+	if err != nil {
+		return err
+	}
+
+	if c.json {
+		return printJSON(struct {
+			Stitched     string  `json:"stitched"`
+			Output       string  `json:"output"`
+			Clips        int     `json:"clips"`
+			DurationSecs float64 `json:"duration_secs"`
+		}{stitched, edited, len(t.Clips), t.Length().Seconds()})
+	}
+	fmt.Printf("Stitched %d clips into %s\nEdited video saved to %s\n", len(t.Clips), stitched, edited)
+	announceResult(cfg, slog.Default(), edited)
+	return nil
+}
+
+// stitchTakes lets the user pick recordings in order, trim each and export
+// them as one edited video
+func (app *Application) stitchTakes() error {
+	library, err := recording.NewLibrary(app.config.Recording.OutputDir)
+	if err != nil {
+		return err
+	}
+	entries, err := library.List()
+	if err != nil {
+		return err
+	}
+	// Only recordings with cursor data can take part in the effects
+	var takes []recording.Entry
+	for _, e := range entries {
+		if e.CursorPath != "" && e.ProbeError == "" {
+			takes = append(takes, e)
+		}
+	}
+	fmt.Println()
+	printRecordings(os.Stdout, takes, true)
+	if len(takes) < 2 {
+		fmt.Println("Stitching needs at least two recordings with cursor data")
+		return nil
+	}
+
+	var t timeline.Timeline
+	for len(t.Clips) == 0 {
+		input, err := app.readLine("Recordings to stitch, in order (e.g. 1 3 4; empty to go back): ")
+		if err != nil || input == "" {
+			return err
+		}
+		selected, err := parseSelection(input, len(takes))
+		if err != nil {
+			fmt.Println(err)
+			continue
+		}
+		for _, index := range selected {
+			clip, err := timeline.LoadClip(takes[index].Path)
+			if err != nil {
+				fmt.Printf("Cannot use %s: %v\n", takes[index].Name, err)
+				t.Clips = nil
+				break
+			}
+			t.Clips = append(t.Clips, clip)
+		}
+	}
+
+	for i := range t.Clips {
+		clip := &t.Clips[i]
+		for {
+			prompt := fmt.Sprintf("Trim %s (%s), e.g. 5s-1m20s (empty keeps all): ", clip.Name(), clip.Info.Duration.Round(100*time.Millisecond))
+			input, err := app.readLine(prompt)
+			if err != nil {
+				return err
+			}
+			if input == "" {
+				break
+			}
+			if clip.In, clip.Out, err = timeline.ParseTrim(input); err == nil {
+				err = clip.Validate()
+			}
+			if err == nil {
+				break
+			}
+			fmt.Println(err)
+			clip.In, clip.Out = 0, 0
+		}
+	}
+
+	name, err := app.getBaseName()
+	if err != nil {
+		return err
+	}
+
+	// Ctrl+C while stitching cancels just this export
+	ctx, cancel := context.WithCancel(app.ctx)
+	app.setEditCancel(cancel)
+	defer func() {
+		app.setEditCancel(nil)
+		cancel()
+	}()
+
+	fmt.Printf("Stitching %d clips (%s)...\n", len(t.Clips), t.Length().Round(time.Second))
+	stitched, edited, err := stitch(ctx, app.config, &t, name)
+	if err != nil {
+		if stitched != "" {
+			fmt.Printf("Stitched recording saved to %s, but editing it failed\n", stitched)
+		}
+		if ctx.Err() == nil {
+			fmt.Println(err)
+			return nil
+		}
+		return err
+	}
+	fmt.Printf("\n✨ Stitched video saved to: %s\n", edited)
+	announceResult(app.config, app.logger, edited)
+	return nil
+}
@@ -7,6 +7,9 @@ toolchain go1.23.8
 require (
 	github.com/go-vgo/robotgo v0.110.7
 	github.com/robotn/gohook v0.42.0
+	golang.org/x/sys v0.32.0
+	golang.org/x/term v0.31.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
@@ -36,5 +39,4 @@ require (
 	golang.org/x/exp v0.0.0-20250215185904-eff6e970281f // indirect
 	golang.org/x/image v0.25.0 // indirect
 	golang.org/x/net v0.39.0 // indirect
-	golang.org/x/sys v0.32.0 // indirect
 )
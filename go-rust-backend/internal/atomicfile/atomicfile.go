@@ -0,0 +1,115 @@
+// Package atomicfile writes files so they only ever appear complete: content
+// goes to a hidden partial file next to the destination, is synced to disk,
+// and is renamed into place at the end. An interrupted write leaves the old
+// file (or none) plus a partial, never a truncated file under the real name.
+package atomicfile
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// PartialPath names the partial file for path, e.g. .demo-edited.partial.mp4
+// for output/demo-edited.mp4. The extension is kept last because ffmpeg
+// picks the container from it.
+func PartialPath(path string) string {
+	ext := filepath.Ext(path)
+	base := strings.TrimSuffix(filepath.Base(path), ext)
+	return filepath.Join(filepath.Dir(path), "."+base+".partial"+ext)
+}
+
+// WriteFile is os.WriteFile, but atomic
+func WriteFile(path string, data []byte, perm os.FileMode) error {
+	f, err := create(path, perm)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Abort()
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return f.Commit()
+}
+
+// File is written under its partial name until Commit
+type File struct {
+	*os.File
+	path string
+	done bool
+}
+
+// Create starts writing path. Call Commit to put it in place, or Abort to
+// throw it away; deferring Abort after Create is safe either way.
+func Create(path string) (*File, error) {
+	return create(path, 0o644)
+}
+
+func create(path string, perm os.FileMode) (*File, error) {
+	f, err := os.OpenFile(PartialPath(path), os.O_WRONLY|os.O_CREATE|os.O_TRUNC, perm)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s: %w", path, err)
+	}
+	return &File{File: f, path: path}, nil
+}
+
+// Commit syncs the file and renames it over the destination
+func (f *File) Commit() error {
+	if f.done {
+		return errors.New("atomicfile: already committed or aborted")
+	}
+	f.done = true
+	if err := f.Sync(); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return fmt.Errorf("failed to sync %s: %w", f.path, err)
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return fmt.Errorf("failed to write %s: %w", f.path, err)
+	}
+	return rename(f.Name(), f.path)
+}
+
+// Abort closes and removes the partial file; after Commit it does nothing
+func (f *File) Abort() {
+	if f.done {
+		return
+	}
+	f.done = true
+	f.Close()
+	os.Remove(f.Name())
+}
+
+// Finalize moves a partial file written by another process, such as ffmpeg,
+// into place at path once it is synced. The partial is removed on failure.
+func Finalize(partial string, path string) error {
+	f, err := os.OpenFile(partial, os.O_RDWR, 0)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", partial, err)
+	}
+	err = f.Sync()
+	f.Close()
+	if err != nil {
+		os.Remove(partial)
+		return fmt.Errorf("failed to sync %s: %w", path, err)
+	}
+	return rename(partial, path)
+}
+
+// rename moves the partial into place and syncs the directory so the rename
+// itself survives a crash
+func rename(partial string, path string) error {
+	if err := os.Rename(partial, path); err != nil {
+		os.Remove(partial)
+		return fmt.Errorf("failed to move %s into place: %w", path, err)
+	}
+	// Directories can't be synced on Windows; the rename is still atomic
+	if dir, err := os.Open(filepath.Dir(path)); err == nil {
+		dir.Sync()
+		dir.Close()
+	}
+	return nil
+}
@@ -0,0 +1,151 @@
+package atomicfile
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// readFile returns path's contents, failing the test if it can't be read
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+// assertNoPartial fails if path's partial file is left behind
+func assertNoPartial(t *testing.T, path string) {
+	t.Helper()
+	if _, err := os.Stat(PartialPath(path)); !os.IsNotExist(err) {
+		t.Errorf("partial file left behind: %v", err)
+	}
+}
+
+func TestPartialPath(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{filepath.Join("output", "demo-edited.mp4"), filepath.Join("output", ".demo-edited.partial.mp4")},
+		{filepath.Join("output", "demo.cursor.json"), filepath.Join("output", ".demo.cursor.partial.json")},
+		{"notes", ".notes.partial"},
+	}
+	for _, tt := range tests {
+		if got := PartialPath(tt.path); got != tt.want {
+			t.Errorf("PartialPath(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestWriteFile(t *testing.T) {
+	tests := []struct {
+		name     string
+		existing bool
+	}{
+		{"new file", false},
+		{"replaces an existing file", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "settings.yaml")
+			if tt.existing {
+				if err := os.WriteFile(path, []byte("old"), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := WriteFile(path, []byte("new"), 0o600); err != nil {
+				t.Fatalf("WriteFile: %v", err)
+			}
+			if got := readFile(t, path); got != "new" {
+				t.Errorf("contents %q, want %q", got, "new")
+			}
+			assertNoPartial(t, path)
+		})
+	}
+}
+
+func TestCreateCommit(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "demo.cursor.json")
+	f, err := Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Abort() // After Commit this does nothing
+	if _, err := f.WriteString("[1,"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Until Commit only the partial exists
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("destination exists before Commit: %v", err)
+	}
+	if _, err := f.WriteString("2]"); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Commit(); err != nil {
+		t.Fatalf("Commit: %v", err)
+	}
+	if got := readFile(t, path); got != "[1,2]" {
+		t.Errorf("contents %q, want %q", got, "[1,2]")
+	}
+	assertNoPartial(t, path)
+
+	if err := f.Commit(); err == nil {
+		t.Error("second Commit succeeded")
+	}
+}
+
+func TestAbort(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "demo.mp4")
+	if err := os.WriteFile(path, []byte("old take"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	f, err := Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.WriteString("half a new"); err != nil {
+		t.Fatal(err)
+	}
+	f.Abort()
+	f.Abort() // Safe to repeat, e.g. deferred after an explicit Abort
+
+	if got := readFile(t, path); got != "old take" {
+		t.Errorf("target changed to %q by an aborted write", got)
+	}
+	assertNoPartial(t, path)
+	if err := f.Commit(); err == nil {
+		t.Error("Commit after Abort succeeded")
+	}
+}
+
+func TestFinalize(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "demo-edited.mp4")
+	if err := os.WriteFile(path, []byte("previous edit"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	// As ffmpeg would leave it
+	if err := os.WriteFile(PartialPath(path), []byte("new edit"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Finalize(PartialPath(path), path); err != nil {
+		t.Fatalf("Finalize: %v", err)
+	}
+	if got := readFile(t, path); got != "new edit" {
+		t.Errorf("contents %q, want %q", got, "new edit")
+	}
+	assertNoPartial(t, path)
+
+	// With no partial there is nothing to move, and the file stays
+	if err := Finalize(PartialPath(path), path); err == nil {
+		t.Error("Finalize without a partial succeeded")
+	}
+	if got := readFile(t, path); got != "new edit" {
+		t.Errorf("contents %q after a failed Finalize", got)
+	}
+}
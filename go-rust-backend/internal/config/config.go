@@ -1,81 +1,534 @@
 package config
 
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+
+	"github.com/vedantwpatil/Screen-Capture/internal/cursorshape"
+	"github.com/vedantwpatil/Screen-Capture/internal/easing"
+	"github.com/vedantwpatil/Screen-Capture/internal/media"
+	"gopkg.in/yaml.v3"
+)
+
+// Config holds every tunable setting; each section has its own defaults and
+// validation so it can be used on its own
 type Config struct {
-	Effects struct {
-		Blur struct {
-			Enabled bool
-			Radius  int
+	Effects     EffectsConfig     `yaml:"effects"`
+	Processing  ProcessingConfig  `yaml:"processing"`
+	Recording   RecordingConfig   `yaml:"recording"`
+	Export      ExportConfig      `yaml:"export"`
+	Postprocess PostprocessConfig `yaml:"postprocess"`
+
+	// Profiles are named partial configs selected with --profile
+	Profiles map[string]yaml.Node `yaml:"profiles,omitempty"`
+
+	// Source is the file this config was loaded from; empty for built-in defaults
+	Source string `yaml:"-"`
+
+	// Profile is the name of the applied profile, if any
+	Profile string `yaml:"-"`
+}
+
+func NewConfig() *Config {
+	return &Config{
+		Effects:     DefaultEffectsConfig(),
+		Processing:  DefaultProcessingConfig(),
+		Recording:   DefaultRecordingConfig(),
+		Export:      DefaultExportConfig(),
+		Postprocess: DefaultPostprocessConfig(),
+	}
+}
+
+// Validate checks every field and reports all problems at once, each
+// prefixed with its config path (e.g. "effects.zoom.factor: ...")
+func (c *Config) Validate() error {
+	return errors.Join(
+		c.Effects.Validate(),
+		c.Processing.Validate(),
+		c.Recording.Validate(),
+		c.Export.Validate(),
+		c.Postprocess.Validate(),
+	)
+}
+
+type EffectsConfig struct {
+	Zoom       ZoomConfig       `yaml:"zoom"`
+	Follow     FollowConfig     `yaml:"follow"`
+	Timecode   TimecodeConfig   `yaml:"timecode"`
+	ClickSound ClickSoundConfig `yaml:"click_sound"`
+	Cursor     CursorConfig     `yaml:"cursor"`
+	Smoothing  SmoothingConfig  `yaml:"smoothing"`
+	MotionBlur MotionBlurConfig `yaml:"motion_blur"`
+}
+
+func DefaultEffectsConfig() EffectsConfig {
+	return EffectsConfig{
+		Zoom:       DefaultZoomConfig(),
+		Follow:     DefaultFollowConfig(),
+		Timecode:   DefaultTimecodeConfig(),
+		ClickSound: DefaultClickSoundConfig(),
+		Cursor:     DefaultCursorConfig(),
+		Smoothing:  DefaultSmoothingConfig(),
+		MotionBlur: DefaultMotionBlurConfig(),
+	}
+}
+
+func (c EffectsConfig) Validate() error {
+	return errors.Join(
+		c.Zoom.Validate(),
+		c.Follow.Validate(),
+		c.Timecode.Validate(),
+		c.ClickSound.Validate(),
+		c.Cursor.Validate(),
+	)
+}
+
+type ZoomConfig struct {
+	Enabled bool    `yaml:"enabled"`
+	Factor  float64 `yaml:"factor"`
+	Review  bool    `yaml:"review"` // List the clicks before each edit from the menu, to skip misclicks
+	Easing  string  `yaml:"easing"` // linear, smoothstep, easeInOutCubic, spring or cubic-bezier(x1, y1, x2, y2)
+}
+
+func DefaultZoomConfig() ZoomConfig {
+	return ZoomConfig{
+		Enabled: true,
+		Factor:  1.5,
+		Easing:  "smoothstep",
+	}
+}
+
+func (c ZoomConfig) Validate() error {
+	var problems []error
+	if c.Factor < 1 {
+		problems = append(problems, fmt.Errorf("effects.zoom.factor: must be >= 1.0, got %g", c.Factor))
+	}
+	if _, err := easing.Parse(c.Easing); err != nil {
+		problems = append(problems, fmt.Errorf("effects.zoom.easing: %w", err))
+	}
+	return errors.Join(problems...)
+}
+
+// FollowConfig drives the follow camera, which crops in on the cursor and
+// pans to keep it in view
+type FollowConfig struct {
+	Enabled  bool    `yaml:"enabled"`
+	Window   float64 `yaml:"window"`    // Seconds before and after a click during which the camera keeps the cursor centred
+	Zoom     float64 `yaml:"zoom"`      // Magnification while following; 1 shows the whole frame
+	DeadZone float64 `yaml:"dead_zone"` // Share of the view the cursor can move in between clicks without the camera panning
+	Release  float64 `yaml:"release"`   // Share of the view the cursor can reach after a click before the zoom eases out early; 0 always holds it
+}
+
+func DefaultFollowConfig() FollowConfig {
+	return FollowConfig{
+		Enabled:  true,
+		Window:   1.0, // 1 second window before and after click
+		Zoom:     1.5,
+		DeadZone: 0.5,
+		Release:  0.8,
+	}
+}
+
+func (c FollowConfig) Validate() error {
+	var problems []error
+	if c.Window < 0 {
+		problems = append(problems, fmt.Errorf("effects.follow.window: must not be negative, got %g", c.Window))
+	}
+	if c.Zoom < 1 || c.Zoom > 8 {
+		problems = append(problems, fmt.Errorf("effects.follow.zoom: must be between 1 and 8, got %g", c.Zoom))
+	}
+	if c.DeadZone < 0 || c.DeadZone >= 1 {
+		problems = append(problems, fmt.Errorf("effects.follow.dead_zone: must be at least 0 and below 1, got %g", c.DeadZone))
+	}
+	if c.Release < 0 || c.Release > 1 {
+		problems = append(problems, fmt.Errorf("effects.follow.release: must be between 0 and 1, got %g", c.Release))
+	}
+	return errors.Join(problems...)
+}
+
+type TimecodeConfig struct {
+	Enabled   bool   `yaml:"enabled"`
+	WallClock bool   `yaml:"wall_clock"` // Show capture wall-clock time instead of elapsed time
+	FontFile  string `yaml:"font_file"`  // Empty uses ffmpeg's fontconfig default
+	FontSize  int    `yaml:"font_size"`
+	Corner    string `yaml:"corner"` // top-left, top-right, bottom-left or bottom-right
+	Box       bool   `yaml:"box"`    // Draw a translucent box behind the text
+}
+
+func DefaultTimecodeConfig() TimecodeConfig {
+	return TimecodeConfig{
+		Enabled:  false,
+		FontSize: 32,
+		Corner:   "bottom-right",
+		Box:      true,
+	}
+}
+
+func (c TimecodeConfig) Validate() error {
+	var problems []error
+	if c.FontSize <= 0 {
+		problems = append(problems, fmt.Errorf("effects.timecode.font_size: must be positive, got %d", c.FontSize))
+	}
+	switch c.Corner {
+	case "top-left", "top-right", "bottom-left", "bottom-right":
+	default:
+		problems = append(problems, fmt.Errorf(
+			"effects.timecode.corner: must be top-left, top-right, bottom-left or bottom-right, got %q", c.Corner))
+	}
+	if c.Enabled && c.FontFile != "" {
+		if err := checkReadable(c.FontFile); err != nil {
+			problems = append(problems, fmt.Errorf("effects.timecode.font_file: %w", err))
 		}
-		Zoom struct {
-			Enabled bool
-			Factor  float64
+	}
+	return errors.Join(problems...)
+}
+
+// ClickSoundConfig adds a short synthesized click to the audio at every
+// click, mixed over the recording's own audio if it has any
+type ClickSoundConfig struct {
+	Enabled    bool    `yaml:"enabled"`
+	Volume     float64 `yaml:"volume"`      // 1 is the click at full scale
+	MaxOverlap int     `yaml:"max_overlap"` // Clicks beyond this many sounding at once are left silent
+}
+
+func DefaultClickSoundConfig() ClickSoundConfig {
+	return ClickSoundConfig{
+		Enabled:    false,
+		Volume:     0.5,
+		MaxOverlap: 3,
+	}
+}
+
+func (c ClickSoundConfig) Validate() error {
+	var problems []error
+	if c.Volume <= 0 || c.Volume > 2 {
+		problems = append(problems, fmt.Errorf("effects.click_sound.volume: must be above 0 and at most 2, got %g", c.Volume))
+	}
+	if c.MaxOverlap < 1 {
+		problems = append(problems, fmt.Errorf("effects.click_sound.max_overlap: must be at least 1, got %d", c.MaxOverlap))
+	}
+	return errors.Join(problems...)
+}
+
+type CursorConfig struct {
+	SpritePath string  `yaml:"sprite_path"` // PNG with alpha; empty uses the built-in sprite
+	Scale      float64 `yaml:"scale"`       // Sprite size multiplier, e.g. 2.0 for 4K recordings
+	HotspotX   int     `yaml:"hotspot_x"`   // Pixel in the unscaled sprite that marks the pointer tip
+	HotspotY   int     `yaml:"hotspot_y"`
+
+	// Shapes maps a cursor shape (ibeam, hand) to the sprite drawn while the
+	// recorded cursor has it; shapes without one keep the sprite above
+	Shapes map[string]ShapeSprite `yaml:"shapes"`
+
+	Trail TrailConfig `yaml:"trail"`
+}
+
+type ShapeSprite struct {
+	SpritePath string `yaml:"sprite_path"` // PNG with alpha
+	HotspotX   int    `yaml:"hotspot_x"`   // Pixel in the unscaled sprite placed on the cursor position
+	HotspotY   int    `yaml:"hotspot_y"`
+}
+
+func DefaultCursorConfig() CursorConfig {
+	return CursorConfig{
+		Scale: 1.0, // Built-in arrow's tip is its top-left corner
+		Trail: DefaultTrailConfig(),
+	}
+}
+
+// TrailConfig draws fading copies of the cursor along the last stretch of
+// its path, which keeps fast movement legible at low frame rates
+type TrailConfig struct {
+	Enabled    bool    `yaml:"enabled"`
+	DurationMs int     `yaml:"duration_ms"` // How far back the trail reaches
+	Opacity    float64 `yaml:"opacity"`     // Of the nearest copy; farther ones fade out
+}
+
+func DefaultTrailConfig() TrailConfig {
+	return TrailConfig{
+		Enabled:    false,
+		DurationMs: 200,
+		Opacity:    0.5,
+	}
+}
+
+func (c TrailConfig) Validate() error {
+	var problems []error
+	if c.DurationMs < 1 || c.DurationMs > 1000 {
+		problems = append(problems, fmt.Errorf("effects.cursor.trail.duration_ms: must be between 1 and 1000, got %d", c.DurationMs))
+	}
+	if c.Opacity <= 0 || c.Opacity > 1 {
+		problems = append(problems, fmt.Errorf("effects.cursor.trail.opacity: must be above 0 and at most 1, got %g", c.Opacity))
+	}
+	return errors.Join(problems...)
+}
+
+func (c CursorConfig) Validate() error {
+	var problems []error
+	if c.Scale <= 0 {
+		problems = append(problems, fmt.Errorf("effects.cursor.scale: must be positive, got %g", c.Scale))
+	}
+	if c.HotspotX < 0 || c.HotspotY < 0 {
+		problems = append(problems, fmt.Errorf("effects.cursor.hotspot_x/hotspot_y: must not be negative, got (%d, %d)",
+			c.HotspotX, c.HotspotY))
+	}
+	if c.SpritePath != "" {
+		if err := checkReadable(ResolvePath(c.SpritePath)); err != nil {
+			problems = append(problems, fmt.Errorf("effects.cursor.sprite_path: %w", err))
+		}
+	}
+	names := make([]string, 0, len(c.Shapes))
+	for name := range c.Shapes {
+		names = append(names, name)
+	}
+	sort.Strings(names) // Stable error order
+	for _, name := range names {
+		sprite := c.Shapes[name]
+		key := "effects.cursor.shapes." + name
+		shape, err := cursorshape.Parse(name)
+		switch {
+		case err != nil:
+			problems = append(problems, fmt.Errorf("%s: %w", key, err))
+			continue
+		case shape == cursorshape.Arrow:
+			problems = append(problems, fmt.Errorf("%s: the arrow is set with effects.cursor.sprite_path", key))
+			continue
+		}
+		if sprite.HotspotX < 0 || sprite.HotspotY < 0 {
+			problems = append(problems, fmt.Errorf("%s.hotspot_x/hotspot_y: must not be negative, got (%d, %d)",
+				key, sprite.HotspotX, sprite.HotspotY))
 		}
-		Follow struct {
-			Enabled bool
-			Window  float64 // Window size in seconds before and after click
+		if sprite.SpritePath == "" {
+			problems = append(problems, fmt.Errorf("%s.sprite_path: must be set", key))
+		} else if err := checkReadable(ResolvePath(sprite.SpritePath)); err != nil {
+			problems = append(problems, fmt.Errorf("%s.sprite_path: %w", key, err))
 		}
 	}
-	Processing struct {
-		Parallel bool
-		Workers  int
+	problems = append(problems, c.Trail.Validate())
+	return errors.Join(problems...)
+}
+
+// SmoothingConfig and MotionBlurConfig are range-checked by video.VideoConfig,
+// which knows how the engine combines them
+type SmoothingConfig struct {
+	SplineAlpha    float64 `yaml:"spline_alpha"`   // Catmull-Rom parameter; 0.5 is centripetal
+	Responsiveness float64 `yaml:"responsiveness"` // 0 = floaty, 1 = snappy
+	Smoothness     float64 `yaml:"smoothness"`     // 0 = slight overshoot, 1 = none
+}
+
+func DefaultSmoothingConfig() SmoothingConfig {
+	return SmoothingConfig{
+		SplineAlpha:    0.5,
+		Responsiveness: 0.5,
+		Smoothness:     0.7,
 	}
-	Recording struct {
-		TargetFPS int
-		OutputDir string
+}
+
+type MotionBlurConfig struct {
+	Strength  float64 `yaml:"strength"`  // 0 disables; 1 spans a full frame of travel
+	Threshold float64 `yaml:"threshold"` // Cursor speed in pixels per frame before blurring
+}
+
+func DefaultMotionBlurConfig() MotionBlurConfig {
+	return MotionBlurConfig{
+		Strength:  0.5,
+		Threshold: 8.0,
 	}
 }
 
-func NewConfig() *Config {
-	return &Config{
-		Effects: struct {
-			Blur struct {
-				Enabled bool
-				Radius  int
-			}
-			Zoom struct {
-				Enabled bool
-				Factor  float64
-			}
-			Follow struct {
-				Enabled bool
-				Window  float64
-			}
-		}{
-			Blur: struct {
-				Enabled bool
-				Radius  int
-			}{
-				Enabled: true,
-				Radius:  5,
-			},
-			Zoom: struct {
-				Enabled bool
-				Factor  float64
-			}{
-				Enabled: true,
-				Factor:  1.5,
-			},
-			Follow: struct {
-				Enabled bool
-				Window  float64
-			}{
-				Enabled: true,
-				Window:  1.0, // 1 second window before and after click
-			},
-		},
-		Processing: struct {
-			Parallel bool
-			Workers  int
-		}{
-			Parallel: true,
-			Workers:  4,
-		},
-		Recording: struct {
-			TargetFPS int
-			OutputDir string
-		}{
-			TargetFPS: 60,
-			OutputDir: "output",
-		},
+type ProcessingConfig struct {
+	Parallel bool `yaml:"parallel"`
+	Workers  int  `yaml:"workers"`
+}
+
+func DefaultProcessingConfig() ProcessingConfig {
+	return ProcessingConfig{
+		Parallel: true,
+		Workers:  4,
 	}
 }
+
+func (c ProcessingConfig) Validate() error {
+	if c.Workers < 1 {
+		return fmt.Errorf("processing.workers: must be at least 1, got %d", c.Workers)
+	}
+	return nil
+}
+
+type RecordingConfig struct {
+	TargetFPS          int    `yaml:"target_fps"`
+	CursorSampleHz     int    `yaml:"cursor_sample_hz"` // Cursor polling rate, independent of TargetFPS
+	OutputDir          string `yaml:"output_dir"`
+	RevealOnComplete   bool   `yaml:"reveal_on_complete"`    // Show the edited file in the file manager
+	CopyPathOnComplete bool   `yaml:"copy_path_on_complete"` // Put the edited file's path on the clipboard
+	SaveProject        bool   `yaml:"save_project"`          // Bundle each recording as <name>.focusframe
+	AllowSubdirs       bool   `yaml:"allow_subdirs"`         // Let names like "client/demo" record into subfolders of OutputDir
+	ShowIndicator      bool   `yaml:"show_indicator"`        // Show a red dot and the elapsed time while recording
+
+	Watchdog WatchdogConfig `yaml:"watchdog"`
+}
+
+// WatchdogConfig sets when a running recording warns. Zero turns a check off.
+type WatchdogConfig struct {
+	DiskFullWarnMins  int `yaml:"disk_full_warn_mins"` // Warn when the disk will fill within this long at the current rate
+	DroppedFramesWarn int `yaml:"dropped_frames_warn"` // Warn when this many frames are dropped within DroppedWindowSecs
+	DroppedWindowSecs int `yaml:"dropped_window_secs"`
+	SoftLimitMins     int `yaml:"soft_limit_mins"` // Warn each time the recording passes another multiple of this
+}
+
+func DefaultWatchdogConfig() WatchdogConfig {
+	return WatchdogConfig{
+		DiskFullWarnMins:  10,
+		DroppedFramesWarn: 30,
+		DroppedWindowSecs: 30,
+		SoftLimitMins:     60,
+	}
+}
+
+func (c WatchdogConfig) Validate() error {
+	var problems []error
+	if c.DiskFullWarnMins < 0 || c.DiskFullWarnMins > 1440 {
+		problems = append(problems, fmt.Errorf("recording.watchdog.disk_full_warn_mins: must be between 0 and 1440, got %d", c.DiskFullWarnMins))
+	}
+	if c.DroppedFramesWarn < 0 {
+		problems = append(problems, fmt.Errorf("recording.watchdog.dropped_frames_warn: must not be negative, got %d", c.DroppedFramesWarn))
+	}
+	if c.DroppedWindowSecs < 1 || c.DroppedWindowSecs > 3600 {
+		problems = append(problems, fmt.Errorf("recording.watchdog.dropped_window_secs: must be between 1 and 3600, got %d", c.DroppedWindowSecs))
+	}
+	if c.SoftLimitMins < 0 || c.SoftLimitMins > 1440 {
+		problems = append(problems, fmt.Errorf("recording.watchdog.soft_limit_mins: must be between 0 and 1440, got %d", c.SoftLimitMins))
+	}
+	return errors.Join(problems...)
+}
+
+func DefaultRecordingConfig() RecordingConfig {
+	return RecordingConfig{
+		TargetFPS:      60,
+		CursorSampleHz: 120, // Oversampling gives the spline more to fit
+		OutputDir:      "output",
+		SaveProject:    true,
+		Watchdog:       DefaultWatchdogConfig(),
+	}
+}
+
+func (c RecordingConfig) Validate() error {
+	var problems []error
+	if c.TargetFPS < 1 || c.TargetFPS > 240 {
+		problems = append(problems, fmt.Errorf("recording.target_fps: must be between 1 and 240, got %d", c.TargetFPS))
+	}
+	if c.CursorSampleHz < 1 || c.CursorSampleHz > 1000 {
+		problems = append(problems, fmt.Errorf("recording.cursor_sample_hz: must be between 1 and 1000, got %d", c.CursorSampleHz))
+	}
+	if c.OutputDir == "" {
+		problems = append(problems, errors.New("recording.output_dir: must not be empty"))
+	} else if err := checkWritableDir(c.OutputDir); err != nil {
+		problems = append(problems, fmt.Errorf("recording.output_dir: %w", err))
+	}
+	problems = append(problems, c.Watchdog.Validate())
+	return errors.Join(problems...)
+}
+
+type ExportConfig struct {
+	Codec  string `yaml:"codec"` // Encoder for editing passes after the cursor render
+	CRF    int    `yaml:"crf"`
+	Preset string `yaml:"preset"`
+
+	// Intermediate is how passes other than the last are encoded:
+	// near-lossless (libx264 CRF 12) or lossless (FFV1)
+	Intermediate string `yaml:"intermediate"`
+
+	// OddSize is how a recording with an odd width or height is made
+	// encodable: pad (add a black line) or crop (drop one)
+	OddSize string `yaml:"odd_size"`
+
+	// Markers writes click markers next to each edit in this format (edl,
+	// csv or fcpxml); empty turns it off
+	Markers string `yaml:"markers"`
+}
+
+func DefaultExportConfig() ExportConfig {
+	return ExportConfig{
+		Codec:        "libx264",
+		CRF:          18,
+		Preset:       "medium",
+		Intermediate: media.IntermediateNearLossless,
+		OddSize:      media.EvenPad,
+	}
+}
+
+func (c ExportConfig) Validate() error {
+	var problems []error
+	if c.Codec == "" {
+		problems = append(problems, errors.New("export.codec: must not be empty, e.g. libx264"))
+	}
+	if c.CRF < 0 || c.CRF > 51 {
+		problems = append(problems, fmt.Errorf("export.crf: must be between 0 and 51, got %d", c.CRF))
+	}
+	switch c.Intermediate {
+	case media.IntermediateNearLossless, media.IntermediateLossless:
+	default:
+		problems = append(problems, fmt.Errorf("export.intermediate: must be %s or %s, got %q",
+			media.IntermediateNearLossless, media.IntermediateLossless, c.Intermediate))
+	}
+	if c.OddSize != media.EvenPad && c.OddSize != media.EvenCrop {
+		problems = append(problems, fmt.Errorf("export.odd_size: must be %s or %s, got %q", media.EvenPad, media.EvenCrop, c.OddSize))
+	}
+	switch c.Markers {
+	case "", "edl", "csv", "fcpxml":
+	default:
+		problems = append(problems, fmt.Errorf("export.markers: must be edl, csv, fcpxml or empty, got %q", c.Markers))
+	}
+	return errors.Join(problems...)
+}
+
+// PostprocessConfig hands each finished edit to other tools
+type PostprocessConfig struct {
+	// Command runs with the edited video and its cursor data appended, e.g.
+	// ["./upload.sh", "--public"]
+	Command     []string `yaml:"command"`
+	WebhookURL  string   `yaml:"webhook_url"` // Receives a JSON summary of the edit
+	TimeoutSecs int      `yaml:"timeout_secs"`
+}
+
+func DefaultPostprocessConfig() PostprocessConfig {
+	return PostprocessConfig{TimeoutSecs: 60}
+}
+
+func (c PostprocessConfig) Validate() error {
+	var problems []error
+	if len(c.Command) > 0 && c.Command[0] == "" {
+		problems = append(problems, errors.New("postprocess.command: the program must not be empty"))
+	}
+	if c.WebhookURL != "" {
+		if u, err := url.Parse(c.WebhookURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			problems = append(problems, errors.New("postprocess.webhook_url: must be an http or https URL"))
+		}
+	}
+	if c.TimeoutSecs < 1 || c.TimeoutSecs > 3600 {
+		problems = append(problems, fmt.Errorf("postprocess.timeout_secs: must be between 1 and 3600, got %d", c.TimeoutSecs))
+	}
+	return errors.Join(problems...)
+}
+
+// ExportEncodeOptions returns the encoder settings for the last editing pass
+func (c *Config) ExportEncodeOptions() media.EncodeOptions {
+	return media.EncodeOptions{
+		Codec:     c.Export.Codec,
+		CRF:       c.Export.CRF,
+		Preset:    c.Export.Preset,
+		PixFmt:    "yuv420p",
+		AudioCopy: true,
+		EvenSize:  c.Export.OddSize,
+	}
+}
+
+// IntermediateEncodeOptions returns the encoder settings for editing passes
+// that a later pass re-encodes, and the file extension they need
+func (c *Config) IntermediateEncodeOptions() (media.EncodeOptions, string) {
+	encode, ext := media.IntermediateEncodeOptions(c.Export.Intermediate)
+	encode.EvenSize = c.Export.OddSize
+	return encode, ext
+}
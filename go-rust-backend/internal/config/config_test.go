@@ -0,0 +1,160 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// validConfig is the default config with an output directory the test can write to
+func validConfig(t *testing.T) *Config {
+	t.Helper()
+	cfg := NewConfig()
+	cfg.Recording.OutputDir = t.TempDir()
+	return cfg
+}
+
+func TestDefaultsAreValid(t *testing.T) {
+	if err := validConfig(t).Validate(); err != nil {
+		t.Fatalf("default config is invalid: %v", err)
+	}
+
+	// Each section's defaults stand on their own
+	sections := map[string]interface{ Validate() error }{
+		"effects":     DefaultEffectsConfig(),
+		"zoom":        DefaultZoomConfig(),
+		"follow":      DefaultFollowConfig(),
+		"timecode":    DefaultTimecodeConfig(),
+		"click_sound": DefaultClickSoundConfig(),
+		"cursor":      DefaultCursorConfig(),
+		"trail":       DefaultTrailConfig(),
+		"processing":  DefaultProcessingConfig(),
+		"watchdog":    DefaultWatchdogConfig(),
+		"export":      DefaultExportConfig(),
+		"postprocess": DefaultPostprocessConfig(),
+	}
+	for name, section := range sections {
+		if err := section.Validate(); err != nil {
+			t.Errorf("%s defaults are invalid: %v", name, err)
+		}
+	}
+}
+
+func TestNewConfigUsesSectionDefaults(t *testing.T) {
+	want := &Config{
+		Effects:     DefaultEffectsConfig(),
+		Processing:  DefaultProcessingConfig(),
+		Recording:   DefaultRecordingConfig(),
+		Export:      DefaultExportConfig(),
+		Postprocess: DefaultPostprocessConfig(),
+	}
+	if got := NewConfig(); !reflect.DeepEqual(got, want) {
+		t.Errorf("NewConfig() = %+v\nwant %+v", got, want)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "file")
+	if err := os.WriteFile(file, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		modify func(*Config)
+		want   string // Substring of the error; empty means valid
+	}{
+		{"zoom factor below 1", func(c *Config) { c.Effects.Zoom.Factor = 0.9 }, "effects.zoom.factor: must be >= 1.0, got 0.9"},
+		{"zoom factor of exactly 1", func(c *Config) { c.Effects.Zoom.Factor = 1 }, ""},
+		{"unknown easing", func(c *Config) { c.Effects.Zoom.Easing = "bounce" }, "effects.zoom.easing:"},
+		{"cubic-bezier easing", func(c *Config) { c.Effects.Zoom.Easing = "cubic-bezier(0.4, 0, 0.2, 1)" }, ""},
+		{"negative follow window", func(c *Config) { c.Effects.Follow.Window = -1 }, "effects.follow.window:"},
+		{"follow zoom above 8", func(c *Config) { c.Effects.Follow.Zoom = 8.5 }, "effects.follow.zoom: must be between 1 and 8"},
+		{"dead zone of 1", func(c *Config) { c.Effects.Follow.DeadZone = 1 }, "effects.follow.dead_zone:"},
+		{"release above 1", func(c *Config) { c.Effects.Follow.Release = 1.1 }, "effects.follow.release:"},
+		{"release of 0", func(c *Config) { c.Effects.Follow.Release = 0 }, ""},
+		{"timecode font size", func(c *Config) { c.Effects.Timecode.FontSize = 0 }, "effects.timecode.font_size:"},
+		{"timecode corner", func(c *Config) { c.Effects.Timecode.Corner = "middle" }, `effects.timecode.corner: must be top-left, top-right, bottom-left or bottom-right, got "middle"`},
+		{"missing font of a disabled timecode", func(c *Config) { c.Effects.Timecode.FontFile = filepath.Join(dir, "none.ttf") }, ""},
+		{"missing font", func(c *Config) {
+			c.Effects.Timecode.Enabled = true
+			c.Effects.Timecode.FontFile = filepath.Join(dir, "none.ttf")
+		}, "effects.timecode.font_file: cannot read"},
+		{"click volume of 0", func(c *Config) { c.Effects.ClickSound.Volume = 0 }, "effects.click_sound.volume:"},
+		{"click overlap of 0", func(c *Config) { c.Effects.ClickSound.MaxOverlap = 0 }, "effects.click_sound.max_overlap:"},
+		{"cursor scale", func(c *Config) { c.Effects.Cursor.Scale = 0 }, "effects.cursor.scale:"},
+		{"negative hotspot", func(c *Config) { c.Effects.Cursor.HotspotY = -1 }, "effects.cursor.hotspot_x/hotspot_y:"},
+		{"missing sprite", func(c *Config) { c.Effects.Cursor.SpritePath = filepath.Join(dir, "none.png") }, "effects.cursor.sprite_path: cannot read"},
+		{"unknown shape", func(c *Config) { c.Effects.Cursor.Shapes = map[string]ShapeSprite{"spinner": {SpritePath: file}} }, "effects.cursor.shapes.spinner:"},
+		{"arrow shape", func(c *Config) { c.Effects.Cursor.Shapes = map[string]ShapeSprite{"arrow": {SpritePath: file}} }, "effects.cursor.shapes.arrow: the arrow is set with effects.cursor.sprite_path"},
+		{"shape without sprite", func(c *Config) { c.Effects.Cursor.Shapes = map[string]ShapeSprite{"ibeam": {}} }, "effects.cursor.shapes.ibeam.sprite_path: must be set"},
+		{"shape sprite", func(c *Config) { c.Effects.Cursor.Shapes = map[string]ShapeSprite{"ibeam": {SpritePath: file}} }, ""},
+		{"trail duration", func(c *Config) { c.Effects.Cursor.Trail.DurationMs = 1001 }, "effects.cursor.trail.duration_ms:"},
+		{"trail opacity", func(c *Config) { c.Effects.Cursor.Trail.Opacity = 0 }, "effects.cursor.trail.opacity:"},
+		{"no workers", func(c *Config) { c.Processing.Workers = 0 }, "processing.workers: must be at least 1, got 0"},
+		{"fps of 0", func(c *Config) { c.Recording.TargetFPS = 0 }, "recording.target_fps:"},
+		{"fps of 240", func(c *Config) { c.Recording.TargetFPS = 240 }, ""},
+		{"cursor rate", func(c *Config) { c.Recording.CursorSampleHz = 1001 }, "recording.cursor_sample_hz:"},
+		{"empty output dir", func(c *Config) { c.Recording.OutputDir = "" }, "recording.output_dir: must not be empty"},
+		{"output dir to be created", func(c *Config) { c.Recording.OutputDir = filepath.Join(dir, "a", "b") }, ""},
+		{"output dir is a file", func(c *Config) { c.Recording.OutputDir = file }, "recording.output_dir:"},
+		{"watchdog window", func(c *Config) { c.Recording.Watchdog.DroppedWindowSecs = 0 }, "recording.watchdog.dropped_window_secs:"},
+		{"watchdog off", func(c *Config) {
+			c.Recording.Watchdog.DiskFullWarnMins = 0
+			c.Recording.Watchdog.DroppedFramesWarn = 0
+			c.Recording.Watchdog.SoftLimitMins = 0
+		}, ""},
+		{"empty codec", func(c *Config) { c.Export.Codec = "" }, "export.codec: must not be empty"},
+		{"crf above 51", func(c *Config) { c.Export.CRF = 52 }, "export.crf:"},
+		{"intermediate", func(c *Config) { c.Export.Intermediate = "raw" }, "export.intermediate:"},
+		{"odd size", func(c *Config) { c.Export.OddSize = "stretch" }, "export.odd_size:"},
+		{"markers", func(c *Config) { c.Export.Markers = "xml" }, "export.markers:"},
+		{"empty command", func(c *Config) { c.Postprocess.Command = []string{""} }, "postprocess.command:"},
+		{"webhook scheme", func(c *Config) { c.Postprocess.WebhookURL = "ftp://example.com/hook" }, "postprocess.webhook_url:"},
+		{"webhook without host", func(c *Config) { c.Postprocess.WebhookURL = "https:///hook" }, "postprocess.webhook_url:"},
+		{"webhook", func(c *Config) { c.Postprocess.WebhookURL = "https://example.com/hook" }, ""},
+		{"timeout", func(c *Config) { c.Postprocess.TimeoutSecs = 0 }, "postprocess.timeout_secs:"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := validConfig(t)
+			tt.modify(cfg)
+			err := cfg.Validate()
+			switch {
+			case tt.want == "" && err != nil:
+				t.Fatalf("Validate() = %v, want nil", err)
+			case tt.want != "" && err == nil:
+				t.Fatalf("Validate() = nil, want an error containing %q", tt.want)
+			case tt.want != "" && !strings.Contains(err.Error(), tt.want):
+				t.Fatalf("Validate() = %v, want it to contain %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateReportsEveryProblem(t *testing.T) {
+	cfg := validConfig(t)
+	cfg.Effects.Zoom.Factor = 0
+	cfg.Processing.Workers = 0
+	cfg.Export.CRF = -1
+	cfg.Postprocess.TimeoutSecs = 0
+
+	err := cfg.Validate()
+	if err == nil {
+		t.Fatal("Validate() = nil, want errors")
+	}
+	want := []string{"effects.zoom.factor", "processing.workers", "export.crf", "postprocess.timeout_secs"}
+	lines := strings.Split(err.Error(), "\n")
+	if len(lines) != len(want) {
+		t.Fatalf("got %d problems, want %d:\n%v", len(lines), len(want), err)
+	}
+	// Sections are checked in the order they appear in the file
+	for i, prefix := range want {
+		if !strings.HasPrefix(lines[i], prefix+":") {
+			t.Errorf("problem %d = %q, want it to start with %s", i, lines[i], prefix)
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// EnvPrefix starts every environment variable that maps onto a config field
+const EnvPrefix = "FOCUSFRAME_"
+
+// envAliases are short names for settings scripts change most often
+var envAliases = map[string]string{
+	"FOCUSFRAME_FPS":        "FOCUSFRAME_RECORDING_TARGET_FPS",
+	"FOCUSFRAME_OUTPUT_DIR": "FOCUSFRAME_RECORDING_OUTPUT_DIR",
+}
+
+// EnvVar describes one environment variable derived from the config struct
+type EnvVar struct {
+	Name string // e.g. FOCUSFRAME_EFFECTS_ZOOM_FACTOR
+	Path string // YAML path, e.g. effects.zoom.factor
+	Type string // bool, int, float or string
+}
+
+// EnvVars lists every supported variable, generated from the yaml tags on
+// Config so the list cannot drift from the struct
+func EnvVars() []EnvVar {
+	var vars []EnvVar
+	walkEnvFields(reflect.ValueOf(NewConfig()).Elem(), nil, func(path []string, v reflect.Value) {
+		vars = append(vars, EnvVar{
+			Name: envName(path),
+			Path: strings.Join(path, "."),
+			Type: envType(v.Kind()),
+		})
+	})
+	return vars
+}
+
+// ApplyEnv overrides cfg with any FOCUSFRAME_* variables that are set.
+// Every malformed variable is reported, not just the first.
+func ApplyEnv(cfg *Config) error {
+	return applyEnv(cfg, os.LookupEnv)
+}
+
+func applyEnv(cfg *Config, lookup func(string) (string, bool)) error {
+	var problems []error
+	walkEnvFields(reflect.ValueOf(cfg).Elem(), nil, func(path []string, v reflect.Value) {
+		name := envName(path)
+		raw, ok := lookup(name)
+		for alias, target := range envAliases {
+			if target != name {
+				continue
+			}
+			// The full name wins when both are set
+			if aliasRaw, aliasOK := lookup(alias); aliasOK && !ok {
+				name, raw, ok = alias, aliasRaw, true
+			}
+		}
+		if !ok {
+			return
+		}
+		if err := setFromString(v, strings.TrimSpace(raw)); err != nil {
+			problems = append(problems, fmt.Errorf("%s: %w", name, err))
+		}
+	})
+	if len(problems) > 0 {
+		return errors.Join(problems...)
+	}
+	return nil
+}
+
+// walkEnvFields calls visit for every settable leaf field, with its yaml path
+func walkEnvFields(v reflect.Value, path []string, visit func([]string, reflect.Value)) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := strings.Split(field.Tag.Get("yaml"), ",")[0]
+		if tag == "-" || !field.IsExported() {
+			continue
+		}
+		if tag == "" {
+			tag = strings.ToLower(field.Name)
+		}
+		fieldPath := append(append([]string{}, path...), tag)
+		switch field.Type.Kind() {
+		case reflect.Struct:
+			walkEnvFields(v.Field(i), fieldPath, visit)
+		case reflect.Map, reflect.Slice:
+			// Collections such as profiles have no single-variable form
+		default:
+			visit(fieldPath, v.Field(i))
+		}
+	}
+}
+
+func envName(path []string) string {
+	return EnvPrefix + strings.ToUpper(strings.Join(path, "_"))
+}
+
+func envType(kind reflect.Kind) string {
+	switch kind {
+	case reflect.Bool:
+		return "bool"
+	case reflect.Int, reflect.Int64, reflect.Int32:
+		return "int"
+	case reflect.Float64, reflect.Float32:
+		return "float"
+	default:
+		return "string"
+	}
+}
+
+func setFromString(v reflect.Value, raw string) error {
+	switch v.Kind() {
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return fmt.Errorf("expected true or false, got %q", raw)
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int64, reflect.Int32:
+		n, err := strconv.ParseInt(raw, 10, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("expected an integer, got %q", raw)
+		}
+		v.SetInt(n)
+	case reflect.Float64, reflect.Float32:
+		f, err := strconv.ParseFloat(raw, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("expected a number, got %q", raw)
+		}
+		v.SetFloat(f)
+	case reflect.String:
+		v.SetString(raw)
+	default:
+		return fmt.Errorf("unsupported field type %s", v.Type())
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+package config
+
+import (
+	"errors"
+	"flag"
+)
+
+// FlagOverrides binds command-line flags for the most common settings.
+// Only flags the user actually passed are applied, so a flag overrides the
+// config file, which overrides the built-in default.
+type FlagOverrides struct {
+	fs *flag.FlagSet
+
+	fps        *int
+	cursorHz   *int
+	outputDir  *string
+	noZoom     *bool
+	zoomFactor *float64
+	noFollow   *bool
+	timecode   *bool
+	sprite     *string
+	spriteSize *float64
+	codec      *string
+	crf        *int
+	preset     *string
+	noHooks    *bool
+}
+
+// BindFlags registers the override flags on fs. Defaults are only shown in
+// --help; they are never applied on their own.
+func BindFlags(fs *flag.FlagSet, defaults *Config) *FlagOverrides {
+	return &FlagOverrides{
+		fs:         fs,
+		fps:        fs.Int("fps", defaults.Recording.TargetFPS, "capture frame rate"),
+		cursorHz:   fs.Int("cursor-hz", defaults.Recording.CursorSampleHz, "cursor sampling rate, independent of --fps"),
+		outputDir:  fs.String("output-dir", defaults.Recording.OutputDir, "directory for recordings"),
+		noZoom:     fs.Bool("no-zoom", false, "disable zoom on clicks"),
+		zoomFactor: fs.Float64("zoom-factor", defaults.Effects.Zoom.Factor, "zoom magnification (at least 1.0)"),
+		noFollow:   fs.Bool("no-follow", false, "disable the follow camera"),
+		timecode:   fs.Bool("timecode", defaults.Effects.Timecode.Enabled, "burn a timecode into edited videos"),
+		sprite:     fs.String("cursor-sprite", defaults.Effects.Cursor.SpritePath, "cursor PNG (empty uses the built-in sprite)"),
+		spriteSize: fs.Float64("cursor-scale", defaults.Effects.Cursor.Scale, "cursor sprite size multiplier"),
+		codec:      fs.String("codec", defaults.Export.Codec, "encoder for editing passes"),
+		crf:        fs.Int("crf", defaults.Export.CRF, "export quality, 0 (lossless) to 51"),
+		preset:     fs.String("preset", defaults.Export.Preset, "export encoder preset"),
+		noHooks:    fs.Bool("no-hooks", false, "skip the postprocess command and webhook"),
+	}
+}
+
+// Apply copies the explicitly set flags onto cfg, rejecting contradictory
+// combinations; callers validate the final config
+func (o *FlagOverrides) Apply(cfg *Config) error {
+	set := map[string]bool{}
+	o.fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+
+	var conflicts []error
+	if set["no-zoom"] && *o.noZoom && set["zoom-factor"] {
+		conflicts = append(conflicts, errors.New("--zoom-factor has no effect with --no-zoom"))
+	}
+	if len(conflicts) > 0 {
+		return errors.Join(conflicts...)
+	}
+
+	if set["fps"] {
+		cfg.Recording.TargetFPS = *o.fps
+	}
+	if set["cursor-hz"] {
+		cfg.Recording.CursorSampleHz = *o.cursorHz
+	}
+	if set["output-dir"] {
+		cfg.Recording.OutputDir = *o.outputDir
+	}
+	if set["no-zoom"] {
+		cfg.Effects.Zoom.Enabled = !*o.noZoom
+	}
+	if set["zoom-factor"] {
+		cfg.Effects.Zoom.Factor = *o.zoomFactor
+	}
+	if set["no-follow"] {
+		cfg.Effects.Follow.Enabled = !*o.noFollow
+	}
+	if set["timecode"] {
+		cfg.Effects.Timecode.Enabled = *o.timecode
+	}
+	if set["cursor-sprite"] {
+		cfg.Effects.Cursor.SpritePath = *o.sprite
+	}
+	if set["cursor-scale"] {
+		cfg.Effects.Cursor.Scale = *o.spriteSize
+	}
+	if set["codec"] {
+		cfg.Export.Codec = *o.codec
+	}
+	if set["crf"] {
+		cfg.Export.CRF = *o.crf
+	}
+	if set["preset"] {
+		cfg.Export.Preset = *o.preset
+	}
+	if set["no-hooks"] && *o.noHooks {
+		cfg.Postprocess.Command = nil
+		cfg.Postprocess.WebhookURL = ""
+	}
+	return nil
+}
@@ -0,0 +1,143 @@
+package config
+
+import (
+	"flag"
+	"io"
+	"strings"
+	"testing"
+)
+
+// layered resolves a config the way the recorder does: file, then
+// environment, then flags
+func layered(t *testing.T, file string, env map[string]string, args ...string) (*Config, error) {
+	t.Helper()
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	overrides := BindFlags(fs, NewConfig())
+	if err := fs.Parse(args); err != nil {
+		t.Fatalf("parse %v: %v", args, err)
+	}
+
+	cfg := NewConfig()
+	if file != "" {
+		cfg = loadYAML(t, file)
+	}
+	lookup := func(name string) (string, bool) {
+		value, ok := env[name]
+		return value, ok
+	}
+	if err := applyEnv(cfg, lookup); err != nil {
+		return nil, err
+	}
+	if err := overrides.Apply(cfg); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+func TestPrecedence(t *testing.T) {
+	const file = `
+recording:
+  target_fps: 50
+export:
+  crf: 20
+  preset: slow
+`
+	tests := []struct {
+		name      string
+		file      string
+		env       map[string]string
+		args      []string
+		fps       int
+		crf       int
+		preset    string
+		outputDir string
+	}{
+		{"defaults", "", nil, nil, 60, 18, "medium", "output"},
+		{"file over default", file, nil, nil, 50, 20, "slow", "output"},
+		{"env over file", file, map[string]string{"FOCUSFRAME_RECORDING_TARGET_FPS": "40"}, nil, 40, 20, "slow", "output"},
+		{"short env name", file, map[string]string{"FOCUSFRAME_FPS": "45", "FOCUSFRAME_OUTPUT_DIR": "clips"}, nil, 45, 20, "slow", "clips"},
+		{"full env name over short", file, map[string]string{"FOCUSFRAME_FPS": "45", "FOCUSFRAME_RECORDING_TARGET_FPS": "40"}, nil, 40, 20, "slow", "output"},
+		{"flag over env", file, map[string]string{"FOCUSFRAME_RECORDING_TARGET_FPS": "40", "FOCUSFRAME_EXPORT_CRF": "22"}, []string{"--fps", "24"}, 24, 22, "slow", "output"},
+		{"flag over file", file, nil, []string{"--preset", "fast", "--output-dir", "out"}, 50, 20, "fast", "out"},
+		// A flag left at its --help default must not reset the file's value
+		{"unset flag keeps file", file, nil, []string{"--crf", "25"}, 50, 25, "slow", "output"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := layered(t, tt.file, tt.env, tt.args...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if cfg.Recording.TargetFPS != tt.fps || cfg.Export.CRF != tt.crf || cfg.Export.Preset != tt.preset || cfg.Recording.OutputDir != tt.outputDir {
+				t.Errorf("got fps %d, crf %d, preset %q, output dir %q; want %d, %d, %q, %q",
+					cfg.Recording.TargetFPS, cfg.Export.CRF, cfg.Export.Preset, cfg.Recording.OutputDir,
+					tt.fps, tt.crf, tt.preset, tt.outputDir)
+			}
+		})
+	}
+}
+
+func TestFlagSwitches(t *testing.T) {
+	const file = `
+effects:
+  zoom:
+    enabled: true
+  timecode:
+    enabled: true
+postprocess:
+  command: ["./upload.sh"]
+  webhook_url: https://example.com/hook
+`
+	cfg, err := layered(t, file, map[string]string{"FOCUSFRAME_EFFECTS_FOLLOW_ENABLED": "true"},
+		"--no-zoom", "--no-follow", "--timecode=false", "--no-hooks")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Effects.Zoom.Enabled || cfg.Effects.Follow.Enabled || cfg.Effects.Timecode.Enabled {
+		t.Errorf("zoom %v, follow %v, timecode %v; want all off",
+			cfg.Effects.Zoom.Enabled, cfg.Effects.Follow.Enabled, cfg.Effects.Timecode.Enabled)
+	}
+	if cfg.Postprocess.Command != nil || cfg.Postprocess.WebhookURL != "" {
+		t.Errorf("--no-hooks left command %v and webhook %q", cfg.Postprocess.Command, cfg.Postprocess.WebhookURL)
+	}
+
+	// --no-zoom=false is the same as not passing it
+	cfg, err = layered(t, file, nil, "--no-zoom=false")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cfg.Effects.Zoom.Enabled {
+		t.Error("--no-zoom=false turned zoom off")
+	}
+}
+
+func TestFlagConflicts(t *testing.T) {
+	_, err := layered(t, "", nil, "--no-zoom", "--zoom-factor", "2")
+	if err == nil || !strings.Contains(err.Error(), "--zoom-factor has no effect with --no-zoom") {
+		t.Errorf("err = %v, want the zoom conflict", err)
+	}
+	if _, err := layered(t, "", nil, "--no-zoom=false", "--zoom-factor", "2"); err != nil {
+		t.Errorf("zoom factor with zoom on: %v", err)
+	}
+}
+
+func TestApplyEnvReportsEveryProblem(t *testing.T) {
+	_, err := layered(t, "", map[string]string{
+		"FOCUSFRAME_RECORDING_TARGET_FPS": "fast",
+		"FOCUSFRAME_EFFECTS_ZOOM_ENABLED": "maybe",
+		"FOCUSFRAME_EFFECTS_ZOOM_FACTOR":  "two",
+	})
+	if err == nil {
+		t.Fatal("malformed variables were accepted")
+	}
+	for _, want := range []string{
+		`FOCUSFRAME_RECORDING_TARGET_FPS: expected an integer, got "fast"`,
+		`FOCUSFRAME_EFFECTS_ZOOM_ENABLED: expected true or false, got "maybe"`,
+		`FOCUSFRAME_EFFECTS_ZOOM_FACTOR: expected a number, got "two"`,
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q is missing %q", err, want)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/vedantwpatil/Screen-Capture/internal/atomicfile"
+	"gopkg.in/yaml.v3"
+)
+
+// FileName is the config file looked up in the working directory
+const FileName = "focusframe.yaml"
+
+// SearchPaths lists where Load looks for a config file when none is given,
+// in priority order
+func SearchPaths() []string {
+	paths := []string{FileName}
+	if dir, err := os.UserConfigDir(); err == nil {
+		paths = append(paths, filepath.Join(dir, "focusframe", "config.yaml"))
+	}
+	return paths
+}
+
+// Load reads a YAML config over the built-in defaults, so fields missing from
+// the file keep their default values. An empty path searches SearchPaths and
+// falls back to the defaults when no file exists. Unknown fields are logged
+// as warnings rather than rejected, so older binaries can read newer files.
+// The result is not validated, since environment or flags may still fix it.
+func Load(path string) (*Config, error) {
+	if path == "" {
+		for _, candidate := range SearchPaths() {
+			if _, err := os.Stat(candidate); err == nil {
+				path = candidate
+				break
+			}
+		}
+		if path == "" {
+			return NewConfig(), nil
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+
+	cfg := NewConfig()
+	if err := decodeInto(cfg, data, path); err != nil {
+		return nil, err
+	}
+
+	cfg.Source = path
+	return cfg, nil
+}
+
+// Save writes the config as YAML, creating parent directories as needed
+func (c *Config) Save(path string) error {
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(c); err != nil {
+		return fmt.Errorf("failed to encode config: %w", err)
+	}
+	if err := encoder.Close(); err != nil {
+		return fmt.Errorf("failed to encode config: %w", err)
+	}
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return fmt.Errorf("failed to create config directory: %w", err)
+		}
+	}
+	if err := atomicfile.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+		return fmt.Errorf("failed to write config: %w", err)
+	}
+	return nil
+}
+
+// decodeInto strictly decodes YAML over cfg. Fields absent from data keep
+// their current values, which is what makes both defaults and profiles work.
+func decodeInto(cfg *Config, data []byte, source string) error {
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	err := decoder.Decode(cfg)
+	if err == nil || errors.Is(err, io.EOF) {
+		return nil
+	}
+
+	var typeErr *yaml.TypeError
+	if !errors.As(err, &typeErr) {
+		return fmt.Errorf("failed to parse %s: %w", source, err)
+	}
+	// The decoder keeps going past type errors, so unknown fields can be
+	// reported and skipped while real mismatches still fail
+	for _, msg := range typeErr.Errors {
+		if !strings.Contains(msg, "not found in type") {
+			return fmt.Errorf("failed to parse %s: %w", source, err)
+		}
+	}
+	for _, msg := range typeErr.Errors {
+		slog.Warn("Ignoring unknown config field", "file", source, "detail", msg)
+	}
+	return nil
+}
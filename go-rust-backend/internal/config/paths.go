@@ -0,0 +1,62 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// ResolvePath returns path unchanged if it exists or is absolute; otherwise a
+// relative path is also tried next to the executable, so bundled assets are
+// found regardless of the working directory
+func ResolvePath(path string) string {
+	if path == "" || filepath.IsAbs(path) {
+		return path
+	}
+	if _, err := os.Stat(path); err == nil {
+		return path
+	}
+	if exe, err := os.Executable(); err == nil {
+		candidate := filepath.Join(filepath.Dir(exe), path)
+		if _, err := os.Stat(candidate); err == nil {
+			return candidate
+		}
+	}
+	return path
+}
+
+func checkReadable(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("cannot read %s: %w", path, errors.Unwrap(err))
+	}
+	return f.Close()
+}
+
+// checkWritableDir verifies the directory, or the nearest existing parent
+// it would be created under, accepts new files
+func checkWritableDir(dir string) error {
+	existing := dir
+	for {
+		info, err := os.Stat(existing)
+		if err == nil {
+			if !info.IsDir() {
+				return fmt.Errorf("%s is not a directory", existing)
+			}
+			break
+		}
+		parent := filepath.Dir(existing)
+		if parent == existing {
+			return fmt.Errorf("no existing parent directory for %s", dir)
+		}
+		existing = parent
+	}
+
+	probe, err := os.CreateTemp(existing, ".focusframe-write-test-*")
+	if err != nil {
+		return fmt.Errorf("%s is not writable: %w", existing, errors.Unwrap(err))
+	}
+	probe.Close()
+	return os.Remove(probe.Name())
+}
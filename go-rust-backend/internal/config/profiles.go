@@ -0,0 +1,104 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// builtinProfiles are available without a config file. A profile of the same
+// name under "profiles:" in the config file replaces the built-in one.
+var builtinProfiles = map[string]string{
+	"fast": `
+recording:
+  target_fps: 30
+export:
+  preset: ultrafast
+  crf: 28
+`,
+	"final": `
+recording:
+  target_fps: 60
+export:
+  preset: slow
+  crf: 17
+`,
+	PreviewProfile: `
+recording:
+  target_fps: 30
+export:
+  preset: ultrafast
+  crf: 30
+  markers: ""
+effects:
+  motion_blur:
+    strength: 0
+`,
+}
+
+// PreviewProfile is applied on top of the current settings for preview
+// renders. The built-in one only touches speed and quality, never the camera
+// or cursor motion, so a preview moves exactly like the full render.
+const PreviewProfile = "preview"
+
+// ForPreview returns a copy of the config with the preview profile applied
+func (c *Config) ForPreview() (*Config, error) {
+	preview := *c
+	if err := preview.ApplyProfile(PreviewProfile); err != nil {
+		return nil, err
+	}
+	return &preview, nil
+}
+
+// IsPreview reports whether the config is for a preview render, so effects
+// can pick cheaper settings
+func (c *Config) IsPreview() bool {
+	return c.Profile == PreviewProfile
+}
+
+// ApplyProfile overlays the named profile onto the config. A profile is a
+// partial config: every field it sets replaces the base value, everything
+// else is left alone. Nested sections merge field by field, so a profile
+// setting export.crf keeps the base export.codec.
+func (c *Config) ApplyProfile(name string) error {
+	var data []byte
+	if node, ok := c.Profiles[name]; ok {
+		encoded, err := yaml.Marshal(&node)
+		if err != nil {
+			return fmt.Errorf("profile %q: %w", name, err)
+		}
+		data = encoded
+	} else if builtin, ok := builtinProfiles[name]; ok {
+		data = []byte(builtin)
+	} else {
+		return fmt.Errorf("unknown profile %q (available: %s)", name, strings.Join(c.ProfileNames(), ", "))
+	}
+
+	// Decoding must not touch the profile table itself
+	profiles := c.Profiles
+	if err := decodeInto(c, data, "profile "+name); err != nil {
+		return err
+	}
+	c.Profiles = profiles
+	c.Profile = name
+	return nil
+}
+
+// ProfileNames lists the built-in and configured profiles
+func (c *Config) ProfileNames() []string {
+	seen := map[string]bool{}
+	for name := range builtinProfiles {
+		seen[name] = true
+	}
+	for name := range c.Profiles {
+		seen[name] = true
+	}
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,142 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// loadYAML loads a config file with the given contents
+func loadYAML(t *testing.T, contents string) *Config {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), FileName)
+	if err := os.WriteFile(path, []byte(contents), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	return cfg
+}
+
+func TestApplyBuiltinProfile(t *testing.T) {
+	cfg := loadYAML(t, `
+export:
+  codec: libx265
+  crf: 20
+`)
+	if err := cfg.ApplyProfile("fast"); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Recording.TargetFPS != 30 || cfg.Export.Preset != "ultrafast" || cfg.Export.CRF != 28 {
+		t.Errorf("fast profile gave fps %d, preset %q, crf %d", cfg.Recording.TargetFPS, cfg.Export.Preset, cfg.Export.CRF)
+	}
+	// Fields the profile doesn't set keep the file's values
+	if cfg.Export.Codec != "libx265" {
+		t.Errorf("codec = %q, want the file's libx265", cfg.Export.Codec)
+	}
+	if cfg.Profile != "fast" {
+		t.Errorf("Profile = %q, want fast", cfg.Profile)
+	}
+}
+
+func TestApplyFileProfileMergesFieldByField(t *testing.T) {
+	cfg := loadYAML(t, `
+effects:
+  zoom:
+    factor: 2
+    easing: linear
+  follow:
+    zoom: 2
+profiles:
+  demo:
+    effects:
+      zoom:
+        factor: 3
+    export:
+      crf: 12
+`)
+	want := *cfg
+	want.Effects.Zoom.Factor = 3
+	want.Export.CRF = 12
+	want.Profile = "demo"
+
+	if err := cfg.ApplyProfile("demo"); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(cfg.Effects, want.Effects) {
+		t.Errorf("effects = %+v\nwant %+v", cfg.Effects, want.Effects)
+	}
+	if cfg.Export != want.Export || cfg.Recording != want.Recording {
+		t.Errorf("export %+v, recording %+v\nwant %+v, %+v", cfg.Export, cfg.Recording, want.Export, want.Recording)
+	}
+	if cfg.Effects.Zoom.Easing != "linear" {
+		t.Errorf("easing = %q, want the file's linear kept beside the profile's factor", cfg.Effects.Zoom.Easing)
+	}
+	if _, ok := cfg.Profiles["demo"]; !ok || len(cfg.Profiles) != 1 {
+		t.Errorf("profile table changed to %v", cfg.ProfileNames())
+	}
+}
+
+func TestFileProfileReplacesBuiltin(t *testing.T) {
+	cfg := loadYAML(t, `
+profiles:
+  fast:
+    export:
+      crf: 35
+`)
+	if err := cfg.ApplyProfile("fast"); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Export.CRF != 35 {
+		t.Errorf("crf = %d, want the file's 35", cfg.Export.CRF)
+	}
+	// The built-in fast profile's other settings don't leak in
+	if cfg.Recording.TargetFPS != DefaultRecordingConfig().TargetFPS || cfg.Export.Preset != DefaultExportConfig().Preset {
+		t.Errorf("fps %d and preset %q come from the built-in profile", cfg.Recording.TargetFPS, cfg.Export.Preset)
+	}
+}
+
+func TestApplyProfileRejects(t *testing.T) {
+	cfg := loadYAML(t, `
+profiles:
+  broken:
+    export:
+      crf: high
+`)
+	err := cfg.ApplyProfile("nope")
+	if err == nil || !strings.Contains(err.Error(), `unknown profile "nope"`) {
+		t.Fatalf("unknown profile: err = %v", err)
+	}
+	if !strings.Contains(err.Error(), "available: broken, fast, final, preview") {
+		t.Errorf("unknown profile error %q does not list the profiles", err)
+	}
+
+	if err := cfg.ApplyProfile("broken"); err == nil {
+		t.Error("profile with a mistyped field applied without error")
+	}
+}
+
+func TestForPreviewLeavesOriginal(t *testing.T) {
+	cfg := NewConfig()
+	preview, err := cfg.ForPreview()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !preview.IsPreview() || cfg.IsPreview() {
+		t.Fatalf("IsPreview: preview %v, original %v", preview.IsPreview(), cfg.IsPreview())
+	}
+	if preview.Effects.MotionBlur.Strength != 0 || preview.Export.Preset != "ultrafast" {
+		t.Errorf("preview has motion blur %g and preset %q", preview.Effects.MotionBlur.Strength, preview.Export.Preset)
+	}
+	if !reflect.DeepEqual(cfg, NewConfig()) {
+		t.Error("ForPreview changed the original config")
+	}
+	// Camera and cursor motion match the full render
+	if !reflect.DeepEqual(preview.Effects.Follow, cfg.Effects.Follow) || preview.Effects.Smoothing != cfg.Effects.Smoothing {
+		t.Error("preview changes the camera or cursor motion")
+	}
+}
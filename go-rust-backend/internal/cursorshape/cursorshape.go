@@ -0,0 +1,45 @@
+// Package cursorshape names the system cursor shapes a recording tracks, so
+// the render can draw an I-beam over text and a hand over links instead of
+// always the arrow.
+package cursorshape
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Shape is the system cursor's shape at one moment
+type Shape uint8
+
+const (
+	Arrow Shape = iota // Also used wherever the shape is unknown
+	IBeam
+	Hand
+)
+
+var names = [...]string{Arrow: "arrow", IBeam: "ibeam", Hand: "hand"}
+
+// Names lists every shape name Parse accepts, in order
+func Names() []string {
+	return append([]string(nil), names[:]...)
+}
+
+func (s Shape) String() string {
+	if int(s) < len(names) {
+		return names[s]
+	}
+	return fmt.Sprintf("shape(%d)", uint8(s))
+}
+
+// Parse reads a shape name; an empty name is the arrow
+func Parse(name string) (Shape, error) {
+	if name == "" {
+		return Arrow, nil
+	}
+	for i, n := range names {
+		if strings.EqualFold(name, n) {
+			return Shape(i), nil
+		}
+	}
+	return Arrow, fmt.Errorf("unknown cursor shape %q, expected one of %s", name, strings.Join(names[:], ", "))
+}
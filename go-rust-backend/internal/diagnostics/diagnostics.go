@@ -0,0 +1,184 @@
+// Package diagnostics gathers version and environment details for bug reports
+package diagnostics
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/go-vgo/robotgo"
+	"github.com/vedantwpatil/Screen-Capture/internal/ffmpegcmd"
+	"github.com/vedantwpatil/Screen-Capture/internal/media"
+	"github.com/vedantwpatil/Screen-Capture/internal/recording"
+	"github.com/vedantwpatil/Screen-Capture/internal/video"
+)
+
+// probeTimeout bounds every external command so a hung ffmpeg cannot hang the report
+const probeTimeout = 5 * time.Second
+
+// hardwareEncoderMarkers identify GPU encoders in `ffmpeg -encoders` output
+var hardwareEncoderMarkers = []string{"videotoolbox", "nvenc", "qsv", "vaapi", "amf", "v4l2m2m"}
+
+// Tool describes an external binary FocusFrame depends on
+type Tool struct {
+	Path    string `json:"path,omitempty"`
+	Version string `json:"version,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// Report is everything a bug report needs about the running build and machine
+type Report struct {
+	Version          string   `json:"version"`
+	GoVersion        string   `json:"go_version"`
+	OS               string   `json:"os"`
+	Arch             string   `json:"arch"`
+	Engine           string   `json:"engine"` // rust or ffmpeg, depending on build tags
+	FFmpeg           Tool     `json:"ffmpeg"`
+	FFprobe          Tool     `json:"ffprobe"`
+	HardwareEncoders []string `json:"hardware_encoders"`
+	Displays         int      `json:"displays"`
+	ScreenPermission string   `json:"screen_permission"` // granted, denied or unknown
+}
+
+// Collect runs every probe; failures are recorded in the report, never returned
+func Collect(ctx context.Context, version string) Report {
+	report := Report{
+		Version:          version,
+		GoVersion:        runtime.Version(),
+		OS:               runtime.GOOS,
+		Arch:             runtime.GOARCH,
+		Engine:           video.Engine,
+		FFmpeg:           probeTool(ctx, "ffmpeg"),
+		FFprobe:          probeTool(ctx, "ffprobe"),
+		HardwareEncoders: []string{},
+		ScreenPermission: "unknown",
+	}
+	if report.FFmpeg.Error == "" {
+		report.HardwareEncoders = hardwareEncoders(ctx)
+	}
+	report.Displays, report.ScreenPermission = probeScreens(ctx, report.FFmpeg.Error == "")
+	return report
+}
+
+// String formats the report as a plain block suitable for pasting into an issue
+func (r Report) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "FocusFrame:        %s (%s engine)\n", r.Version, r.Engine)
+	fmt.Fprintf(&b, "Go:                %s %s/%s\n", r.GoVersion, r.OS, r.Arch)
+	fmt.Fprintf(&b, "ffmpeg:            %s\n", r.FFmpeg)
+	fmt.Fprintf(&b, "ffprobe:           %s\n", r.FFprobe)
+	encoders := strings.Join(r.HardwareEncoders, ", ")
+	if encoders == "" {
+		encoders = "none"
+	}
+	fmt.Fprintf(&b, "Hardware encoders: %s\n", encoders)
+	fmt.Fprintf(&b, "Displays:          %d\n", r.Displays)
+	fmt.Fprintf(&b, "Screen recording:  %s\n", r.ScreenPermission)
+	return b.String()
+}
+
+func (t Tool) String() string {
+	if t.Error != "" {
+		return "not usable: " + t.Error
+	}
+	return fmt.Sprintf("%s (%s)", t.Path, t.Version)
+}
+
+func probeTool(ctx context.Context, name string) Tool {
+	path, err := exec.LookPath(name)
+	if err != nil {
+		return Tool{Error: "not found in PATH"}
+	}
+	output, err := run(ctx, path, "-hide_banner", "-version")
+	if err != nil {
+		return Tool{Path: path, Error: err.Error()}
+	}
+	firstLine, _, _ := strings.Cut(output, "\n")
+	return Tool{Path: path, Version: strings.TrimSpace(firstLine)}
+}
+
+func hardwareEncoders(ctx context.Context) []string {
+	output, err := run(ctx, "ffmpeg", ffmpegcmd.New().Global("-encoders").Args()...)
+	if err != nil {
+		return []string{}
+	}
+	encoders := []string{}
+	for _, line := range strings.Split(output, "\n") {
+		// Encoder lines look like " V....D h264_videotoolbox  VideoToolbox H.264 Encoder"
+		fields := strings.Fields(line)
+		if len(fields) < 2 || !strings.HasPrefix(fields[0], "V") {
+			continue
+		}
+		for _, marker := range hardwareEncoderMarkers {
+			if strings.Contains(fields[1], marker) {
+				encoders = append(encoders, fields[1])
+				break
+			}
+		}
+	}
+	return encoders
+}
+
+// probeScreens counts displays and, on macOS, checks the screen recording
+// permission by grabbing a single frame
+func probeScreens(ctx context.Context, haveFFmpeg bool) (int, string) {
+	if runtime.GOOS != "darwin" {
+		// Only macOS gates screen capture behind a permission
+		return robotgo.DisplaysNum(), "not required"
+	}
+	if !haveFFmpeg {
+		return robotgo.DisplaysNum(), "unknown"
+	}
+
+	devices, err := recording.ListDevices(slog.Default())
+	if err != nil {
+		return robotgo.DisplaysNum(), "unknown"
+	}
+	screens := 0
+	screenIndex := -1
+	for _, d := range devices {
+		if d.Kind == "video" && strings.HasPrefix(d.Name, "Capture screen") {
+			if screenIndex < 0 {
+				screenIndex = d.Index
+			}
+			screens++
+		}
+	}
+	if screenIndex < 0 {
+		return screens, "unknown"
+	}
+
+	args := ffmpegcmd.New().
+		Global("-v", "error").
+		Device("avfoundation", fmt.Sprintf("%d:none", screenIndex)).
+		Output("-", media.EncodeOptions{}, "-frames:v", "1", "-f", "null").
+		Args()
+	_, err = run(ctx, "ffmpeg", args...)
+	if err != nil {
+		return screens, "appears denied (System Settings > Privacy & Security > Screen Recording)"
+	}
+	return screens, "appears granted"
+}
+
+func run(ctx context.Context, name string, args ...string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, probeTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, name, args...)
+	var output bytes.Buffer
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	slog.Debug("Running diagnostic", "args", cmd.Args)
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return "", fmt.Errorf("timed out after %s", probeTimeout)
+		}
+		return "", fmt.Errorf("%w: %s", err, strings.TrimSpace(output.String()))
+	}
+	return output.String(), nil
+}
@@ -0,0 +1,121 @@
+// Package easing maps progress through a transition (0 to 1) onto how far
+// the transition has moved, giving zooms their feel.
+package easing
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Curve eases x in [0, 1]. Every curve returns 0 at 0 and 1 at 1; inputs
+// outside the range are clamped.
+type Curve func(x float64) float64
+
+// Names lists the built-in curves Parse accepts
+var Names = []string{"linear", "smoothstep", "easeInOutCubic", "spring"}
+
+// springStiffness sets how quickly the spring curve settles; higher is snappier
+const springStiffness = 8.0
+
+// Linear moves at a constant rate
+func Linear(x float64) float64 {
+	return clamp(x)
+}
+
+// Smoothstep eases gently in and out
+func Smoothstep(x float64) float64 {
+	x = clamp(x)
+	return x * x * (3 - 2*x)
+}
+
+// EaseInOutCubic starts and ends slower than Smoothstep and moves faster
+// through the middle
+func EaseInOutCubic(x float64) float64 {
+	x = clamp(x)
+	if x < 0.5 {
+		return 4 * x * x * x
+	}
+	return 1 - math.Pow(-2*x+2, 3)/2
+}
+
+// Spring is a critically damped spring released at 0: it leaves fast and
+// settles softly, without overshooting
+func Spring(x float64) float64 {
+	x = clamp(x)
+	response := func(t float64) float64 {
+		return 1 - (1+springStiffness*t)*math.Exp(-springStiffness*t)
+	}
+	return response(x) / response(1)
+}
+
+// CubicBezier returns the CSS-style curve through (0,0), (x1,y1), (x2,y2)
+// and (1,1). x1 and x2 must be within [0, 1] so the curve is a function of
+// x; y1 and y2 may go outside it to overshoot.
+func CubicBezier(x1, y1, x2, y2 float64) (Curve, error) {
+	if x1 < 0 || x1 > 1 || x2 < 0 || x2 > 1 {
+		return nil, fmt.Errorf("cubic-bezier x values must be within 0..1, got %g and %g", x1, x2)
+	}
+	bezier := func(t, p1, p2 float64) float64 {
+		u := 1 - t
+		return 3*u*u*t*p1 + 3*u*t*t*p2 + t*t*t
+	}
+	return func(x float64) float64 {
+		if x <= 0 || x >= 1 {
+			return clamp(x)
+		}
+		// x(t) rises monotonically with t, so bisection always finds it
+		lo, hi := 0.0, 1.0
+		for range 40 {
+			mid := (lo + hi) / 2
+			if bezier(mid, x1, x2) < x {
+				lo = mid
+			} else {
+				hi = mid
+			}
+		}
+		return bezier((lo+hi)/2, y1, y2)
+	}, nil
+}
+
+// Parse reads a curve name from Names, or cubic-bezier(x1, y1, x2, y2)
+func Parse(name string) (Curve, error) {
+	switch name {
+	case "linear":
+		return Linear, nil
+	case "smoothstep":
+		return Smoothstep, nil
+	case "easeInOutCubic":
+		return EaseInOutCubic, nil
+	case "spring":
+		return Spring, nil
+	}
+
+	args, isBezier := strings.CutPrefix(name, "cubic-bezier(")
+	args, closed := strings.CutSuffix(args, ")")
+	if !isBezier || !closed {
+		return nil, fmt.Errorf("unknown easing %q (available: %s, or cubic-bezier(x1, y1, x2, y2))", name, strings.Join(Names, ", "))
+	}
+	fields := strings.Split(args, ",")
+	if len(fields) != 4 {
+		return nil, fmt.Errorf("invalid easing %q: cubic-bezier takes 4 numbers", name)
+	}
+	var p [4]float64
+	for i, field := range fields {
+		v, err := strconv.ParseFloat(strings.TrimSpace(field), 64)
+		if err != nil || math.IsNaN(v) || math.IsInf(v, 0) {
+			return nil, fmt.Errorf("invalid easing %q: %q is not a number", name, strings.TrimSpace(field))
+		}
+		p[i] = v
+	}
+	curve, err := CubicBezier(p[0], p[1], p[2], p[3])
+	if err != nil {
+		return nil, fmt.Errorf("invalid easing %q: %w", name, err)
+	}
+	return curve, nil
+}
+
+func clamp(x float64) float64 {
+	return min(max(x, 0), 1)
+}
@@ -0,0 +1,143 @@
+package easing
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+// checkCurve fails unless curve runs from 0 to 1 without ever going back
+func checkCurve(t *testing.T, curve Curve) {
+	t.Helper()
+	if got := curve(0); got != 0 {
+		t.Errorf("f(0) = %g, want 0", got)
+	}
+	if got := curve(1); got != 1 {
+		t.Errorf("f(1) = %g, want 1", got)
+	}
+	const steps = 1000
+	prev := curve(0)
+	for i := 1; i <= steps; i++ {
+		x := float64(i) / steps
+		y := curve(x)
+		if math.IsNaN(y) || y < prev-1e-12 {
+			t.Fatalf("f(%g) = %g after f(%g) = %g; not monotonic", x, y, float64(i-1)/steps, prev)
+		}
+		prev = y
+	}
+	// Outside the range the curve holds its endpoints
+	if got := curve(-0.5); got != 0 {
+		t.Errorf("f(-0.5) = %g, want 0", got)
+	}
+	if got := curve(1.5); got != 1 {
+		t.Errorf("f(1.5) = %g, want 1", got)
+	}
+}
+
+func TestNamedCurves(t *testing.T) {
+	for _, name := range Names {
+		t.Run(name, func(t *testing.T) {
+			curve, err := Parse(name)
+			if err != nil {
+				t.Fatalf("Parse(%q): %v", name, err)
+			}
+			checkCurve(t, curve)
+		})
+	}
+}
+
+func TestSymmetricCurves(t *testing.T) {
+	for name, curve := range map[string]Curve{"smoothstep": Smoothstep, "easeInOutCubic": EaseInOutCubic} {
+		for _, x := range []float64{0.1, 0.25, 0.4, 0.5} {
+			if got := curve(x) + curve(1-x); math.Abs(got-1) > 1e-12 {
+				t.Errorf("%s: f(%g) + f(%g) = %g, want 1", name, x, 1-x, got)
+			}
+		}
+	}
+}
+
+func TestCubicBezier(t *testing.T) {
+	tests := []struct {
+		name           string
+		x1, y1, x2, y2 float64
+	}{
+		{"linear", 0, 0, 1, 1},
+		{"ease", 0.25, 0.1, 0.25, 1},
+		{"ease-in", 0.42, 0, 1, 1},
+		{"ease-out", 0, 0, 0.58, 1},
+		{"ease-in-out", 0.42, 0, 0.58, 1},
+		{"steep start", 0, 1, 0, 1},
+		{"flat start", 1, 0, 1, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			curve, err := CubicBezier(tt.x1, tt.y1, tt.x2, tt.y2)
+			if err != nil {
+				t.Fatal(err)
+			}
+			checkCurve(t, curve)
+		})
+	}
+
+	// With its control points on the diagonal the curve is the identity
+	linear, _ := CubicBezier(1.0/3, 1.0/3, 2.0/3, 2.0/3)
+	for _, x := range []float64{0.1, 0.5, 0.9} {
+		if got := linear(x); math.Abs(got-x) > 1e-9 {
+			t.Errorf("diagonal bezier f(%g) = %g", x, got)
+		}
+	}
+	// CSS ease-in-out is symmetric
+	inOut, _ := CubicBezier(0.42, 0, 0.58, 1)
+	if got := inOut(0.5); math.Abs(got-0.5) > 1e-9 {
+		t.Errorf("ease-in-out f(0.5) = %g, want 0.5", got)
+	}
+}
+
+func TestCubicBezierOvershoot(t *testing.T) {
+	// y values outside 0..1 overshoot in between, but the ends still hold
+	back, err := CubicBezier(0.34, 1.56, 0.64, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if back(0) != 0 || back(1) != 1 {
+		t.Errorf("endpoints %g and %g, want 0 and 1", back(0), back(1))
+	}
+	peak := 0.0
+	for i := 0; i <= 100; i++ {
+		peak = max(peak, back(float64(i)/100))
+	}
+	if peak <= 1 {
+		t.Errorf("peak %g, want an overshoot past 1", peak)
+	}
+}
+
+func TestParse(t *testing.T) {
+	if _, err := Parse("cubic-bezier(0.25, 0.1, 0.25, 1)"); err != nil {
+		t.Errorf("CSS ease: %v", err)
+	}
+	if _, err := Parse("cubic-bezier(0,0,1,1)"); err != nil {
+		t.Errorf("without spaces: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"bounce", "unknown easing"},
+		{"Linear", "unknown easing"},
+		{"", "unknown easing"},
+		{"cubic-bezier(0.1, 0.2, 0.3)", "takes 4 numbers"},
+		{"cubic-bezier(0.1, 0.2, 0.3, 0.4", "unknown easing"},
+		{"cubic-bezier(a, 0, 1, 1)", `"a" is not a number`},
+		{"cubic-bezier(NaN, 0, 1, 1)", `"NaN" is not a number`},
+		{"cubic-bezier(0, Inf, 1, 1)", `"Inf" is not a number`},
+		{"cubic-bezier(1.5, 0, 1, 1)", "x values must be within 0..1"},
+		{"cubic-bezier(0, 0, -0.1, 1)", "x values must be within 0..1"},
+	}
+	for _, tt := range tests {
+		_, err := Parse(tt.name)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("Parse(%q) = %v, want an error containing %q", tt.name, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package editing
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vedantwpatil/Screen-Capture/internal/media"
+)
+
+// Probed recordings: the screen capture has no audio, an OBS capture has
+var (
+	silentInfo = media.Info{Width: 1920, Height: 1080, FPS: 60, Duration: 12 * time.Second, VideoCodec: "h264", VideoStreams: 1}
+	audioInfo  = media.Info{Width: 1920, Height: 1080, FPS: 60, Duration: 12 * time.Second, VideoCodec: "h264", AudioCodec: "aac", VideoStreams: 1, AudioStreams: 1}
+)
+
+// values returns every argument following flag in args
+func values(args []string, flag string) []string {
+	var found []string
+	for i := 0; i+1 < len(args); i++ {
+		if args[i] == flag {
+			found = append(found, args[i+1])
+		}
+	}
+	return found
+}
+
+func TestFilterArgsMapsAudioOptionally(t *testing.T) {
+	// One command serves recordings with and without audio
+	args := filterArgs("demo.mp4", ".demo-edited.partial.mp4", "scale=1280:-2", media.EncodeOptions{Codec: "libx264", AudioCopy: true})
+	if got, want := values(args, "-map"), []string{"0:v:0", "0:a?"}; !slices.Equal(got, want) {
+		t.Errorf("maps %q, want %q", got, want)
+	}
+	if got := values(args, "-c:a"); !slices.Equal(got, []string{"copy"}) {
+		t.Errorf("audio codec %q, want copy", got)
+	}
+}
+
+func TestAudioLost(t *testing.T) {
+	tests := []struct {
+		name          string
+		input, output media.Info
+		want          bool
+	}{
+		{"silent recording", silentInfo, silentInfo, false},
+		{"renderer dropped the audio", audioInfo, silentInfo, true},
+		{"renderer kept the audio", audioInfo, audioInfo, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := audioLost(tt.input, tt.output); got != tt.want {
+				t.Errorf("audioLost = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	args := restoreAudioArgs("demo.mp4", "demo-edited.mp4", ".demo-edited.partial.mp4")
+	if got, want := values(args, "-map"), []string{"0:v:0", "1:a"}; !slices.Equal(got, want) {
+		t.Errorf("restore maps %q, want %q", got, want)
+	}
+	if got, want := values(args, "-i"), []string{"demo-edited.mp4", "demo.mp4"}; !slices.Equal(got, want) {
+		t.Errorf("restore inputs %q, want %q", got, want)
+	}
+}
+
+func TestClickSoundArgs(t *testing.T) {
+	placed := []time.Duration{time.Second, 2500 * time.Millisecond}
+	encode := media.EncodeOptions{Codec: "libx264", AudioCopy: true, EvenSize: media.EvenPad}
+	tests := []struct {
+		name       string
+		info       media.Info
+		wantBed    string
+		wantInputs int
+	}{
+		{"over the recording's audio", audioInfo, "[0:a:0][d0][d1]amix", 2},
+		{"over silence as long as the video", silentInfo, "[2:a][d0][d1]amix", 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := clickSoundArgs("demo.mp4", "click.wav", ".demo.partial.mp4", tt.info, placed, 0.5, encode)
+			if inputs := values(args, "-i"); len(inputs) != tt.wantInputs {
+				t.Errorf("inputs %q, want %d", inputs, tt.wantInputs)
+			}
+			graph := strings.Join(values(args, "-filter_complex"), "")
+			if !strings.Contains(graph, tt.wantBed) {
+				t.Errorf("graph %q lacks %q", graph, tt.wantBed)
+			}
+			for _, delay := range []string{"adelay=delays=1000:", "adelay=delays=2500:"} {
+				if !strings.Contains(graph, delay) {
+					t.Errorf("graph %q lacks %q", graph, delay)
+				}
+			}
+			if got, want := values(args, "-map"), []string{"0:v:0", "[a]"}; !slices.Equal(got, want) {
+				t.Errorf("maps %q, want %q", got, want)
+			}
+			// The mix is encoded, never copied
+			if got := values(args, "-c:a"); !slices.Equal(got, []string{"aac"}) {
+				t.Errorf("audio codec %q, want aac", got)
+			}
+		})
+	}
+
+	// The silent bed lasts as long as the video
+	args := clickSoundArgs("demo.mp4", "click.wav", ".demo.partial.mp4", silentInfo, placed, 0.5, encode)
+	if got := values(args, "-t"); !slices.Equal(got, []string{"12.000"}) {
+		t.Errorf("silence length %q, want 12.000", got)
+	}
+}
@@ -0,0 +1,71 @@
+package editing
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/vedantwpatil/Screen-Capture/internal/config"
+)
+
+// BatchResult is the outcome of one edit in a batch
+type BatchResult struct {
+	Request Request
+	Err     error
+	Elapsed time.Duration
+}
+
+// EditBatch runs every request through Edit, workers at a time. A failed
+// clip is recorded and the batch carries on; once ctx is cancelled the
+// remaining clips fail with the context's error. onStart, if set, is called
+// as each clip begins with its 1-based position.
+func EditBatch(ctx context.Context, cfg *config.Config, reqs []Request, workers int,
+	onStart func(position, total int, req Request)) []BatchResult {
+	if workers < 1 {
+		workers = 1
+	}
+	results := make([]BatchResult, len(reqs))
+
+	queue := make(chan int)
+	var wg sync.WaitGroup
+	var startMu sync.Mutex
+	started := 0
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range queue {
+				req := reqs[i]
+				if err := ctx.Err(); err != nil {
+					results[i] = BatchResult{Request: req, Err: err}
+					continue
+				}
+
+				startMu.Lock()
+				started++
+				position := started
+				if onStart != nil {
+					onStart(position, len(reqs), req)
+				}
+				startMu.Unlock()
+
+				begin := time.Now()
+				err := Edit(ctx, cfg, req)
+				results[i] = BatchResult{Request: req, Err: err, Elapsed: time.Since(begin)}
+				// Edit only puts the output in place once it is complete, so a
+				// failed clip is still picked up on the next run
+				if err != nil {
+					slog.Error("Clip failed", "input", req.InputPath, "err", err)
+				}
+			}
+		}()
+	}
+
+	for i := range reqs {
+		queue <- i
+	}
+	close(queue)
+	wg.Wait()
+	return results
+}
@@ -0,0 +1,65 @@
+package editing
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/vedantwpatil/Screen-Capture/internal/ffmpegcmd"
+	"github.com/vedantwpatil/Screen-Capture/internal/media"
+	"github.com/vedantwpatil/Screen-Capture/internal/video"
+)
+
+// ApplyCamera crops each frame to the plan's view and scales it back up to
+// the full frame size, which is how the follow and click zooms are drawn
+func ApplyCamera(ctx context.Context, inputVideo string, outputVideo string, plan *video.CameraPlan, encode media.EncodeOptions) error {
+	info, err := media.Probe(inputVideo)
+	if err != nil {
+		return err
+	}
+
+	workDir, err := os.MkdirTemp("", "focusframe-camera-*")
+	if err != nil {
+		return fmt.Errorf("failed to create work directory: %w", err)
+	}
+	defer os.RemoveAll(workDir)
+	commands := filepath.Join(workDir, "camera.cmd")
+	if err := writeCameraCommands(commands, plan); err != nil {
+		return fmt.Errorf("failed to write camera commands: %w", err)
+	}
+
+	first := plan.Crop(0)
+	filter := fmt.Sprintf(
+		"sendcmd=f=%s,crop@camera=w=%d:h=%d:x=%d:y=%d,scale=%d:%d:flags=lanczos,setsar=1",
+		ffmpegcmd.EscapeFilterValue(commands), first.W, first.H, first.X, first.Y, info.Width, info.Height,
+	)
+	return renderWithFilter(ctx, inputVideo, outputVideo, filter, encode)
+}
+
+// writeCameraCommands writes a sendcmd script that moves and resizes the
+// crop, skipping frames where the camera holds still
+func writeCameraCommands(path string, plan *video.CameraPlan) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	w := bufio.NewWriter(f)
+	last := plan.Crop(0)
+	for i := 1; i < len(plan.Frames); i++ {
+		c := plan.Crop(i)
+		if c == last {
+			continue
+		}
+		ts := float64(i) / plan.FPS
+		fmt.Fprintf(w, "%.4f crop@camera w %d, crop@camera h %d, crop@camera x %d, crop@camera y %d;\n", ts, c.W, c.H, c.X, c.Y)
+		last = c
+	}
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,153 @@
+package editing
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/vedantwpatil/Screen-Capture/internal/atomicfile"
+	"github.com/vedantwpatil/Screen-Capture/internal/config"
+	"github.com/vedantwpatil/Screen-Capture/internal/ffmpegcmd"
+	"github.com/vedantwpatil/Screen-Capture/internal/media"
+)
+
+const (
+	clickSampleRate = 48000
+	clickLength     = 45 * time.Millisecond
+	clickGroupGap   = 15 * time.Millisecond // Clicks closer than this sound once, e.g. a double click's halves
+)
+
+// AddClickSounds mixes a short click into the audio at each of clicks (times
+// in the video, in order). A recording without audio gets a silent track to
+// carry them. The video stream is re-encoded with encode like any other pass.
+func AddClickSounds(ctx context.Context, inputVideo string, outputVideo string, clicks []time.Duration, cfg config.ClickSoundConfig, encode media.EncodeOptions) error {
+	info, err := media.Probe(inputVideo)
+	if err != nil {
+		return err
+	}
+	placed := clickInstances(clicks, cfg.MaxOverlap)
+	if len(placed) == 0 {
+		return errors.New("no clicks to sound")
+	}
+
+	workDir, err := os.MkdirTemp("", "focusframe-clicks-*")
+	if err != nil {
+		return fmt.Errorf("failed to create work directory: %w", err)
+	}
+	defer os.RemoveAll(workDir)
+	clickPath := filepath.Join(workDir, "click.wav")
+	if err := writeClickWAV(clickPath); err != nil {
+		return fmt.Errorf("failed to write click sound: %w", err)
+	}
+
+	tempOutput := atomicfile.PartialPath(outputVideo)
+	args := clickSoundArgs(inputVideo, clickPath, tempOutput, info, placed, cfg.Volume, encode)
+	return runRender(ctx, args, tempOutput, outputVideo)
+}
+
+// clickSoundArgs mixes a click at each of placed into the recording's audio,
+// or into silence as long as the video when it has none
+func clickSoundArgs(inputVideo string, clickPath string, tempOutput string, info media.Info, placed []time.Duration, volume float64, encode media.EncodeOptions) []string {
+	// The bed sets the length: the recording's own audio, or silence as long
+	// as the video
+	bed := "[0:a:0]"
+	if info.AudioStreams == 0 {
+		bed = "[2:a]"
+	}
+	var graph strings.Builder
+	fmt.Fprintf(&graph, "[1:a]volume=%g,asplit=%d", volume, len(placed))
+	for i := range placed {
+		fmt.Fprintf(&graph, "[c%d]", i)
+	}
+	graph.WriteString(";")
+	for i, at := range placed {
+		fmt.Fprintf(&graph, "[c%d]adelay=delays=%d:all=1[d%d];", i, at.Milliseconds(), i)
+	}
+	graph.WriteString(bed)
+	for i := range placed {
+		fmt.Fprintf(&graph, "[d%d]", i)
+	}
+	fmt.Fprintf(&graph, "amix=inputs=%d:duration=first:normalize=0[a]", len(placed)+1)
+
+	cmd := ffmpegcmd.New().Input(inputVideo).Input(clickPath)
+	if info.AudioStreams == 0 {
+		cmd.Device("lavfi", fmt.Sprintf("anullsrc=r=%d:cl=mono", clickSampleRate), "-t", strconv.FormatFloat(info.Duration.Seconds(), 'f', 3, 64))
+	}
+	// The frame is already even from the earlier passes, and the even-size
+	// filter would otherwise be routed onto the audio label
+	encode.EvenSize = ""
+	encode.AudioCopy = false
+	return cmd.FilterComplex(graph.String()).
+		Map("0:v:0", "[a]").
+		Output(tempOutput, encode, "-c:a", "aac", "-b:a", "160k").
+		Args()
+}
+
+// clickInstances groups clicks closer than clickGroupGap into one sound and
+// drops any that would make more than maxOverlap sound at once
+func clickInstances(clicks []time.Duration, maxOverlap int) []time.Duration {
+	var placed []time.Duration
+	for _, at := range clicks {
+		if at < 0 {
+			continue
+		}
+		if n := len(placed); n > 0 && at-placed[n-1] < clickGroupGap {
+			continue
+		}
+		sounding := 0
+		for i := len(placed) - 1; i >= 0 && at-placed[i] < clickLength; i-- {
+			sounding++
+		}
+		if sounding >= maxOverlap {
+			continue
+		}
+		placed = append(placed, at)
+	}
+	return placed
+}
+
+// writeClickWAV writes a soft mouse click: a short burst of noise over a
+// decaying high tone, as 16-bit mono PCM. The noise comes from a fixed
+// generator, so the same click is written every time.
+func writeClickWAV(path string) error {
+	samples := int(clickLength.Seconds() * clickSampleRate)
+	pcm := make([]int16, samples)
+	seed := uint32(0x2545f491)
+	for i := range pcm {
+		t := float64(i) / clickSampleRate
+		seed = seed*1664525 + 1013904223
+		noise := float64(int32(seed)) / math.MaxInt32 * math.Exp(-t*900) * 0.5
+		tone := math.Sin(2*math.Pi*2400*t) * math.Exp(-t*120) * 0.5
+		pcm[i] = int16((noise + tone) * 0.8 * math.MaxInt16)
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	dataSize := uint32(len(pcm) * 2)
+	header := []any{
+		[4]byte{'R', 'I', 'F', 'F'}, 36 + dataSize, [4]byte{'W', 'A', 'V', 'E'},
+		[4]byte{'f', 'm', 't', ' '}, uint32(16), uint16(1), uint16(1),
+		uint32(clickSampleRate), uint32(clickSampleRate * 2), uint16(2), uint16(16),
+		[4]byte{'d', 'a', 't', 'a'}, dataSize,
+	}
+	for _, v := range header {
+		if err := binary.Write(f, binary.LittleEndian, v); err != nil {
+			f.Close()
+			return err
+		}
+	}
+	if err := binary.Write(f, binary.LittleEndian, pcm); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
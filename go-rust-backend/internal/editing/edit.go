@@ -0,0 +1,322 @@
+package editing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/vedantwpatil/Screen-Capture/internal/atomicfile"
+	"github.com/vedantwpatil/Screen-Capture/internal/config"
+	"github.com/vedantwpatil/Screen-Capture/internal/markers"
+	"github.com/vedantwpatil/Screen-Capture/internal/media"
+	"github.com/vedantwpatil/Screen-Capture/internal/progress"
+	"github.com/vedantwpatil/Screen-Capture/internal/tracking"
+	"github.com/vedantwpatil/Screen-Capture/internal/video"
+)
+
+// Request describes one edit of a finished recording
+type Request struct {
+	InputPath    string
+	OutputPath   string
+	MouseHistory []tracking.CursorPosition // None skips the cursor render and camera, e.g. for another tool's recording
+	StartTime    time.Time                 // Wall-clock start of the capture, used by the timecode
+
+	// CursorSpace is the size of the screen the cursor data was recorded on,
+	// when it differs from the video's (e.g. points on a Retina display, or
+	// another tool's scaled export). Zero means the video's own size.
+	CursorSpace image.Point
+
+	// SkipClicks are the start times of click groups (as markers.FromClicks
+	// lists them with markers.DefaultGap) the camera shouldn't zoom on, e.g.
+	// misclicks. Their clicks still reach the cursor render and markers.
+	SkipClicks []time.Duration
+
+	// Preview renders a quick low-resolution version with the preview
+	// profile instead, normally to PreviewPath(OutputPath). The camera moves
+	// exactly as in the full render. Markers and postprocessing are skipped.
+	Preview bool
+
+	// Progress receives the cursor render's completion from 0 to 1
+	Progress func(percent float32)
+
+	// Reporter receives every stage of the edit and its result. It takes
+	// precedence over Progress; with neither set progress goes to stdout when
+	// info output is enabled, as a bar on a terminal or periodic lines otherwise.
+	Reporter progress.Reporter
+}
+
+func (r Request) reporter(ctx context.Context) progress.Reporter {
+	switch {
+	case r.Reporter != nil:
+		return r.Reporter
+	case r.Progress != nil:
+		return progress.Func(r.Progress)
+	case slog.Default().Enabled(ctx, slog.LevelInfo):
+		return progress.NewTerminal(os.Stdout)
+	default:
+		return progress.Discard{}
+	}
+}
+
+// EditedPath names the edit of a recording, e.g. demo-edited.mp4 for demo.mp4
+func EditedPath(inputPath string) string {
+	return strings.TrimSuffix(inputPath, filepath.Ext(inputPath)) + "-edited.mp4"
+}
+
+// Edit renders the cursor effects and then any camera, timecode, subtitle and
+// click sound passes, then hands the result to the configured postprocess command and webhook
+func Edit(ctx context.Context, cfg *config.Config, req Request) error {
+	reporter := req.reporter(ctx)
+	err := edit(ctx, cfg, req, reporter)
+	reporter.Finish(req.OutputPath, err)
+	return err
+}
+
+func edit(ctx context.Context, cfg *config.Config, req Request, reporter progress.Reporter) error {
+	began := time.Now()
+	slog.Info("Starting video processing")
+	slog.Info("Edit plan", "input", req.InputPath, "output", req.OutputPath, "mouse_events", len(req.MouseHistory))
+
+	// Check if we have enough mouse data
+	cursor := len(req.MouseHistory) > 0
+	if cursor && len(req.MouseHistory) < 4 {
+		return fmt.Errorf("not enough mouse data for smoothing (need at least 4 points, got %d)", len(req.MouseHistory))
+	}
+
+	info, err := media.ProbeVideo(req.InputPath)
+	if err != nil {
+		return fmt.Errorf("failed to read recording: %w", err)
+	}
+	if cursor {
+		if err := checkCursorTiming(req.MouseHistory, info.Duration); err != nil {
+			return err
+		}
+		if space := req.CursorSpace; space.X > 0 && space.Y > 0 && (space.X != info.Width || space.Y != info.Height) {
+			slog.Info("Mapping cursor data onto the video", "from", fmt.Sprintf("%dx%d", space.X, space.Y), "to", info.Resolution())
+			req.MouseHistory = tracking.ScaleHistory(req.MouseHistory,
+				float64(info.Width)/float64(space.X), float64(info.Height)/float64(space.Y))
+		}
+	} else {
+		slog.Warn("No cursor data, skipping the cursor render and camera")
+	}
+
+	// The camera and cursor positions are worked out once, and reused from
+	// <name>.camera.json when it was built from the same recording and settings
+	var plan *video.CameraPlan
+	if cursor {
+		history := withoutSkippedClicks(req.MouseHistory, req.SkipClicks)
+		if plan, err = video.CameraPlanFor(req.InputPath, history, info, cfg); err != nil {
+			return fmt.Errorf("failed to plan the camera: %w", err)
+		}
+	}
+
+	source := previewSource{path: req.InputPath, history: req.MouseHistory, plan: plan}
+	if req.Preview {
+		if cfg, err = cfg.ForPreview(); err != nil {
+			return err
+		}
+		source, err = scaleForPreview(ctx, cfg, req, info, plan, reporter)
+		if source.scaled {
+			defer os.Remove(source.path)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	// Everything renders into a partial file that only takes the output's
+	// name once the render is verified and every pass is done, so an
+	// interrupted edit never leaves a truncated video that looks finished
+	partial := atomicfile.PartialPath(req.OutputPath)
+	passes := editPasses(cfg, req, source.plan)
+	if cursor {
+		err = ProcessEffect(
+			ctx,
+			cfg,
+			source.path,
+			partial,
+			source.history,
+			source.plan,
+			reporter,
+		)
+		if err == nil {
+			err = runPasses(ctx, cfg, partial, partial, passes, reporter)
+		}
+	} else if len(passes) == 0 {
+		err = errors.New("nothing to render: there is no cursor data, and no timecode, subtitles or other pass is enabled")
+	} else {
+		err = runPasses(ctx, cfg, source.path, partial, passes, reporter)
+	}
+	if err != nil {
+		os.Remove(partial)
+		return err
+	}
+	if err := atomicfile.Finalize(partial, req.OutputPath); err != nil {
+		return err
+	}
+
+	// The video is already done, so a marker problem shouldn't fail the edit
+	if format := cfg.Export.Markers; format != "" && cursor {
+		path := markers.PathFor(req.OutputPath, format)
+		found := markers.FromClicks(req.MouseHistory, markers.DefaultGap)
+		if err := markers.ExportFile(req.OutputPath, found, format, path); err != nil {
+			slog.Warn("Failed to export markers", "err", err)
+		} else {
+			slog.Info("Exported markers", "file", path, "markers", len(found))
+		}
+	}
+
+	if req.Preview {
+		return nil
+	}
+	postprocess(ctx, cfg.Postprocess, req, time.Since(began))
+	return nil
+}
+
+// pass is one ffmpeg re-encode after the cursor render
+type pass struct {
+	stage   string
+	failure string
+	run     func(ctx context.Context, input string, output string, encode media.EncodeOptions) error
+}
+
+// editPasses lists the passes the config and recording call for, in order
+func editPasses(cfg *config.Config, req Request, plan *video.CameraPlan) []pass {
+	var passes []pass
+	if plan != nil && plan.Moves() {
+		passes = append(passes, pass{
+			stage:   progress.StageCamera,
+			failure: "camera pass failed",
+			run: func(ctx context.Context, input string, output string, encode media.EncodeOptions) error {
+				return ApplyCamera(ctx, input, output, plan, encode)
+			},
+		})
+	}
+	if cfg.Effects.Timecode.Enabled {
+		style := TimecodeStyleFromConfig(cfg)
+		if cfg.Effects.Timecode.WallClock {
+			style.Origin = req.StartTime
+		}
+		passes = append(passes, pass{
+			stage:   progress.StageTimecode,
+			failure: "timecode overlay failed",
+			run: func(ctx context.Context, input string, output string, encode media.EncodeOptions) error {
+				return BurnTimecode(ctx, input, output, style, encode)
+			},
+		})
+	}
+
+	// Burn in a transcript saved next to the recording (e.g. demo.srt)
+	srtPath := strings.TrimSuffix(req.InputPath, filepath.Ext(req.InputPath)) + ".srt"
+	if _, err := os.Stat(srtPath); err == nil {
+		passes = append(passes, pass{
+			stage:   progress.StageSubtitles,
+			failure: "subtitle burn-in failed",
+			run: func(ctx context.Context, input string, output string, encode media.EncodeOptions) error {
+				slog.Info("Burning in subtitles", "srt", srtPath)
+				return BurnSubtitles(ctx, input, srtPath, output, SubtitleStyle{}, encode)
+			},
+		})
+	}
+
+	// Click times are relative to the cursor render's first frame, which the
+	// later passes keep
+	if clicks := video.ClickOffsets(req.MouseHistory); cfg.Effects.ClickSound.Enabled && len(clicks) > 0 {
+		passes = append(passes, pass{
+			stage:   progress.StageClicks,
+			failure: "click sound pass failed",
+			run: func(ctx context.Context, input string, output string, encode media.EncodeOptions) error {
+				return AddClickSounds(ctx, input, output, clicks, cfg.Effects.ClickSound, encode)
+			},
+		})
+	}
+	return passes
+}
+
+// runPasses applies passes to source, writing video; the two may be the
+// same file. Every pass but the last writes a hidden intermediate next to
+// video in the intermediate profile, so the export settings' quality loss
+// happens once rather than per pass.
+func runPasses(ctx context.Context, cfg *config.Config, source string, video string, passes []pass, reporter progress.Reporter) error {
+	input := source
+	for i, p := range passes {
+		output, encode := video, cfg.ExportEncodeOptions()
+		if i < len(passes)-1 {
+			var ext string
+			encode, ext = cfg.IntermediateEncodeOptions()
+			base := strings.TrimSuffix(filepath.Base(video), filepath.Ext(video))
+			output = filepath.Join(filepath.Dir(video), "."+base+"."+p.stage+ext)
+			defer os.Remove(output)
+		}
+
+		reporter.Stage(p.stage, 0)
+		if err := p.run(ctx, input, output, encode); err != nil {
+			return fmt.Errorf("%s: %w", p.failure, err)
+		}
+		if input != source && input != video {
+			os.Remove(input) // The previous intermediate is no longer needed
+		}
+		input = output
+	}
+	return nil
+}
+
+// maxCursorOverrun is how far cursor data may run past the end of the video.
+// The tracker starts with the capture but stops a little after it, so a
+// recording's own data always runs slightly over.
+const maxCursorOverrun = 3 * time.Second
+
+// checkCursorTiming rejects cursor data that can't belong to a video of the
+// given length, which usually means --cursor points at another recording's
+func checkCursorTiming(history []tracking.CursorPosition, duration time.Duration) error {
+	if duration <= 0 {
+		return nil
+	}
+	first, last := history[0].ClickTimeStamp, history[0].ClickTimeStamp
+	for _, p := range history {
+		first, last = min(first, p.ClickTimeStamp), max(last, p.ClickTimeStamp)
+	}
+	switch {
+	case first >= duration:
+		return fmt.Errorf("cursor data starts at %s, after the %s video ends; is it for another recording?",
+			first.Round(time.Millisecond), duration.Round(time.Millisecond))
+	case last-first > duration+maxCursorOverrun:
+		return fmt.Errorf("cursor data covers %s but the video is only %s long; is it for another recording?",
+			(last - first).Round(time.Millisecond), duration.Round(time.Millisecond))
+	}
+	return nil
+}
+
+// withoutSkippedClicks drops the clicks of every skipped click group
+func withoutSkippedClicks(history []tracking.CursorPosition, skipped []time.Duration) []tracking.CursorPosition {
+	if len(skipped) == 0 {
+		return history
+	}
+	groups := markers.FromClicks(history, markers.DefaultGap)
+	skip := make([]bool, len(groups))
+	for _, at := range skipped {
+		if i := markers.Index(groups, at); i >= 0 {
+			skip[i] = true
+		}
+	}
+
+	kept := make([]tracking.CursorPosition, 0, len(history))
+	for _, p := range history {
+		if p.Click {
+			// The group a click belongs to is the last one starting at or before it
+			g := sort.Search(len(groups), func(i int) bool { return groups[i].At > p.ClickTimeStamp }) - 1
+			if g >= 0 && skip[g] {
+				continue
+			}
+		}
+		kept = append(kept, p)
+	}
+	return kept
+}
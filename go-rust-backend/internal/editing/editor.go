@@ -1,34 +1,132 @@
 package editing
 
 import (
+	"context"
 	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
 
+	"github.com/vedantwpatil/Screen-Capture/internal/atomicfile"
+	"github.com/vedantwpatil/Screen-Capture/internal/config"
+	"github.com/vedantwpatil/Screen-Capture/internal/ffmpegcmd"
+	"github.com/vedantwpatil/Screen-Capture/internal/media"
+	"github.com/vedantwpatil/Screen-Capture/internal/progress"
 	"github.com/vedantwpatil/Screen-Capture/internal/tracking"
 	"github.com/vedantwpatil/Screen-Capture/internal/video"
 )
 
+// ProcessEffect renders the configured effects onto a finished recording,
+// reporting it as the render stage
 func ProcessEffect(
+	ctx context.Context,
+	cfg *config.Config,
 	inputVideo string,
 	outputVideo string,
 	mouseHistory []tracking.CursorPosition,
-	frameRate int16,
+	plan *video.CameraPlan,
+	reporter progress.Reporter,
 ) error {
-	// Progress handler
-	progressHandler := func(percent float32) {
-		fmt.Printf("\rProcessing: %.1f%%", percent*100)
+	if err := cfg.Validate(); err != nil {
+		return fmt.Errorf("invalid effect configuration: %w", err)
 	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	// Frame count lets reporters show a render rate; unknown is fine
+	var frames int64
+	renderInput := inputVideo
+	if info, err := media.Probe(inputVideo); err == nil {
+		frames = int64(info.Duration.Seconds() * info.FPS)
+
+		// The Rust engine encodes at the input's size, which libx264 rejects
+		// when odd, so such recordings are evened up first
+		if info.Width%2 != 0 || info.Height%2 != 0 {
+			slog.Info("Recording has an odd frame size, making it even before rendering",
+				"size", info.Resolution(), "mode", cfg.Export.OddSize)
+			encode, ext := cfg.IntermediateEncodeOptions()
+			base := strings.TrimSuffix(filepath.Base(outputVideo), filepath.Ext(outputVideo))
+			renderInput = filepath.Join(filepath.Dir(outputVideo), "."+base+".even"+ext)
+			defer os.Remove(renderInput)
+			if err := renderWithFilter(ctx, inputVideo, renderInput, "null", encode); err != nil {
+				return fmt.Errorf("failed to make frame size even: %w", err)
+			}
+		}
+	}
+	reporter.Stage(progress.StageRender, frames)
 
 	err := video.ProcessRecording(
-		inputVideo,
+		ctx,
+		cfg,
+		renderInput,
 		outputVideo,
 		mouseHistory,
-		frameRate,
-		progressHandler,
+		plan,
+		func(percent float32) { reporter.Progress(float64(percent)) },
 	)
 	if err != nil {
 		return fmt.Errorf("video processing failed: %w", err)
 	}
 
-	fmt.Println("\nProcessing complete!")
+	if err := restoreAudio(ctx, renderInput, outputVideo); err != nil {
+		return fmt.Errorf("failed to copy the recording's audio: %w", err)
+	}
+	if err := verifyRender(renderInput, outputVideo); err != nil {
+		return err
+	}
+
+	slog.Info("Processing complete", "output", outputVideo, "profile", cfg.Profile)
 	return nil
 }
+
+// restoreAudio copies the recording's audio into the render when the
+// renderer wrote video only, as the Rust engine does. The video stream is
+// copied as is.
+func restoreAudio(ctx context.Context, inputVideo string, outputVideo string) error {
+	inputInfo, err := media.Probe(inputVideo)
+	if err != nil {
+		return nil // verifyRender reports a probe failure
+	}
+	outputInfo, err := media.Probe(outputVideo)
+	if err != nil || !audioLost(inputInfo, outputInfo) {
+		return nil
+	}
+
+	tempOutput := atomicfile.PartialPath(outputVideo)
+	return runRender(ctx, restoreAudioArgs(inputVideo, outputVideo, tempOutput), tempOutput, outputVideo)
+}
+
+// audioLost reports whether the render dropped audio the recording has
+func audioLost(input media.Info, output media.Info) bool {
+	return input.AudioStreams > 0 && output.AudioStreams == 0
+}
+
+// restoreAudioArgs takes the render's video and the recording's audio
+func restoreAudioArgs(inputVideo string, outputVideo string, tempOutput string) []string {
+	return ffmpegcmd.New().
+		Input(outputVideo).
+		Input(inputVideo).
+		Map("0:v:0", "1:a").
+		Output(tempOutput, media.EncodeOptions{Codec: "copy", AudioCopy: true}).
+		Args()
+}
+
+// verifyRender checks the renderer produced a complete copy of the recording,
+// audio included
+func verifyRender(inputVideo string, outputVideo string) error {
+	inputInfo, err := media.Probe(inputVideo)
+	if err != nil {
+		return fmt.Errorf("failed to probe input for verification: %w", err)
+	}
+	outputInfo, err := media.Probe(outputVideo)
+	if err != nil {
+		return fmt.Errorf("failed to probe output for verification: %w", err)
+	}
+
+	return video.VerifyOutput(inputInfo, outputInfo, video.Expectations{
+		DurationTolerance: time.Second,
+	})
+}
@@ -0,0 +1,40 @@
+package editing
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/vedantwpatil/Screen-Capture/internal/atomicfile"
+	"github.com/vedantwpatil/Screen-Capture/internal/ffmpegcmd"
+	"github.com/vedantwpatil/Screen-Capture/internal/media"
+)
+
+// renderWithFilter re-encodes inputVideo through a single video filter.
+// Rendering goes to a partial file first so a failed pass never leaves a
+// truncated output behind (and in-place edits are possible).
+// Cancelling ctx kills ffmpeg, removes the partial file and returns ctx.Err().
+func renderWithFilter(ctx context.Context, inputVideo string, outputVideo string, filter string, encode media.EncodeOptions) error {
+	tempOutput := atomicfile.PartialPath(outputVideo)
+	return runRender(ctx, filterArgs(inputVideo, tempOutput, filter, encode), tempOutput, outputVideo)
+}
+
+// filterArgs maps audio optionally ("0:a?") so inputs without an audio track
+// work with the same command, and inputs with one keep it
+func filterArgs(inputVideo string, tempOutput string, filter string, encode media.EncodeOptions) []string {
+	return ffmpegcmd.New().
+		Input(inputVideo).
+		Filter(filter).
+		Map("0:v:0", "0:a?").
+		Output(tempOutput, encode).
+		Args()
+}
+
+// runRender runs ffmpeg with args, which write tempOutput, and moves the
+// result to outputVideo once it succeeds
+func runRender(ctx context.Context, args []string, tempOutput string, outputVideo string) error {
+	err := ffmpegcmd.Render(ctx, args, tempOutput, outputVideo)
+	if err != nil && ctx.Err() == nil {
+		return fmt.Errorf("ffmpeg filter pass failed: %w", err)
+	}
+	return err
+}
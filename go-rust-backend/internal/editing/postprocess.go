@@ -0,0 +1,103 @@
+package editing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+
+	"github.com/vedantwpatil/Screen-Capture/internal/config"
+	"github.com/vedantwpatil/Screen-Capture/internal/ffmpegcmd"
+	"github.com/vedantwpatil/Screen-Capture/internal/media"
+	"github.com/vedantwpatil/Screen-Capture/internal/tracking"
+)
+
+// webhookPayload is POSTed after each edit. Text makes it readable as-is by
+// Slack and other incoming-webhook services.
+type webhookPayload struct {
+	Text         string  `json:"text"`
+	Input        string  `json:"input"`
+	Output       string  `json:"output"`
+	Cursor       string  `json:"cursor"`
+	DurationSecs float64 `json:"duration_secs"`
+	Size         int64   `json:"size"`
+	RenderSecs   float64 `json:"render_secs"`
+}
+
+// postprocess runs the configured command and webhook for a finished edit.
+// The edited file is complete by now, so problems are only logged: a broken
+// upload script must never cost the user their render.
+func postprocess(ctx context.Context, cfg config.PostprocessConfig, req Request, renderTime time.Duration) {
+	if len(cfg.Command) == 0 && cfg.WebhookURL == "" {
+		return
+	}
+	timeout := time.Duration(cfg.TimeoutSecs) * time.Second
+	cursorPath := tracking.HistoryPath(req.InputPath)
+
+	if len(cfg.Command) > 0 {
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		args := append(append([]string{}, cfg.Command[1:]...), req.OutputPath, cursorPath)
+		cmd := exec.CommandContext(ctx, cfg.Command[0], args...)
+		slog.Info("Running postprocess command", "args", cmd.Args)
+		output, err := cmd.CombinedOutput()
+		cancel()
+		if len(output) > 0 {
+			slog.Info("Postprocess command output", "output", ffmpegcmd.LastLines(string(output), 20))
+		}
+		if ctx.Err() == context.DeadlineExceeded {
+			slog.Warn("Postprocess command timed out", "timeout", timeout)
+		} else if err != nil {
+			slog.Warn("Postprocess command failed", "err", err)
+		}
+	}
+
+	if cfg.WebhookURL != "" {
+		payload := webhookPayload{
+			Text:       fmt.Sprintf("FocusFrame finished %s", filepath.Base(req.OutputPath)),
+			Input:      req.InputPath,
+			Output:     req.OutputPath,
+			Cursor:     cursorPath,
+			RenderSecs: renderTime.Seconds(),
+		}
+		if info, err := os.Stat(req.OutputPath); err == nil {
+			payload.Size = info.Size()
+		}
+		if info, err := media.Probe(req.OutputPath); err == nil {
+			payload.DurationSecs = info.Duration.Seconds()
+		}
+		if err := postWebhook(ctx, cfg.WebhookURL, payload, timeout); err != nil {
+			slog.Warn("Webhook failed", "err", err)
+		} else {
+			slog.Info("Webhook delivered")
+		}
+	}
+}
+
+func postWebhook(ctx context.Context, url string, payload webhookPayload, timeout time.Duration) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(httpReq)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook returned %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package editing
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"strings"
+
+	"github.com/vedantwpatil/Screen-Capture/internal/config"
+	"github.com/vedantwpatil/Screen-Capture/internal/media"
+	"github.com/vedantwpatil/Screen-Capture/internal/progress"
+	"github.com/vedantwpatil/Screen-Capture/internal/tracking"
+	"github.com/vedantwpatil/Screen-Capture/internal/video"
+)
+
+// previewHeight is the tallest frame a preview renders
+const previewHeight = 480
+
+// PreviewPath names the preview that stands in for an edit, e.g.
+// demo-preview.mp4 for demo-edited.mp4
+func PreviewPath(editedPath string) string {
+	base := strings.TrimSuffix(editedPath, filepath.Ext(editedPath))
+	return strings.TrimSuffix(base, "-edited") + "-preview.mp4"
+}
+
+// previewSource is what a preview renders from
+type previewSource struct {
+	path    string // The recording, or a hidden scaled-down copy of it
+	history []tracking.CursorPosition
+	plan    *video.CameraPlan
+	scaled  bool // path is a temporary copy the caller removes
+}
+
+// scaleForPreview shrinks the recording to previewHeight, scaling the cursor
+// data, camera plan and cursor sprite (in cfg, a preview copy) to match.
+// The plan keeps its timing, so cuts judged on the preview hold in the full
+// render. Recordings that are already small are used as they are.
+func scaleForPreview(ctx context.Context, cfg *config.Config, req Request, info media.Info, plan *video.CameraPlan, reporter progress.Reporter) (previewSource, error) {
+	source := previewSource{path: req.InputPath, history: req.MouseHistory, plan: plan}
+	if info.Height <= previewHeight {
+		return source, nil
+	}
+
+	base := strings.TrimSuffix(filepath.Base(req.OutputPath), filepath.Ext(req.OutputPath))
+	scaledPath := filepath.Join(filepath.Dir(req.OutputPath), "."+base+".preview-source.mp4")
+	reporter.Stage(progress.StagePreview, 0)
+	filter := fmt.Sprintf("fps=%d,scale=-2:%d:flags=fast_bilinear", cfg.Recording.TargetFPS, previewHeight)
+	encode := media.EncodeOptions{Codec: "libx264", CRF: 23, Preset: "ultrafast", PixFmt: "yuv420p", AudioCopy: true}
+	if err := renderWithFilter(ctx, req.InputPath, scaledPath, filter, encode); err != nil {
+		return source, fmt.Errorf("failed to scale down for preview: %w", err)
+	}
+	source.path, source.scaled = scaledPath, true
+
+	scaledInfo, err := media.Probe(scaledPath)
+	if err != nil {
+		return source, err
+	}
+	sx := float64(scaledInfo.Width) / float64(info.Width)
+	sy := float64(scaledInfo.Height) / float64(info.Height)
+	slog.Info("Rendering preview", "size", scaledInfo.Resolution())
+
+	source.history = tracking.ScaleHistory(req.MouseHistory, sx, sy)
+	if plan != nil {
+		source.plan = plan.Scaled(sx, sy)
+	}
+	cfg.Effects.Cursor.Scale *= sy
+	return source, nil
+}
@@ -0,0 +1,193 @@
+package editing
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/vedantwpatil/Screen-Capture/internal/ffmpegcmd"
+	"github.com/vedantwpatil/Screen-Capture/internal/media"
+)
+
+// SubtitleStyle overrides the look of burned-in subtitles
+type SubtitleStyle struct {
+	FontName string // Empty keeps libass' default font
+	FontSize int    // Zero keeps the default size
+	Outline  int    // Outline thickness in pixels
+
+	// FontsDir is where libass looks for FontName. Empty uses the OS font directory.
+	FontsDir string
+}
+
+// subtitleCue is one numbered entry of an SRT file
+type subtitleCue struct {
+	Start time.Duration
+	End   time.Duration
+	Text  string
+}
+
+// BurnSubtitles renders the cues from an SRT file into the video.
+// The SRT file is parsed first so a malformed transcript is reported before
+// ffmpeg spends time re-encoding.
+func BurnSubtitles(ctx context.Context, inputVideo string, srtPath string, outputVideo string, style SubtitleStyle, encode media.EncodeOptions) error {
+	file, err := os.Open(srtPath)
+	if err != nil {
+		return fmt.Errorf("failed to open subtitles: %w", err)
+	}
+	cues, err := parseSRT(bufio.NewScanner(file))
+	file.Close()
+	if err != nil {
+		return fmt.Errorf("invalid subtitles file %s: %w", srtPath, err)
+	}
+	if len(cues) == 0 {
+		return fmt.Errorf("subtitles file %s contains no cues", srtPath)
+	}
+
+	return renderWithFilter(ctx, inputVideo, outputVideo, subtitlesFilter(srtPath, style), encode)
+}
+
+// subtitlesFilter builds the subtitles filter for the given file and style
+func subtitlesFilter(srtPath string, style SubtitleStyle) string {
+	fontsDir := style.FontsDir
+	if fontsDir == "" {
+		fontsDir = defaultFontsDir()
+	}
+
+	opts := []string{"filename=" + ffmpegcmd.EscapeFilterValue(srtPath)}
+	if fontsDir != "" {
+		opts = append(opts, "fontsdir="+ffmpegcmd.EscapeFilterValue(fontsDir))
+	}
+
+	var forceStyle []string
+	if style.FontName != "" {
+		forceStyle = append(forceStyle, "FontName="+style.FontName)
+	}
+	if style.FontSize > 0 {
+		forceStyle = append(forceStyle, fmt.Sprintf("FontSize=%d", style.FontSize))
+	}
+	if style.Outline > 0 {
+		forceStyle = append(forceStyle, fmt.Sprintf("Outline=%d", style.Outline))
+	}
+	if len(forceStyle) > 0 {
+		opts = append(opts, "force_style="+ffmpegcmd.EscapeFilterValue(strings.Join(forceStyle, ",")))
+	}
+
+	return "subtitles=" + strings.Join(opts, ":")
+}
+
+// defaultFontsDir returns the system font directory for the current OS
+func defaultFontsDir() string {
+	switch runtime.GOOS {
+	case "darwin":
+		return "/System/Library/Fonts"
+	case "windows":
+		return `C:\Windows\Fonts`
+	case "linux":
+		return "/usr/share/fonts"
+	default:
+		return ""
+	}
+}
+
+// parseSRT reads SRT cues, rejecting malformed timings
+func parseSRT(scanner *bufio.Scanner) ([]subtitleCue, error) {
+	var cues []subtitleCue
+	var block []string
+	lineNumber := 0
+
+	flush := func() error {
+		defer func() { block = block[:0] }()
+		if len(block) == 0 {
+			return nil
+		}
+		if len(block) < 2 {
+			return fmt.Errorf("line %d: incomplete cue", lineNumber)
+		}
+		if _, err := strconv.Atoi(block[0]); err != nil {
+			return fmt.Errorf("line %d: expected cue number, got %q", lineNumber, block[0])
+		}
+		start, end, err := parseSRTTiming(block[1])
+		if err != nil {
+			return fmt.Errorf("line %d: %w", lineNumber, err)
+		}
+		cues = append(cues, subtitleCue{
+			Start: start,
+			End:   end,
+			Text:  strings.Join(block[2:], "\n"),
+		})
+		return nil
+	}
+
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if lineNumber == 1 {
+			line = strings.TrimPrefix(line, "\ufeff")
+		}
+
+		if strings.TrimSpace(line) == "" {
+			if err := flush(); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		block = append(block, strings.TrimSpace(line))
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if err := flush(); err != nil {
+		return nil, err
+	}
+
+	return cues, nil
+}
+
+// parseSRTTiming parses "00:00:01,000 --> 00:00:04,500"
+func parseSRTTiming(line string) (time.Duration, time.Duration, error) {
+	parts := strings.Split(line, "-->")
+	if len(parts) != 2 {
+		return 0, 0, fmt.Errorf("expected timing line, got %q", line)
+	}
+
+	start, err := parseSRTTimestamp(strings.TrimSpace(parts[0]))
+	if err != nil {
+		return 0, 0, err
+	}
+	// Position hints (X1:... Y1:...) may follow the end timestamp
+	endField := strings.Fields(strings.TrimSpace(parts[1]))
+	if len(endField) == 0 {
+		return 0, 0, fmt.Errorf("missing end time in %q", line)
+	}
+	end, err := parseSRTTimestamp(endField[0])
+	if err != nil {
+		return 0, 0, err
+	}
+	if end < start {
+		return 0, 0, fmt.Errorf("cue ends before it starts: %q", line)
+	}
+
+	return start, end, nil
+}
+
+// parseSRTTimestamp parses "HH:MM:SS,mmm" (a dot separator is accepted too)
+func parseSRTTimestamp(value string) (time.Duration, error) {
+	var hours, minutes, seconds, millis int
+	normalized := strings.Replace(value, ".", ",", 1)
+	if _, err := fmt.Sscanf(normalized, "%d:%d:%d,%d", &hours, &minutes, &seconds, &millis); err != nil {
+		return 0, fmt.Errorf("invalid timestamp %q", value)
+	}
+	if minutes >= 60 || seconds >= 60 || millis >= 1000 || hours < 0 || minutes < 0 || seconds < 0 || millis < 0 {
+		return 0, fmt.Errorf("timestamp out of range %q", value)
+	}
+
+	return time.Duration(hours)*time.Hour +
+		time.Duration(minutes)*time.Minute +
+		time.Duration(seconds)*time.Second +
+		time.Duration(millis)*time.Millisecond, nil
+}
@@ -0,0 +1,92 @@
+package editing
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/vedantwpatil/Screen-Capture/internal/config"
+	"github.com/vedantwpatil/Screen-Capture/internal/ffmpegcmd"
+	"github.com/vedantwpatil/Screen-Capture/internal/media"
+)
+
+// TimecodeStyle controls how the running timecode is drawn
+type TimecodeStyle struct {
+	FontFile string
+	FontSize int
+	Corner   string
+	Box      bool
+
+	// Origin is the wall-clock time of the first frame. The zero value
+	// renders elapsed time (00:00:00.000) instead.
+	Origin time.Time
+}
+
+// TimecodeStyleFromConfig builds a style from the timecode effect settings.
+// The caller is responsible for setting Origin when WallClock is enabled.
+func TimecodeStyleFromConfig(cfg *config.Config) TimecodeStyle {
+	return TimecodeStyle{
+		FontFile: cfg.Effects.Timecode.FontFile,
+		FontSize: cfg.Effects.Timecode.FontSize,
+		Corner:   cfg.Effects.Timecode.Corner,
+		Box:      cfg.Effects.Timecode.Box,
+	}
+}
+
+// BurnTimecode draws a running timecode into a corner of the video.
+// inputVideo and outputVideo may be the same path, in which case the file is
+// replaced once the new render has finished.
+func BurnTimecode(ctx context.Context, inputVideo string, outputVideo string, style TimecodeStyle, encode media.EncodeOptions) error {
+	filter, err := timecodeFilter(style)
+	if err != nil {
+		return err
+	}
+
+	return renderWithFilter(ctx, inputVideo, outputVideo, filter, encode)
+}
+
+// timecodeFilter builds the drawtext filter for the given style
+func timecodeFilter(style TimecodeStyle) (string, error) {
+	var x, y string
+	switch style.Corner {
+	case "top-left":
+		x, y = "20", "20"
+	case "top-right":
+		x, y = "w-tw-20", "20"
+	case "bottom-left":
+		x, y = "20", "h-th-20"
+	case "bottom-right", "":
+		x, y = "w-tw-20", "h-th-20"
+	default:
+		return "", fmt.Errorf("unknown timecode corner %q", style.Corner)
+	}
+
+	// Colons inside the expansion must be escaped once for drawtext, and the
+	// strftime colons a second time because they sit inside the pts arguments
+	text := `%{pts\:hms}`
+	if !style.Origin.IsZero() {
+		text = fmt.Sprintf(`%%{pts\:localtime\:%d\:%%H\\\:%%M\\\:%%S}`, style.Origin.Unix())
+	}
+
+	fontSize := style.FontSize
+	if fontSize <= 0 {
+		fontSize = 32
+	}
+
+	opts := []string{
+		fmt.Sprintf("text='%s'", text),
+		fmt.Sprintf("fontsize=%d", fontSize),
+		"fontcolor=white",
+		"x=" + x,
+		"y=" + y,
+	}
+	if style.FontFile != "" {
+		opts = append(opts, "fontfile="+ffmpegcmd.EscapeFilterValue(style.FontFile))
+	}
+	if style.Box {
+		opts = append(opts, "box=1", "boxcolor=black@0.5", "boxborderw=10")
+	}
+
+	return "drawtext=" + strings.Join(opts, ":"), nil
+}
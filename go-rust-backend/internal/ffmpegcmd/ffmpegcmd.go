@@ -0,0 +1,179 @@
+// Package ffmpegcmd builds ffmpeg argument lists so call sites don't each
+// reinvent flag ordering, overwrite handling and filter escaping.
+package ffmpegcmd
+
+import (
+	"strings"
+
+	"github.com/vedantwpatil/Screen-Capture/internal/media"
+)
+
+// Builder assembles one ffmpeg invocation. Every method returns the builder
+// so a command reads top to bottom in the order ffmpeg expects it.
+type Builder struct {
+	global      []string
+	inputs      []string
+	filterFlag  string
+	filter      string
+	maps        []string
+	output      []string
+	evenFilter  string // From the output's EncodeOptions.EvenSize
+	interactive bool
+}
+
+func New() *Builder {
+	return &Builder{}
+}
+
+// Global appends options that apply to the whole run, e.g. "-v", "error"
+func (b *Builder) Global(args ...string) *Builder {
+	b.global = append(b.global, args...)
+	return b
+}
+
+// Interactive keeps stdin attached, which live capture needs so it can be
+// stopped cleanly by writing "q". Everything else gets -nostdin so ffmpeg
+// never swallows terminal input meant for the menu.
+func (b *Builder) Interactive() *Builder {
+	b.interactive = true
+	return b
+}
+
+// Input adds a file input preceded by its input options
+func (b *Builder) Input(path string, opts ...string) *Builder {
+	b.inputs = append(b.inputs, opts...)
+	b.inputs = append(b.inputs, "-i", filePath(path))
+	return b
+}
+
+// Device adds a capture device input such as avfoundation "1:none". The name
+// is passed through untouched since colons are part of device syntax.
+func (b *Builder) Device(format string, name string, opts ...string) *Builder {
+	b.inputs = append(b.inputs, "-f", format)
+	b.inputs = append(b.inputs, opts...)
+	b.inputs = append(b.inputs, "-i", name)
+	return b
+}
+
+// Filter sets a simple per-stream video filter (-vf)
+func (b *Builder) Filter(graph string) *Builder {
+	b.filterFlag, b.filter = "-vf", graph
+	return b
+}
+
+// FilterComplex sets a graph spanning several inputs (-filter_complex)
+func (b *Builder) FilterComplex(graph string) *Builder {
+	b.filterFlag, b.filter = "-filter_complex", graph
+	return b
+}
+
+// Map selects streams for the output, e.g. "0:v:0" or "[out]"
+func (b *Builder) Map(specs ...string) *Builder {
+	for _, spec := range specs {
+		b.maps = append(b.maps, "-map", spec)
+	}
+	return b
+}
+
+// Output sets the destination, overwriting it if present. opts go before the
+// encoder settings, e.g. "-an" or "-f", "null" with a path of "-".
+//
+// An EvenSize in encode is applied by extending the video filter, so every
+// encode is covered without each call site probing the frame size.
+func (b *Builder) Output(path string, encode media.EncodeOptions, opts ...string) *Builder {
+	b.evenFilter = encode.EvenSizeFilter()
+	b.output = append(b.output, opts...)
+	b.output = append(b.output, encode.Args()...)
+	b.output = append(b.output, "-y", filePath(path))
+	return b
+}
+
+// Args returns the arguments to pass to exec.Command("ffmpeg", ...)
+func (b *Builder) Args() []string {
+	args := []string{"-hide_banner"}
+	if !b.interactive {
+		args = append(args, "-nostdin")
+	}
+	args = append(args, b.global...)
+	args = append(args, b.inputs...)
+	if flag, graph := b.videoFilter(); graph != "" {
+		args = append(args, flag, graph)
+	}
+	args = append(args, b.maps...)
+	args = append(args, b.output...)
+	return args
+}
+
+// videoFilter returns the filter flag and graph with the even-size filter
+// added at the end of the video chain
+func (b *Builder) videoFilter() (string, string) {
+	if b.evenFilter == "" {
+		return b.filterFlag, b.filter
+	}
+	switch b.filterFlag {
+	case "":
+		return "-vf", b.evenFilter
+	case "-vf":
+		return "-vf", b.filter + "," + b.evenFilter
+	}
+
+	// A complex graph's video result is the labelled pad mapped to the
+	// output; route it through the filter under a new label
+	for i := 1; i < len(b.maps); i += 2 {
+		label := b.maps[i]
+		if !strings.HasPrefix(label, "[") {
+			continue
+		}
+		at := strings.LastIndex(b.filter, label)
+		if at < 0 {
+			continue
+		}
+		uneven := strings.TrimSuffix(label, "]") + "_uneven]"
+		graph := b.filter[:at] + uneven + b.filter[at+len(label):]
+		return b.filterFlag, graph + ";" + uneven + b.evenFilter + label
+	}
+	return b.filterFlag, b.filter
+}
+
+// filePath stops ffmpeg reading a file name as something else: a leading
+// dash would look like an option and "name:rest" like a protocol prefix
+func filePath(path string) string {
+	if path == "-" || strings.HasPrefix(path, "pipe:") || strings.HasPrefix(path, "file:") {
+		return path
+	}
+	if strings.HasPrefix(path, "-") || strings.Contains(path, ":") {
+		return "file:" + path
+	}
+	return path
+}
+
+// LastLines returns the last n lines of a command's output, trimmed of
+// surrounding blank space, for error messages
+func LastLines(output string, n int) string {
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return strings.Join(lines, "\n")
+}
+
+// EscapeFilterValue escapes a value, typically a path, for use as a filter
+// option. ffmpeg unescapes filter arguments twice (filtergraph parsing, then
+// option parsing), so option-level specials are escaped first and the result
+// is escaped again for the graph level.
+func EscapeFilterValue(value string) string {
+	optionLevel := backslashEscape(value, `\':`)
+	return backslashEscape(optionLevel, `\'[],;`)
+}
+
+// backslashEscape prefixes every character in specials with a backslash
+func backslashEscape(value string, specials string) string {
+	var b strings.Builder
+	for _, r := range value {
+		if strings.ContainsRune(specials, r) {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
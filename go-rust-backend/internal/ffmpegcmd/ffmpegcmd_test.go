@@ -0,0 +1,237 @@
+package ffmpegcmd
+
+import (
+	"math"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/vedantwpatil/Screen-Capture/internal/media"
+)
+
+func TestFilePath(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"demo.mp4", "demo.mp4"},
+		{"output/demo take.mp4", "output/demo take.mp4"},
+		{"-demo.mp4", "file:-demo.mp4"},
+		{"--help", "file:--help"},
+		{"clip:1.mp4", "file:clip:1.mp4"},
+		{"http://example.com/x.mp4", "file:http://example.com/x.mp4"},
+		{`C:\Users\me\demo.mp4`, `file:C:\Users\me\demo.mp4`},
+		{"it's, here.mp4", "it's, here.mp4"}, // Only filter values need these escaped
+		{"-", "-"},
+		{"pipe:1", "pipe:1"},
+		{"file:already.mp4", "file:already.mp4"},
+	}
+	for _, tt := range tests {
+		if got := filePath(tt.path); got != tt.want {
+			t.Errorf("filePath(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
+
+// unescape undoes one level of ffmpeg's backslash escaping
+func unescape(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) {
+			i++
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+func TestEscapeFilterValue(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{"plain.srt", "plain.srt"},
+		{"C:/subs/demo.srt", `C\\:/subs/demo.srt`},
+		{"it's.srt", `it\\\'s.srt`},
+		{"a,b.srt", `a\,b.srt`},
+		{"[x];y.srt", `\[x\]\;y.srt`},
+		{`back\slash.srt`, `back\\\\slash.srt`},
+	}
+	for _, tt := range tests {
+		got := EscapeFilterValue(tt.value)
+		if got != tt.want {
+			t.Errorf("EscapeFilterValue(%q) = %q, want %q", tt.value, got, tt.want)
+		}
+		// ffmpeg unescapes once for the graph and once for the option
+		if back := unescape(unescape(got)); back != tt.value {
+			t.Errorf("EscapeFilterValue(%q) unescapes to %q", tt.value, back)
+		}
+	}
+}
+
+func TestArgsStdin(t *testing.T) {
+	args := New().Input("in.mp4").Output("out.mp4", media.EncodeOptions{}).Args()
+	if !slices.Contains(args, "-nostdin") {
+		t.Errorf("non-interactive args %q lack -nostdin", args)
+	}
+	args = New().Interactive().Input("in.mp4").Output("out.mp4", media.EncodeOptions{}).Args()
+	if slices.Contains(args, "-nostdin") {
+		t.Errorf("interactive args %q include -nostdin", args)
+	}
+}
+
+func TestArgsOrder(t *testing.T) {
+	args := New().
+		Global("-v", "error").
+		Input("-in.mp4", "-ss", "5", "-t", "10").
+		Input("cursor.png", "-loop", "1").
+		Device("lavfi", "anullsrc=r=48000", "-t", "3").
+		Map("0:v:0", "0:a?").
+		Output("out.mp4", media.EncodeOptions{Codec: "libx264", CRF: 18, AudioCopy: true}, "-an").
+		Args()
+	want := []string{
+		"-hide_banner", "-nostdin", "-v", "error",
+		"-ss", "5", "-t", "10", "-i", "file:-in.mp4",
+		"-loop", "1", "-i", "cursor.png",
+		"-f", "lavfi", "-t", "3", "-i", "anullsrc=r=48000",
+		"-map", "0:v:0", "-map", "0:a?",
+		"-an", "-c:v", "libx264", "-crf", "18", "-c:a", "copy",
+		"-y", "out.mp4",
+	}
+	if !reflect.DeepEqual(args, want) {
+		t.Errorf("Args =\n%q\nwant\n%q", args, want)
+	}
+}
+
+// filterArg returns the value following flag in args
+func filterArg(t *testing.T, args []string, flag string) string {
+	t.Helper()
+	i := slices.Index(args, flag)
+	if i < 0 || i+1 >= len(args) {
+		t.Fatalf("args %q have no %s", args, flag)
+	}
+	return args[i+1]
+}
+
+func TestEvenSizeFilter(t *testing.T) {
+	pad := media.EncodeOptions{EvenSize: media.EvenPad}
+	padFilter := pad.EvenSizeFilter()
+
+	t.Run("no filter", func(t *testing.T) {
+		args := New().Input("in.mp4").Output("out.mp4", pad).Args()
+		if got := filterArg(t, args, "-vf"); got != padFilter {
+			t.Errorf("-vf = %q, want %q", got, padFilter)
+		}
+	})
+	t.Run("simple filter", func(t *testing.T) {
+		args := New().Input("in.mp4").Filter("scale=640:-2").Output("out.mp4", pad).Args()
+		if got, want := filterArg(t, args, "-vf"), "scale=640:-2,"+padFilter; got != want {
+			t.Errorf("-vf = %q, want %q", got, want)
+		}
+	})
+	t.Run("complex graph", func(t *testing.T) {
+		args := New().
+			Input("in.mp4").Input("cursor.png").
+			FilterComplex("[0:v][1:v]overlay[out]").
+			Map("[out]", "0:a?").
+			Output("out.mp4", pad).
+			Args()
+		want := "[0:v][1:v]overlay[out_uneven];[out_uneven]" + padFilter + "[out]"
+		if got := filterArg(t, args, "-filter_complex"); got != want {
+			t.Errorf("-filter_complex = %q, want %q", got, want)
+		}
+	})
+	t.Run("complex graph reusing the label name", func(t *testing.T) {
+		// Only the last [out] is the graph's output pad
+		args := New().
+			Input("in.mp4").
+			FilterComplex("[0:v]split[out][b];[b]null[c];[out][c]hstack[out]").
+			Map("[out]").
+			Output("out.mp4", pad).
+			Args()
+		want := "[0:v]split[out][b];[b]null[c];[out][c]hstack[out_uneven];[out_uneven]" + padFilter + "[out]"
+		if got := filterArg(t, args, "-filter_complex"); got != want {
+			t.Errorf("-filter_complex = %q, want %q", got, want)
+		}
+	})
+	t.Run("no even size", func(t *testing.T) {
+		args := New().Input("in.mp4").Output("out.mp4", media.EncodeOptions{}).Args()
+		if slices.Contains(args, "-vf") {
+			t.Errorf("args %q add a filter without EvenSize", args)
+		}
+	})
+}
+
+// evenSize evaluates an EvenSizeFilter's width and height expressions for
+// a w by h frame; only the forms the filters use are understood
+func evenSize(t *testing.T, filter string, w, h int) (int, int) {
+	t.Helper()
+	_, sizes, ok := strings.Cut(filter, "=")
+	if !ok {
+		t.Fatalf("filter %q has no options", filter)
+	}
+	parts := strings.Split(sizes, ":")
+	eval := func(expr string, v int) int {
+		switch expr {
+		case "ceil(iw/2)*2", "ceil(ih/2)*2":
+			return int(math.Ceil(float64(v)/2)) * 2
+		case "trunc(iw/2)*2", "trunc(ih/2)*2":
+			return v / 2 * 2
+		}
+		t.Fatalf("unexpected size expression %q", expr)
+		return 0
+	}
+	return eval(parts[0], w), eval(parts[1], h)
+}
+
+func TestEvenSizeResolutions(t *testing.T) {
+	tests := []struct {
+		mode          string
+		width, height int
+		wantW, wantH  int
+	}{
+		{media.EvenPad, 1920, 1080, 1920, 1080},
+		{media.EvenPad, 1921, 1081, 1922, 1082},
+		{media.EvenPad, 1921, 1080, 1922, 1080},
+		{media.EvenPad, 1, 1, 2, 2},
+		{media.EvenCrop, 1920, 1080, 1920, 1080},
+		{media.EvenCrop, 1921, 1081, 1920, 1080},
+		{media.EvenCrop, 1920, 1081, 1920, 1080},
+		{media.EvenCrop, 3, 3, 2, 2},
+	}
+	for _, tt := range tests {
+		filter := media.EncodeOptions{EvenSize: tt.mode}.EvenSizeFilter()
+		if !strings.HasPrefix(filter, tt.mode+"=") {
+			t.Fatalf("%s filter = %q", tt.mode, filter)
+		}
+		w, h := evenSize(t, filter, tt.width, tt.height)
+		if w != tt.wantW || h != tt.wantH {
+			t.Errorf("%s %dx%d gives %dx%d, want %dx%d", tt.mode, tt.width, tt.height, w, h, tt.wantW, tt.wantH)
+		}
+	}
+	if filter := (media.EncodeOptions{}).EvenSizeFilter(); filter != "" {
+		t.Errorf("no EvenSize gives filter %q", filter)
+	}
+}
+
+func TestLastLines(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		n      int
+		want   string
+	}{
+		{"fewer lines than n", "a\nb\n", 10, "a\nb"},
+		{"exactly n", "a\nb\nc", 3, "a\nb\nc"},
+		{"more lines than n", "a\nb\nc\nd\n", 2, "c\nd"},
+		{"trailing blank lines", "a\nb\n\n\n", 1, "b"},
+		{"leading blank space", "\n  a\nb", 5, "a\nb"},
+		{"empty", "", 3, ""},
+	}
+	for _, tt := range tests {
+		if got := LastLines(tt.output, tt.n); got != tt.want {
+			t.Errorf("%s: LastLines(%q, %d) = %q, want %q", tt.name, tt.output, tt.n, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,32 @@
+package ffmpegcmd
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+
+	"github.com/vedantwpatil/Screen-Capture/internal/atomicfile"
+)
+
+// ffmpegBinary is the program Render runs; tests swap in a fake
+var ffmpegBinary = "ffmpeg"
+
+// Render runs ffmpeg with args, which write partial, and moves the result to
+// output once it succeeds. On failure the partial is removed and the error
+// carries the end of ffmpeg's output. Cancelling ctx kills ffmpeg, removes
+// the partial and returns ctx.Err().
+func Render(ctx context.Context, args []string, partial string, output string) error {
+	cmd := exec.CommandContext(ctx, ffmpegBinary, args...)
+	slog.Debug("Running ffmpeg", "args", cmd.Args)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		os.Remove(partial)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return fmt.Errorf("%w\n%s", err, LastLines(string(out), 10))
+	}
+	return atomicfile.Finalize(partial, output)
+}
@@ -0,0 +1,144 @@
+package ffmpegcmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// fakeEnv makes the test binary act as ffmpeg in the mode it names
+const fakeEnv = "FFMPEGCMD_FAKE"
+
+func TestMain(m *testing.M) {
+	if mode := os.Getenv(fakeEnv); mode != "" {
+		os.Exit(fakeFFmpeg(mode, os.Args[1:]))
+	}
+	os.Exit(m.Run())
+}
+
+// fakeFFmpeg writes its pid to the output file, the last argument, then
+// succeeds, fails, or runs until it is killed
+func fakeFFmpeg(mode string, args []string) int {
+	if len(args) == 0 {
+		return 2
+	}
+	if err := os.WriteFile(args[len(args)-1], []byte(strconv.Itoa(os.Getpid())), 0o644); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	switch mode {
+	case "ok":
+		return 0
+	case "fail":
+		fmt.Fprintln(os.Stderr, "Error while decoding stream #0:0: Invalid data found")
+		return 1
+	default:
+		time.Sleep(time.Minute)
+		return 0
+	}
+}
+
+// useFake runs Render's ffmpeg as this test binary in mode
+func useFake(t *testing.T, mode string) {
+	t.Helper()
+	t.Setenv(fakeEnv, mode)
+	ffmpegBinary = os.Args[0]
+	t.Cleanup(func() { ffmpegBinary = "ffmpeg" })
+}
+
+// renderPaths returns an output path holding an earlier render and its partial
+func renderPaths(t *testing.T) (partial, output string) {
+	t.Helper()
+	output = filepath.Join(t.TempDir(), "demo-edited.mp4")
+	if err := os.WriteFile(output, []byte("previous"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return filepath.Join(filepath.Dir(output), ".demo-edited.partial.mp4"), output
+}
+
+func assertContents(t *testing.T, path string, want string) {
+	t.Helper()
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != want {
+		t.Errorf("%s holds %q, want %q", filepath.Base(path), got, want)
+	}
+}
+
+func TestRender(t *testing.T) {
+	useFake(t, "ok")
+	partial, output := renderPaths(t)
+	if err := Render(context.Background(), []string{"-i", "in.mp4", partial}, partial, output); err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+	if _, err := os.Stat(partial); !os.IsNotExist(err) {
+		t.Errorf("partial left behind: %v", err)
+	}
+	if got, err := os.ReadFile(output); err != nil || string(got) == "previous" {
+		t.Errorf("output not replaced: %q, %v", got, err)
+	}
+}
+
+func TestRenderFailure(t *testing.T) {
+	useFake(t, "fail")
+	partial, output := renderPaths(t)
+	err := Render(context.Background(), []string{"-i", "in.mp4", partial}, partial, output)
+	if err == nil || !strings.Contains(err.Error(), "Invalid data found") {
+		t.Errorf("Render error %v lacks ffmpeg's output", err)
+	}
+	if _, err := os.Stat(partial); !os.IsNotExist(err) {
+		t.Errorf("partial left behind: %v", err)
+	}
+	assertContents(t, output, "previous")
+}
+
+func TestRenderCancel(t *testing.T) {
+	useFake(t, "hang")
+	partial, output := renderPaths(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan error, 1)
+	go func() {
+		done <- Render(ctx, []string{"-i", "in.mp4", partial}, partial, output)
+	}()
+
+	// Wait until the fake is running and has written its pid
+	var pid int
+	for deadline := time.Now().Add(10 * time.Second); pid == 0; {
+		if data, err := os.ReadFile(partial); err == nil {
+			pid, _ = strconv.Atoi(string(data))
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("fake ffmpeg never started")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	cancel()
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("Render = %v, want context.Canceled", err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("Render kept waiting after cancel")
+	}
+
+	// Render has reaped the killed process, so signalling it fails
+	if process, err := os.FindProcess(pid); err == nil && process.Signal(syscall.Signal(0)) == nil {
+		t.Errorf("fake ffmpeg (pid %d) still running", pid)
+	}
+	if _, err := os.Stat(partial); !os.IsNotExist(err) {
+		t.Errorf("partial left behind: %v", err)
+	}
+	assertContents(t, output, "previous")
+}
@@ -0,0 +1,82 @@
+package logging
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+)
+
+// Options controls where log records are written
+type Options struct {
+	// Quiet limits terminal output to warnings and errors
+	Quiet bool
+
+	// LogFile additionally records every message, including debug-level
+	// ffmpeg command lines and exit statuses. Empty disables the file.
+	LogFile string
+}
+
+// New builds the application logger. The returned close function flushes and
+// closes the log file (if any) and is safe to call when no file was opened.
+func New(opts Options) (*slog.Logger, func() error, error) {
+	terminalLevel := slog.LevelInfo
+	if opts.Quiet {
+		terminalLevel = slog.LevelWarn
+	}
+
+	handlers := []slog.Handler{
+		slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: terminalLevel}),
+	}
+	closeFn := func() error { return nil }
+
+	if opts.LogFile != "" {
+		file, err := os.OpenFile(opts.LogFile, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to open log file: %w", err)
+		}
+		handlers = append(handlers, slog.NewTextHandler(file, &slog.HandlerOptions{Level: slog.LevelDebug}))
+		closeFn = file.Close
+	}
+
+	return slog.New(fanoutHandler(handlers)), closeFn, nil
+}
+
+// fanoutHandler sends each record to every handler that accepts its level
+type fanoutHandler []slog.Handler
+
+func (h fanoutHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	for _, handler := range h {
+		if handler.Enabled(ctx, level) {
+			return true
+		}
+	}
+	return false
+}
+
+func (h fanoutHandler) Handle(ctx context.Context, record slog.Record) error {
+	var errs []error
+	for _, handler := range h {
+		if handler.Enabled(ctx, record.Level) {
+			errs = append(errs, handler.Handle(ctx, record.Clone()))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (h fanoutHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	next := make(fanoutHandler, len(h))
+	for i, handler := range h {
+		next[i] = handler.WithAttrs(attrs)
+	}
+	return next
+}
+
+func (h fanoutHandler) WithGroup(name string) slog.Handler {
+	next := make(fanoutHandler, len(h))
+	for i, handler := range h {
+		next[i] = handler.WithGroup(name)
+	}
+	return next
+}
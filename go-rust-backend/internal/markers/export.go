@@ -0,0 +1,284 @@
+package markers
+
+import (
+	"encoding/csv"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/vedantwpatil/Screen-Capture/internal/atomicfile"
+	"github.com/vedantwpatil/Screen-Capture/internal/media"
+)
+
+// Formats lists every supported export format
+var Formats = []string{"edl", "csv", "fcpxml"}
+
+// Timeline describes the video the markers belong to
+type Timeline struct {
+	Name      string
+	VideoPath string
+	FPS       float64
+	Duration  time.Duration
+	Width     int
+	Height    int
+}
+
+// edlStartHour is where DaVinci Resolve timelines begin by default (01:00:00:00);
+// EDL markers are matched against the timeline's record timecode
+const edlStartHour = 1
+
+// Extension returns the file extension for a format, including the dot
+func Extension(format string) string {
+	return "." + format
+}
+
+// ValidFormat reports whether format is one of Formats
+func ValidFormat(format string) bool {
+	for _, f := range Formats {
+		if f == format {
+			return true
+		}
+	}
+	return false
+}
+
+// Write encodes markers for tl in the given format
+func Write(w io.Writer, format string, tl Timeline, markers []Marker) error {
+	if tl.FPS <= 0 {
+		return fmt.Errorf("timeline frame rate must be positive, got %g", tl.FPS)
+	}
+	switch format {
+	case "edl":
+		return writeEDL(w, tl, markers)
+	case "csv":
+		return writeCSV(w, tl, markers)
+	case "fcpxml":
+		return writeFCPXML(w, tl, markers)
+	default:
+		return fmt.Errorf("unknown marker format %q, expected one of %v", format, Formats)
+	}
+}
+
+// writeEDL writes the CMX 3600 marker variant Resolve imports through
+// "Timeline > Import > Timeline Markers from EDL"
+func writeEDL(w io.Writer, tl Timeline, markers []Marker) error {
+	nominal := nominalRate(tl.FPS)
+	// Timecode hours count nominal frames, so the offset can't go through frameAt
+	start := edlStartHour * 3600 * nominal
+	if _, err := fmt.Fprintf(w, "TITLE: %s\nFCM: NON-DROP FRAME\n\n", tl.Name); err != nil {
+		return err
+	}
+	for i, m := range markers {
+		frame := start + frameAt(m.At, tl.FPS)
+		in, out := timecode(frame, nominal), timecode(frame+1, nominal)
+		_, err := fmt.Fprintf(w, "%03d  001      V     C        %s %s %s %s  \n |C:ResolveColorBlue |M:%s |D:1\n\n",
+			i+1, in, out, in, out, m.Name)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeCSV(w io.Writer, tl Timeline, markers []Marker) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"name", "seconds", "timecode", "clicks"})
+	nominal := nominalRate(tl.FPS)
+	for _, m := range markers {
+		cw.Write([]string{
+			m.Name,
+			strconv.FormatFloat(m.At.Seconds(), 'f', 3, 64),
+			timecode(frameAt(m.At, tl.FPS), nominal),
+			strconv.Itoa(m.Clicks),
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// FCPXML 1.9, which Final Cut Pro and Resolve both import as a clip on a
+// timeline with the markers attached
+type fcpxml struct {
+	XMLName xml.Name  `xml:"fcpxml"`
+	Version string    `xml:"version,attr"`
+	Format  fcpFormat `xml:"resources>format"`
+	Asset   fcpAsset  `xml:"resources>asset"`
+	Event   fcpEvent  `xml:"library>event"`
+}
+
+type fcpEvent struct {
+	Name    string     `xml:"name,attr"`
+	Project fcpProject `xml:"project"`
+}
+
+type fcpFormat struct {
+	ID            string `xml:"id,attr"`
+	FrameDuration string `xml:"frameDuration,attr"`
+	Width         int    `xml:"width,attr,omitempty"`
+	Height        int    `xml:"height,attr,omitempty"`
+}
+
+type fcpAsset struct {
+	ID       string `xml:"id,attr"`
+	Name     string `xml:"name,attr"`
+	Src      string `xml:"src,attr"`
+	Start    string `xml:"start,attr"`
+	Duration string `xml:"duration,attr"`
+	HasVideo string `xml:"hasVideo,attr"`
+	Format   string `xml:"format,attr"`
+}
+
+type fcpProject struct {
+	Name     string      `xml:"name,attr"`
+	Sequence fcpSequence `xml:"sequence"`
+}
+
+type fcpSequence struct {
+	Format   string       `xml:"format,attr"`
+	TCStart  string       `xml:"tcStart,attr"`
+	Duration string       `xml:"duration,attr"`
+	Clip     fcpAssetClip `xml:"spine>asset-clip"`
+}
+
+type fcpAssetClip struct {
+	Ref      string      `xml:"ref,attr"`
+	Name     string      `xml:"name,attr"`
+	Offset   string      `xml:"offset,attr"`
+	Start    string      `xml:"start,attr"`
+	Duration string      `xml:"duration,attr"`
+	Markers  []fcpMarker `xml:"marker"`
+}
+
+type fcpMarker struct {
+	Start    string `xml:"start,attr"`
+	Duration string `xml:"duration,attr"`
+	Value    string `xml:"value,attr"`
+}
+
+func writeFCPXML(w io.Writer, tl Timeline, markers []Marker) error {
+	abs, err := filepath.Abs(tl.VideoPath)
+	if err != nil {
+		return err
+	}
+	src := url.URL{Scheme: "file", Path: filepath.ToSlash(abs)}
+
+	rate := newRationalRate(tl.FPS)
+	duration := rate.at(rate.frames(tl.Duration))
+	doc := fcpxml{
+		Version: "1.9",
+		Format:  fcpFormat{ID: "r1", FrameDuration: rate.at(1), Width: tl.Width, Height: tl.Height},
+		Asset:   fcpAsset{ID: "r2", Name: tl.Name, Src: src.String(), Start: "0s", Duration: duration, HasVideo: "1", Format: "r1"},
+		Event: fcpEvent{
+			Name: tl.Name,
+			Project: fcpProject{
+				Name: tl.Name,
+				Sequence: fcpSequence{
+					Format:   "r1",
+					TCStart:  "0s",
+					Duration: duration,
+					Clip:     fcpAssetClip{Ref: "r2", Name: tl.Name, Offset: "0s", Start: "0s", Duration: duration},
+				},
+			},
+		},
+	}
+	clip := &doc.Event.Project.Sequence.Clip
+	for _, m := range markers {
+		clip.Markers = append(clip.Markers, fcpMarker{
+			Start:    rate.at(rate.frames(m.At)),
+			Duration: rate.at(1),
+			Value:    m.Name,
+		})
+	}
+
+	if _, err := io.WriteString(w, xml.Header+"<!DOCTYPE fcpxml>\n"); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(doc); err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, "\n")
+	return err
+}
+
+// frameAt returns the frame showing at t, rounding to the nearest frame
+func frameAt(t time.Duration, fps float64) int64 {
+	return int64(math.Round(t.Seconds() * fps))
+}
+
+// nominalRate is the whole frame count per timecode second, e.g. 30 for 29.97
+func nominalRate(fps float64) int64 {
+	return int64(math.Round(fps))
+}
+
+// timecode formats a frame count as non-drop HH:MM:SS:FF
+func timecode(frame int64, nominal int64) string {
+	ff := frame % nominal
+	seconds := frame / nominal
+	return fmt.Sprintf("%02d:%02d:%02d:%02d", seconds/3600, seconds/60%60, seconds%60, ff)
+}
+
+// rationalRate expresses one frame as num/den seconds, as FCPXML requires.
+// NTSC rates such as 29.97 become 1001/30000.
+type rationalRate struct {
+	num, den int64
+}
+
+func newRationalRate(fps float64) rationalRate {
+	nominal := nominalRate(fps)
+	if ntsc := float64(nominal) * 1000 / 1001; math.Abs(fps-ntsc) < 0.01 {
+		return rationalRate{num: 1001, den: nominal * 1000}
+	}
+	return rationalRate{num: 1, den: nominal}
+}
+
+// frames counts whole frames at this rate, so times stay on frame boundaries
+func (r rationalRate) frames(t time.Duration) int64 {
+	return int64(math.Round(t.Seconds() * float64(r.den) / float64(r.num)))
+}
+
+// at formats a frame count as an FCPXML time, e.g. "90/30s"
+func (r rationalRate) at(frames int64) string {
+	if frames == 0 {
+		return "0s"
+	}
+	return fmt.Sprintf("%d/%ds", frames*r.num, r.den)
+}
+
+// ExportFile writes the markers for a video to path, reading the frame rate
+// and duration from the video itself
+func ExportFile(videoPath string, markers []Marker, format string, path string) error {
+	info, err := media.Probe(videoPath)
+	if err != nil {
+		return err
+	}
+	f, err := atomicfile.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create marker file: %w", err)
+	}
+	defer f.Abort()
+	tl := Timeline{
+		Name:      strings.TrimSuffix(filepath.Base(videoPath), filepath.Ext(videoPath)),
+		VideoPath: videoPath,
+		FPS:       info.FPS,
+		Duration:  info.Duration,
+		Width:     info.Width,
+		Height:    info.Height,
+	}
+	if err := Write(f, format, tl, markers); err != nil {
+		return fmt.Errorf("failed to write %s markers: %w", format, err)
+	}
+	return f.Commit()
+}
+
+// PathFor names the marker file for a video, e.g. demo.edl for demo.mp4
+func PathFor(videoPath string, format string) string {
+	return strings.TrimSuffix(videoPath, filepath.Ext(videoPath)) + Extension(format)
+}
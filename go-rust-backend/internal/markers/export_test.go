@@ -0,0 +1,189 @@
+package markers
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/xml"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFrameAt(t *testing.T) {
+	tests := []struct {
+		at   time.Duration
+		fps  float64
+		want int64
+	}{
+		{0, 30, 0},
+		{500 * time.Millisecond, 30, 15},
+		{8 * time.Millisecond, 60, 0},  // 0.48 frames rounds down
+		{9 * time.Millisecond, 60, 1},  // 0.54 rounds up
+		{16 * time.Millisecond, 60, 1}, // Just short of a frame at 60fps
+		{time.Second, 30000.0 / 1001, 30},
+		{time.Minute, 30000.0 / 1001, 1798}, // 1798.2: NTSC falls behind wall time
+		{time.Hour + 2*time.Minute + 3500*time.Millisecond, 30, 111705},
+	}
+	for _, tt := range tests {
+		if got := frameAt(tt.at, tt.fps); got != tt.want {
+			t.Errorf("frameAt(%v, %g) = %d, want %d", tt.at, tt.fps, got, tt.want)
+		}
+	}
+}
+
+func TestTimecode(t *testing.T) {
+	tests := []struct {
+		frame   int64
+		nominal int64
+		want    string
+	}{
+		{0, 30, "00:00:00:00"},
+		{29, 30, "00:00:00:29"},
+		{30, 30, "00:00:01:00"},
+		{59*60*30 + 59*30 + 29, 30, "00:59:59:29"},
+		{3600 * 30, 30, "01:00:00:00"},
+		{(3600+2*60+3)*60 + 15, 60, "01:02:03:15"},
+		{25 * 3600 * 24, 24, "25:00:00:00"}, // Hours keep counting past a day
+	}
+	for _, tt := range tests {
+		if got := timecode(tt.frame, tt.nominal); got != tt.want {
+			t.Errorf("timecode(%d, %d) = %q, want %q", tt.frame, tt.nominal, got, tt.want)
+		}
+	}
+}
+
+func TestRationalRate(t *testing.T) {
+	tests := []struct {
+		fps       float64
+		wantFrame string // One frame's duration
+		wantSec   string // One second, on a frame boundary
+	}{
+		{30, "1/30s", "30/30s"},
+		{60, "1/60s", "60/60s"},
+		{25, "1/25s", "25/25s"},
+		{30000.0 / 1001, "1001/30000s", "30030/30000s"},
+		{29.97, "1001/30000s", "30030/30000s"}, // As ffprobe rounds it
+		{24000.0 / 1001, "1001/24000s", "24024/24000s"},
+		{60000.0 / 1001, "1001/60000s", "60060/60000s"},
+	}
+	for _, tt := range tests {
+		rate := newRationalRate(tt.fps)
+		if got := rate.at(1); got != tt.wantFrame {
+			t.Errorf("%g fps: one frame is %q, want %q", tt.fps, got, tt.wantFrame)
+		}
+		if got := rate.at(rate.frames(time.Second)); got != tt.wantSec {
+			t.Errorf("%g fps: one second is %q, want %q", tt.fps, got, tt.wantSec)
+		}
+	}
+	if got := newRationalRate(30).at(0); got != "0s" {
+		t.Errorf("zero frames is %q, want 0s", got)
+	}
+}
+
+func TestWriteEDL(t *testing.T) {
+	tests := []struct {
+		name string
+		fps  float64
+		at   time.Duration
+		want string // Record in and out; the timeline starts at 01:00:00:00
+	}{
+		{"start", 30, 0, "01:00:00:00 01:00:00:01"},
+		{"past an hour", 30, time.Hour + 2*time.Minute + 3500*time.Millisecond, "02:02:03:15 02:02:03:16"},
+		{"rounded to the nearest frame", 60, 1016 * time.Millisecond, "01:00:01:01 01:00:01:02"},
+		// 1798 frames at 29.97 read as 59s and 28 frames in non-drop timecode
+		{"NTSC", 30000.0 / 1001, time.Minute, "01:00:59:28 01:00:59:29"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			tl := Timeline{Name: "demo", FPS: tt.fps}
+			if err := Write(&buf, "edl", tl, []Marker{{At: tt.at, Clicks: 1, Name: "Click"}}); err != nil {
+				t.Fatal(err)
+			}
+			want := "001  001      V     C        " + tt.want + " " + tt.want + "  \n |C:ResolveColorBlue |M:Click |D:1\n"
+			if !strings.Contains(buf.String(), want) {
+				t.Errorf("EDL:\n%s\nlacks:\n%s", buf.String(), want)
+			}
+		})
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	markers := []Marker{
+		{At: 1016 * time.Millisecond, Clicks: 1, Name: "Click"},
+		{At: time.Hour + 2*time.Minute + 3*time.Second + 499*time.Millisecond, Clicks: 3, Name: "Clicks, 3"},
+	}
+	var buf bytes.Buffer
+	if err := Write(&buf, "csv", Timeline{Name: "demo", FPS: 60}, markers); err != nil {
+		t.Fatal(err)
+	}
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][]string{
+		{"name", "seconds", "timecode", "clicks"},
+		{"Click", "1.016", "00:00:01:01", "1"},
+		// 3723.499s is frame 223409.94, so rounds up to frame 30 of that second
+		{"Clicks, 3", "3723.499", "01:02:03:30", "3"},
+	}
+	if len(rows) != len(want) {
+		t.Fatalf("got %d rows, want %d:\n%q", len(rows), len(want), rows)
+	}
+	for i := range want {
+		if strings.Join(rows[i], "|") != strings.Join(want[i], "|") {
+			t.Errorf("row %d = %q, want %q", i, rows[i], want[i])
+		}
+	}
+}
+
+func TestWriteFCPXML(t *testing.T) {
+	tl := Timeline{
+		Name:      "demo",
+		VideoPath: filepath.Join(t.TempDir(), "demo.mp4"),
+		FPS:       30000.0 / 1001,
+		Duration:  10 * time.Second,
+		Width:     1920,
+		Height:    1080,
+	}
+	markers := []Marker{
+		{At: 2 * time.Second, Clicks: 1, Name: "Click"},
+		{At: time.Hour, Clicks: 2, Name: "Later"},
+	}
+	var buf bytes.Buffer
+	if err := Write(&buf, "fcpxml", tl, markers); err != nil {
+		t.Fatal(err)
+	}
+	var doc fcpxml
+	if err := xml.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("invalid FCPXML: %v\n%s", err, buf.String())
+	}
+
+	if got := doc.Format.FrameDuration; got != "1001/30000s" {
+		t.Errorf("frame duration %q, want 1001/30000s", got)
+	}
+	// 10s is 299.7 frames, rounded to 300
+	if got := doc.Asset.Duration; got != "300300/30000s" {
+		t.Errorf("duration %q, want 300300/30000s", got)
+	}
+	clip := doc.Event.Project.Sequence.Clip
+	// 59.94 frames rounds to 60; an hour is 107892.1 frames, rounded down
+	want := []string{"60060/30000s", "107999892/30000s"}
+	if len(clip.Markers) != len(want) {
+		t.Fatalf("got %d markers, want %d", len(clip.Markers), len(want))
+	}
+	for i, m := range clip.Markers {
+		if m.Start != want[i] || m.Duration != "1001/30000s" {
+			t.Errorf("marker %d at %s for %s, want %s for one frame", i, m.Start, m.Duration, want[i])
+		}
+	}
+}
+
+func TestWriteRejectsBadRate(t *testing.T) {
+	for _, fps := range []float64{0, -30} {
+		if err := Write(&bytes.Buffer{}, "csv", Timeline{FPS: fps}, nil); err == nil {
+			t.Errorf("Write accepted %g fps", fps)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+// Package markers turns clicks in the cursor history into timeline markers
+// that video editors such as DaVinci Resolve and Premiere can import.
+package markers
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/vedantwpatil/Screen-Capture/internal/tracking"
+)
+
+// DefaultGap is how close clicks must be to share one marker
+const DefaultGap = time.Second
+
+// Marker is one group of clicks on the recording timeline
+type Marker struct {
+	At     time.Duration // First click in the group
+	Clicks int
+	Name   string
+}
+
+// FromClicks groups clicks no more than gap apart and returns one marker per
+// group, in time order. The edit pipeline doesn't retime video, so recording
+// time is also the edited video's time.
+func FromClicks(history []tracking.CursorPosition, gap time.Duration) []Marker {
+	var clicks []time.Duration
+	for _, p := range history {
+		if p.Click {
+			clicks = append(clicks, p.ClickTimeStamp)
+		}
+	}
+	// Positions and clicks are tracked separately, so they can interleave
+	sort.Slice(clicks, func(i, j int) bool { return clicks[i] < clicks[j] })
+
+	var markers []Marker
+	var last time.Duration
+	for _, at := range clicks {
+		if len(markers) > 0 && at-last <= gap {
+			markers[len(markers)-1].Clicks++
+		} else {
+			markers = append(markers, Marker{At: at, Clicks: 1})
+		}
+		last = at
+	}
+	for i := range markers {
+		markers[i].Name = fmt.Sprintf("Click %d", i+1)
+		if markers[i].Clicks > 1 {
+			markers[i].Name = fmt.Sprintf("Click %d (%d clicks)", i+1, markers[i].Clicks)
+		}
+	}
+	return markers
+}
+
+// Index returns the position of the marker starting at at, or -1. Times
+// within a millisecond match, since saved times round-trip through
+// milliseconds.
+func Index(markers []Marker, at time.Duration) int {
+	for i, m := range markers {
+		if (m.At - at).Abs() < time.Millisecond {
+			return i
+		}
+	}
+	return -1
+}
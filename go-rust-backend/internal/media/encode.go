@@ -0,0 +1,99 @@
+package media
+
+import "strconv"
+
+// EncodeOptions selects the encoder settings for an ffmpeg output
+type EncodeOptions struct {
+	Codec  string // e.g. libx264
+	CRF    int    // Constant rate factor; zero leaves the encoder default
+	Preset string // Encoder speed preset; empty leaves the encoder default
+	PixFmt string // Output pixel format; empty keeps the source format
+
+	// AudioCopy passes any audio through untouched instead of re-encoding it
+	AudioCopy bool
+
+	// EvenSize rounds an odd width or height to even, which libx264 and
+	// yuv420p require: EvenPad adds a black line, EvenCrop drops one. Empty
+	// leaves the frame size alone.
+	EvenSize string
+}
+
+// Ways to make a frame size even for EncodeOptions.EvenSize
+const (
+	EvenPad  = "pad"
+	EvenCrop = "crop"
+)
+
+// EvenSizeFilter returns the video filter that applies EvenSize, or "" for
+// none. Both filters leave frames that are already even untouched.
+func (o EncodeOptions) EvenSizeFilter() string {
+	switch o.EvenSize {
+	case EvenPad:
+		return "pad=ceil(iw/2)*2:ceil(ih/2)*2"
+	case EvenCrop:
+		return "crop=trunc(iw/2)*2:trunc(ih/2)*2"
+	default:
+		return ""
+	}
+}
+
+// RecordingEncodeOptions is used for live capture, where keeping up with the
+// screen matters more than file size
+func RecordingEncodeOptions() EncodeOptions {
+	return EncodeOptions{
+		Codec:    "libx264",
+		Preset:   "ultrafast",
+		PixFmt:   "yuv420p",
+		EvenSize: EvenPad, // A window or region capture can be any size
+	}
+}
+
+// RecordingMuxArgs write the capture as fragmented MP4. Each keyframe starts
+// a self-contained fragment, so a capture killed before it writes the file
+// trailer still plays up to its last keyframe.
+func RecordingMuxArgs() []string {
+	return []string{"-movflags", "+frag_keyframe+empty_moov"}
+}
+
+// Profiles for intermediate renders, which a later pass decodes and encodes
+// again. Only the last pass uses the export settings, so quality is lost once.
+const (
+	IntermediateNearLossless = "near-lossless" // libx264 at CRF 12; visually lossless and quick
+	IntermediateLossless     = "lossless"      // FFV1 in Matroska; exact, but several times larger
+)
+
+// IntermediateEncodeOptions returns the settings for an intermediate profile
+// and the file extension its container needs
+func IntermediateEncodeOptions(profile string) (EncodeOptions, string) {
+	if profile == IntermediateLossless {
+		return EncodeOptions{Codec: "ffv1", PixFmt: "yuv420p", AudioCopy: true}, ".mkv"
+	}
+	return EncodeOptions{
+		Codec:     "libx264",
+		CRF:       12,
+		Preset:    "veryfast",
+		PixFmt:    "yuv420p",
+		AudioCopy: true,
+	}, ".mp4"
+}
+
+// Args translates the options into ffmpeg output arguments
+func (o EncodeOptions) Args() []string {
+	var args []string
+	if o.Codec != "" {
+		args = append(args, "-c:v", o.Codec)
+	}
+	if o.PixFmt != "" {
+		args = append(args, "-pix_fmt", o.PixFmt)
+	}
+	if o.Preset != "" {
+		args = append(args, "-preset", o.Preset)
+	}
+	if o.CRF > 0 {
+		args = append(args, "-crf", strconv.Itoa(o.CRF))
+	}
+	if o.AudioCopy {
+		args = append(args, "-c:a", "copy")
+	}
+	return args
+}
@@ -0,0 +1,150 @@
+package media
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Info describes the streams of a media file as reported by ffprobe
+type Info struct {
+	Width        int
+	Height       int
+	FPS          float64 // Average frame rate of the first video stream
+	Duration     time.Duration
+	VideoCodec   string
+	AudioCodec   string
+	VideoStreams int
+	AudioStreams int
+}
+
+// Resolution formats the frame size as WIDTHxHEIGHT
+func (i Info) Resolution() string {
+	return fmt.Sprintf("%dx%d", i.Width, i.Height)
+}
+
+// ffprobeOutput mirrors the parts of `ffprobe -print_format json` we use
+type ffprobeOutput struct {
+	Streams []struct {
+		CodecType    string `json:"codec_type"`
+		CodecName    string `json:"codec_name"`
+		AvgFrameRate string `json:"avg_frame_rate"`
+		Width        int    `json:"width"`
+		Height       int    `json:"height"`
+		Duration     string `json:"duration"`
+	} `json:"streams"`
+	Format struct {
+		Duration string `json:"duration"`
+	} `json:"format"`
+}
+
+// Probe runs ffprobe on path and returns its stream information
+func Probe(path string) (Info, error) {
+	cmd := exec.Command("ffprobe",
+		"-v", "error",
+		"-print_format", "json",
+		"-show_format",
+		"-show_streams",
+		path)
+	slog.Debug("Running ffprobe", "args", cmd.Args)
+
+	output, err := cmd.Output()
+	if err != nil {
+		return Info{}, fmt.Errorf("ffprobe failed for %s: %w", path, err)
+	}
+
+	return parseProbeOutput(output)
+}
+
+// ProbeVideo probes path like Probe and also refuses files with no video
+// stream or no duration, such as the header-only file a capture that failed
+// to start leaves behind
+func ProbeVideo(path string) (Info, error) {
+	info, err := Probe(path)
+	switch {
+	case err != nil:
+		return info, fmt.Errorf("%s is empty or corrupt: %w", path, err)
+	case info.VideoStreams == 0:
+		return info, fmt.Errorf("%s has no video stream", path)
+	case info.Duration <= 0:
+		return info, fmt.Errorf("%s has no frames", path)
+	}
+	return info, nil
+}
+
+// parseProbeOutput converts raw ffprobe JSON into Info
+func parseProbeOutput(data []byte) (Info, error) {
+	var probe ffprobeOutput
+	if err := json.Unmarshal(data, &probe); err != nil {
+		return Info{}, fmt.Errorf("failed to parse ffprobe output: %w", err)
+	}
+
+	var info Info
+	for _, stream := range probe.Streams {
+		switch stream.CodecType {
+		case "video":
+			// The first video stream is the one every consumer cares about
+			if info.VideoStreams == 0 {
+				fps, err := parseFrameRate(stream.AvgFrameRate)
+				if err != nil {
+					return Info{}, err
+				}
+				info.Width = stream.Width
+				info.Height = stream.Height
+				info.FPS = fps
+				info.VideoCodec = stream.CodecName
+			}
+			info.VideoStreams++
+		case "audio":
+			if info.AudioStreams == 0 {
+				info.AudioCodec = stream.CodecName
+			}
+			info.AudioStreams++
+		}
+	}
+
+	// The container duration covers all streams; fall back to the first stream's
+	duration := probe.Format.Duration
+	if duration == "" && len(probe.Streams) > 0 {
+		duration = probe.Streams[0].Duration
+	}
+	if duration != "" {
+		seconds, err := strconv.ParseFloat(duration, 64)
+		if err != nil {
+			return Info{}, fmt.Errorf("invalid duration %q: %w", duration, err)
+		}
+		info.Duration = time.Duration(seconds * float64(time.Second))
+	}
+
+	return info, nil
+}
+
+// parseFrameRate parses ffprobe rates such as "60/1" or "2997/100".
+// ffprobe reports "0/0" when the rate is unknown, which maps to zero.
+func parseFrameRate(rate string) (float64, error) {
+	if rate == "" {
+		return 0, nil
+	}
+
+	numerator, denominator, isFraction := strings.Cut(rate, "/")
+	num, err := strconv.ParseFloat(numerator, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid frame rate %q: %w", rate, err)
+	}
+	if !isFraction {
+		return num, nil
+	}
+
+	den, err := strconv.ParseFloat(denominator, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid frame rate %q: %w", rate, err)
+	}
+	if den == 0 {
+		return 0, nil
+	}
+	return num / den, nil
+}
@@ -0,0 +1,110 @@
+package media
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseProbeOutput(t *testing.T) {
+	tests := []struct {
+		fixture string
+		want    Info
+	}{
+		{"screen-recording.json", Info{
+			Width: 2880, Height: 1800, FPS: 60, Duration: 12200 * time.Millisecond,
+			VideoCodec: "h264", VideoStreams: 1,
+		}},
+		{"with-audio.json", Info{
+			Width: 1920, Height: 1080, FPS: 30000.0 / 1001, Duration: 60074667 * time.Microsecond,
+			VideoCodec: "h264", AudioCodec: "aac", VideoStreams: 1, AudioStreams: 1,
+		}},
+		// Matroska keeps stream durations in tags; the container's is used
+		{"ffv1-intermediate.json", Info{
+			Width: 1921, Height: 1081, FPS: 60, Duration: 5017 * time.Millisecond,
+			VideoCodec: "ffv1", AudioCodec: "pcm_s16le", VideoStreams: 1, AudioStreams: 1,
+		}},
+		// What a capture that never got a frame leaves behind
+		{"header-only.json", Info{
+			Width: 2880, Height: 1800, FPS: 0, Duration: 0,
+			VideoCodec: "h264", VideoStreams: 1,
+		}},
+		{"audio-only.json", Info{
+			Duration: 3 * time.Second, AudioCodec: "aac", AudioStreams: 1,
+		}},
+		// Raw streams have no container duration; the stream's is used
+		{"raw-stream.json", Info{
+			Width: 1280, Height: 720, FPS: 25, Duration: 8 * time.Second,
+			VideoCodec: "h264", VideoStreams: 1,
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			data, err := os.ReadFile(filepath.Join("testdata", tt.fixture))
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := parseProbeOutput(data)
+			if err != nil {
+				t.Fatalf("parseProbeOutput: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got  %+v\nwant %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseProbeOutputRejects(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{"not JSON", `Invalid data found when processing input`, "failed to parse ffprobe output"},
+		{"truncated", `{"streams": [{"codec_type": "video"`, "failed to parse ffprobe output"},
+		{"bad duration", `{"streams": [], "format": {"duration": "N/A"}}`, `invalid duration "N/A"`},
+		{"bad frame rate", `{"streams": [{"codec_type": "video", "avg_frame_rate": "sixty"}]}`, `invalid frame rate "sixty"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseProbeOutput([]byte(tt.data))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("err = %v, want it to contain %q", err, tt.want)
+			}
+		})
+	}
+
+	// Nothing to report is not an error
+	info, err := parseProbeOutput([]byte(`{}`))
+	if err != nil || info != (Info{}) {
+		t.Errorf("empty output = %+v, %v; want a zero Info", info, err)
+	}
+}
+
+func TestParseFrameRate(t *testing.T) {
+	tests := []struct {
+		rate string
+		want float64
+	}{
+		{"60/1", 60},
+		{"2997/100", 29.97},
+		{"30000/1001", 30000.0 / 1001},
+		{"0/0", 0},
+		{"", 0},
+		{"25", 25},
+	}
+	for _, tt := range tests {
+		got, err := parseFrameRate(tt.rate)
+		if err != nil || got != tt.want {
+			t.Errorf("parseFrameRate(%q) = %g, %v; want %g", tt.rate, got, err, tt.want)
+		}
+	}
+	for _, rate := range []string{"x/1", "60/y"} {
+		if _, err := parseFrameRate(rate); err == nil {
+			t.Errorf("parseFrameRate(%q) succeeded", rate)
+		}
+	}
+}
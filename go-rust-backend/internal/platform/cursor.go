@@ -0,0 +1,9 @@
+package platform
+
+import "github.com/vedantwpatil/Screen-Capture/internal/cursorshape"
+
+// CursorShape reports the system cursor's current shape. Shapes that aren't
+// tracked, and platforms that can't tell, report the arrow.
+func CursorShape() cursorshape.Shape {
+	return currentCursor()
+}
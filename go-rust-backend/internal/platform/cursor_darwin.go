@@ -0,0 +1,47 @@
+package platform
+
+/*
+#cgo CFLAGS: -x objective-c -Wno-deprecated-declarations
+#cgo LDFLAGS: -framework AppKit
+#import <AppKit/AppKit.h>
+
+static NSData *ibeamImage, *verticalIBeamImage, *handImage;
+
+// currentCursorShape compares the system cursor's image with AppKit's
+// standard cursors, since the cursor object itself is a new copy on each
+// call: 1 is the I-beam, 2 the pointing hand, 0 anything else
+static int currentCursorShape(void) {
+	static dispatch_once_t once;
+	dispatch_once(&once, ^{
+		ibeamImage = [[[[NSCursor IBeamCursor] image] TIFFRepresentation] retain];
+		verticalIBeamImage = [[[[NSCursor IBeamCursorForVerticalLayout] image] TIFFRepresentation] retain];
+		handImage = [[[[NSCursor pointingHandCursor] image] TIFFRepresentation] retain];
+	});
+	int shape = 0;
+	@autoreleasepool {
+		NSCursor *cursor = [NSCursor currentSystemCursor];
+		if (cursor != nil) {
+			NSData *image = [[cursor image] TIFFRepresentation];
+			if ([image isEqualToData:ibeamImage] || [image isEqualToData:verticalIBeamImage]) {
+				shape = 1;
+			} else if ([image isEqualToData:handImage]) {
+				shape = 2;
+			}
+		}
+	}
+	return shape;
+}
+*/
+import "C"
+
+import "github.com/vedantwpatil/Screen-Capture/internal/cursorshape"
+
+func currentCursor() cursorshape.Shape {
+	switch C.currentCursorShape() {
+	case 1:
+		return cursorshape.IBeam
+	case 2:
+		return cursorshape.Hand
+	}
+	return cursorshape.Arrow
+}
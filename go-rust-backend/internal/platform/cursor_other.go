@@ -0,0 +1,10 @@
+//go:build !windows && !(darwin && cgo)
+
+package platform
+
+import "github.com/vedantwpatil/Screen-Capture/internal/cursorshape"
+
+// currentCursor can't see the cursor's shape here, so it is always the arrow
+func currentCursor() cursorshape.Shape {
+	return cursorshape.Arrow
+}
@@ -0,0 +1,49 @@
+package platform
+
+import (
+	"unsafe"
+
+	"github.com/vedantwpatil/Screen-Capture/internal/cursorshape"
+	"golang.org/x/sys/windows"
+)
+
+var (
+	user32            = windows.NewLazySystemDLL("user32.dll")
+	procGetCursorInfo = user32.NewProc("GetCursorInfo")
+	procLoadCursor    = user32.NewProc("LoadCursorW")
+)
+
+// Standard cursor IDs for LoadCursorW
+const (
+	idcIBeam = 32513
+	idcHand  = 32649
+)
+
+// cursorInfo is the Win32 CURSORINFO structure
+type cursorInfo struct {
+	size     uint32
+	flags    uint32
+	cursor   uintptr
+	position struct{ x, y int32 }
+}
+
+// currentCursor compares the cursor handle with the shared system cursors,
+// which every application gets the same handle for
+func currentCursor() cursorshape.Shape {
+	info := cursorInfo{size: uint32(unsafe.Sizeof(cursorInfo{}))}
+	if ok, _, _ := procGetCursorInfo.Call(uintptr(unsafe.Pointer(&info))); ok == 0 || info.cursor == 0 {
+		return cursorshape.Arrow
+	}
+	switch info.cursor {
+	case systemCursor(idcIBeam):
+		return cursorshape.IBeam
+	case systemCursor(idcHand):
+		return cursorshape.Hand
+	}
+	return cursorshape.Arrow
+}
+
+func systemCursor(id uintptr) uintptr {
+	handle, _, _ := procLoadCursor.Call(0, id)
+	return handle
+}
@@ -0,0 +1,18 @@
+//go:build !windows
+
+package platform
+
+import (
+	"fmt"
+	"syscall"
+)
+
+// FreeSpace reports the bytes available to this user on the filesystem
+// holding path
+func FreeSpace(path string) (uint64, error) {
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(path, &stat); err != nil {
+		return 0, fmt.Errorf("failed to read free space for %s: %w", path, err)
+	}
+	return uint64(stat.Bavail) * uint64(stat.Bsize), nil
+}
@@ -0,0 +1,21 @@
+package platform
+
+import (
+	"fmt"
+
+	"golang.org/x/sys/windows"
+)
+
+// FreeSpace reports the bytes available to this user on the volume holding
+// path
+func FreeSpace(path string) (uint64, error) {
+	dir, err := windows.UTF16PtrFromString(path)
+	if err != nil {
+		return 0, err
+	}
+	var available uint64
+	if err := windows.GetDiskFreeSpaceEx(dir, &available, nil, nil); err != nil {
+		return 0, fmt.Errorf("failed to read free space for %s: %w", path, err)
+	}
+	return available, nil
+}
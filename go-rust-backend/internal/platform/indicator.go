@@ -0,0 +1,86 @@
+package platform
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+
+	"golang.org/x/term"
+)
+
+// Indicator shows that a recording is running and for how long, until it
+// is closed
+type Indicator interface {
+	Close() error
+}
+
+// ShowIndicator shows the recording started at startedAt: a small
+// always-on-top overlay where the OS has one we can keep out of screen
+// captures, otherwise the elapsed time in the terminal's title. With
+// neither available it shows nothing.
+func ShowIndicator(startedAt time.Time) Indicator {
+	indicator, err := showOverlay(startedAt)
+	if err == nil {
+		return indicator
+	}
+	slog.Debug("No recording overlay, using the terminal title", "err", err)
+	if !term.IsTerminal(int(os.Stdout.Fd())) {
+		return noIndicator{}
+	}
+	return newTitleIndicator(os.Stdout, startedAt)
+}
+
+type noIndicator struct{}
+
+func (noIndicator) Close() error { return nil }
+
+// titleIndicator puts "● REC 01:23" in the terminal title, which tmux shows
+// as the pane title. The previous title is saved on the terminal's title
+// stack and restored on Close; terminals without one keep the last text.
+type titleIndicator struct {
+	w    io.Writer
+	stop chan struct{}
+	done chan struct{}
+	once sync.Once
+}
+
+func newTitleIndicator(w io.Writer, startedAt time.Time) *titleIndicator {
+	t := &titleIndicator{w: w, stop: make(chan struct{}), done: make(chan struct{})}
+	fmt.Fprint(w, "\x1b[22;2t") // Save the title
+	go t.run(startedAt)
+	return t
+}
+
+func (t *titleIndicator) run(startedAt time.Time) {
+	defer close(t.done)
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	for {
+		fmt.Fprintf(t.w, "\x1b]2;● REC %s\x07", formatElapsed(time.Since(startedAt)))
+		select {
+		case <-t.stop:
+			fmt.Fprint(t.w, "\x1b[23;2t") // Restore it
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (t *titleIndicator) Close() error {
+	t.once.Do(func() { close(t.stop) })
+	<-t.done
+	return nil
+}
+
+// formatElapsed formats a recording's running time as 01:23, or 1:02:03
+// past an hour
+func formatElapsed(d time.Duration) string {
+	s := int(d / time.Second)
+	if s >= 3600 {
+		return fmt.Sprintf("%d:%02d:%02d", s/3600, s/60%60, s%60)
+	}
+	return fmt.Sprintf("%02d:%02d", s/60, s%60)
+}
@@ -0,0 +1,54 @@
+package platform
+
+import (
+	_ "embed"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"strconv"
+	"time"
+)
+
+//go:embed indicator_darwin.js
+var overlayScript []byte
+
+// overlay is a borderless always-on-top window drawn by an osascript helper.
+// The window asks macOS to leave it out of screen captures; versions that
+// ignore that will record it.
+type overlay struct {
+	cmd    *exec.Cmd
+	script string
+}
+
+func showOverlay(startedAt time.Time) (Indicator, error) {
+	if _, err := exec.LookPath("osascript"); err != nil {
+		return nil, err
+	}
+	f, err := os.CreateTemp("", "focusframe-indicator-*.js")
+	if err != nil {
+		return nil, fmt.Errorf("failed to write overlay script: %w", err)
+	}
+	if _, err := f.Write(overlayScript); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return nil, fmt.Errorf("failed to write overlay script: %w", err)
+	}
+	f.Close()
+
+	cmd := exec.Command("osascript", "-l", "JavaScript", f.Name(),
+		strconv.FormatInt(startedAt.UnixMilli(), 10), strconv.Itoa(os.Getpid()))
+	slog.Debug("Showing recording overlay", "args", cmd.Args)
+	if err := cmd.Start(); err != nil {
+		os.Remove(f.Name())
+		return nil, fmt.Errorf("failed to show recording overlay: %w", err)
+	}
+	return &overlay{cmd: cmd, script: f.Name()}, nil
+}
+
+func (o *overlay) Close() error {
+	defer os.Remove(o.script)
+	o.cmd.Process.Kill()
+	o.cmd.Wait() // Killed, so the error is expected
+	return nil
+}
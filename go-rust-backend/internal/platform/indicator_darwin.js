@@ -0,0 +1,56 @@
+// Recording overlay: a red dot and the elapsed time in a small borderless
+// window at the top right of the main screen. Run by indicator_darwin.go as
+//   osascript -l JavaScript indicator_darwin.js <start unix ms> <parent pid>
+ObjC.import('Cocoa')
+
+function run(argv) {
+  const startedAt = Number(argv[0])
+  const parent = argv[1]
+
+  const app = $.NSApplication.sharedApplication
+  app.setActivationPolicy($.NSApplicationActivationPolicyAccessory) // No Dock icon
+
+  const width = 110, height = 28, margin = 12
+  const screen = $.NSScreen.mainScreen.visibleFrame
+  const frame = $.NSMakeRect(
+    screen.origin.x + screen.size.width - width - margin,
+    screen.origin.y + screen.size.height - height - margin,
+    width, height)
+  const win = $.NSWindow.alloc.initWithContentRectStyleMaskBackingDefer(
+    frame, $.NSWindowStyleMaskBorderless, $.NSBackingStoreBuffered, false)
+  win.setLevel($.NSStatusWindowLevel)
+  win.setOpaque(false)
+  win.setBackgroundColor($.NSColor.colorWithCalibratedWhiteAlpha(0, 0.6))
+  win.setIgnoresMouseEvents(true)
+  win.setSharingType($.NSWindowSharingNone) // Keep it out of screen captures
+  win.setCollectionBehavior($.NSWindowCollectionBehaviorCanJoinAllSpaces | $.NSWindowCollectionBehaviorStationary)
+
+  const label = $.NSTextField.labelWithString('')
+  label.setFrame($.NSMakeRect(0, 5, width, height - 10))
+  label.setAlignment($.NSTextAlignmentCenter)
+  label.setTextColor($.NSColor.whiteColor)
+  label.setFont($.NSFont.monospacedDigitSystemFontOfSizeWeight(13, $.NSFontWeightMedium))
+  win.contentView.addSubview(label)
+  win.orderFrontRegardless
+
+  const sys = Application.currentApplication()
+  sys.includeStandardAdditions = true
+  for (let tick = 0; ; tick++) {
+    const s = Math.floor((Date.now() - startedAt) / 1000)
+    const pad = n => String(n).padStart(2, '0')
+    const elapsed = s >= 3600
+      ? `${Math.floor(s / 3600)}:${pad(Math.floor(s / 60) % 60)}:${pad(s % 60)}`
+      : `${pad(Math.floor(s / 60))}:${pad(s % 60)}`
+    label.setStringValue(`🔴 REC ${elapsed}`)
+    $.NSRunLoop.currentRunLoop.runUntilDate($.NSDate.dateWithTimeIntervalSinceNow(0.5))
+
+    // Go away if the recorder died without closing us
+    if (tick % 4 === 0) {
+      try {
+        sys.doShellScript(`kill -0 ${parent}`)
+      } catch (e) {
+        return
+      }
+    }
+  }
+}
@@ -0,0 +1,14 @@
+//go:build !darwin
+
+package platform
+
+import (
+	"errors"
+	"time"
+)
+
+// showOverlay has no overlay to offer: recording is macOS-only for now, so
+// the terminal title stands in elsewhere
+func showOverlay(time.Time) (Indicator, error) {
+	return nil, errors.New("no recording overlay on this platform")
+}
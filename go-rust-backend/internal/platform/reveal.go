@@ -0,0 +1,52 @@
+// Package platform wraps the desktop integrations that differ per OS
+package platform
+
+import (
+	"fmt"
+	"log/slog"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/go-vgo/robotgo/clipboard"
+)
+
+// Reveal shows path in the system file manager, selecting it where the OS
+// supports that. It does nothing when no file manager helper is installed
+// (e.g. a headless Linux box).
+func Reveal(path string) error {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return fmt.Errorf("failed to resolve %s: %w", path, err)
+	}
+
+	name, args := revealCommand(abs)
+	if _, err := exec.LookPath(name); err != nil {
+		slog.Debug("No file manager helper available, not revealing output", "helper", name)
+		return nil
+	}
+
+	cmd := exec.Command(name, args...)
+	slog.Debug("Revealing output", "args", cmd.Args)
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to reveal %s: %w", abs, err)
+	}
+	// File managers may keep running; only reap the helper
+	go cmd.Wait()
+	return nil
+}
+
+// CopyPath puts the absolute form of path on the clipboard
+func CopyPath(path string) error {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return fmt.Errorf("failed to resolve %s: %w", path, err)
+	}
+	if clipboard.Unsupported {
+		slog.Debug("Clipboard not available, not copying path")
+		return nil
+	}
+	if err := clipboard.WriteAll(abs); err != nil {
+		return fmt.Errorf("failed to copy path to clipboard: %w", err)
+	}
+	return nil
+}
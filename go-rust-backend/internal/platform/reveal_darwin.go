@@ -0,0 +1,6 @@
+package platform
+
+// revealCommand selects the file in Finder
+func revealCommand(path string) (string, []string) {
+	return "open", []string{"-R", path}
+}
@@ -0,0 +1,10 @@
+//go:build !darwin && !windows
+
+package platform
+
+import "path/filepath"
+
+// revealCommand opens the containing directory; xdg-open cannot select a file
+func revealCommand(path string) (string, []string) {
+	return "xdg-open", []string{filepath.Dir(path)}
+}
@@ -0,0 +1,7 @@
+package platform
+
+// revealCommand selects the file in Explorer. Explorer exits non-zero even on
+// success, which is fine since Reveal never waits on it.
+func revealCommand(path string) (string, []string) {
+	return "explorer", []string{"/select," + path}
+}
@@ -0,0 +1,170 @@
+package progress
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/term"
+)
+
+// linesInterval is how often Lines prints while a stage runs
+const linesInterval = 5 * time.Second
+
+// minBarCells is the narrowest bar worth drawing; below it only text is shown
+const minBarCells = 10
+
+// NewTerminal picks how to show progress on f: a bar redrawn in place and
+// sized to the terminal, or plain lines every few seconds when f is a file
+// or pipe (redirects, CI logs), where \r redraws would pile up
+func NewTerminal(f *os.File) Reporter {
+	fd := int(f.Fd())
+	if !term.IsTerminal(fd) {
+		return NewLines(f, linesInterval)
+	}
+	bar := NewBar(f)
+	bar.width = func() int {
+		width, _, err := term.GetSize(fd)
+		if err != nil {
+			return 0
+		}
+		return width
+	}
+	return bar
+}
+
+// Bar redraws a single progress line in place with \r
+type Bar struct {
+	w     io.Writer
+	width func() int // Terminal columns, re-read on every draw so resizes apply; nil or 0 when unknown
+
+	mu      sync.Mutex
+	stage   string
+	started time.Time
+	eta     estimator
+	drawn   int // Length of the line currently on screen; 0 when none
+}
+
+func NewBar(w io.Writer) *Bar {
+	return &Bar{w: w}
+}
+
+func (b *Bar) Stage(name string, _ int64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.endLine()
+	b.stage = name
+	b.started = time.Now()
+	b.eta.reset()
+	b.draw(label(name) + "...")
+}
+
+func (b *Bar) Progress(fraction float64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	now := time.Now()
+	elapsed := now.Sub(b.started).Round(time.Second)
+	remaining := "estimating"
+	if left, ok := b.eta.add(now, fraction); ok {
+		remaining = "~" + left.Round(time.Second).String() + " left"
+	}
+	prefix := label(b.stage) + " "
+	suffix := fmt.Sprintf(" %5.1f%% (%s elapsed, %s)", fraction*100, elapsed, remaining)
+
+	cells := b.columns() - len(prefix) - len(suffix) - 3 // Brackets and the spare last column
+	if cells < minBarCells {
+		b.draw(label(b.stage) + ":" + suffix)
+		return
+	}
+	filled := int(fraction * float64(cells))
+	filled = max(0, min(filled, cells))
+	b.draw(prefix + "[" + strings.Repeat("#", filled) + strings.Repeat("-", cells-filled) + "]" + suffix)
+}
+
+// Finish ends the line; a failed edit's bar is replaced so no half-drawn
+// bar is left above the error message
+func (b *Bar) Finish(_ string, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if err != nil && b.drawn > 0 {
+		b.draw(label(b.stage) + ": failed")
+	}
+	b.endLine()
+}
+
+func (b *Bar) columns() int {
+	if b.width == nil {
+		return 0
+	}
+	return b.width()
+}
+
+// draw replaces the current line, padding with spaces to erase a longer
+// previous one and trimming to the terminal so it never wraps
+func (b *Bar) draw(text string) {
+	if columns := b.columns(); columns > 0 && len(text) > columns-1 {
+		text = text[:columns-1]
+	}
+	padding := ""
+	if b.drawn > len(text) {
+		padding = strings.Repeat(" ", b.drawn-len(text))
+	}
+	fmt.Fprint(b.w, "\r"+text+padding)
+	b.drawn = len(text)
+}
+
+// endLine moves past a drawn line so the next output starts fresh
+func (b *Bar) endLine() {
+	if b.drawn > 0 {
+		fmt.Fprintln(b.w)
+		b.drawn = 0
+	}
+}
+
+// Lines prints progress as ordinary lines at most once per interval, for
+// output that isn't a terminal
+type Lines struct {
+	w        io.Writer
+	interval time.Duration
+
+	mu      sync.Mutex
+	stage   string
+	started time.Time
+	printed time.Time
+	eta     estimator
+}
+
+func NewLines(w io.Writer, interval time.Duration) *Lines {
+	return &Lines{w: w, interval: interval}
+}
+
+func (l *Lines) Stage(name string, _ int64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.stage = name
+	l.started = time.Now()
+	l.printed = l.started
+	l.eta.reset()
+	fmt.Fprintf(l.w, "%s...\n", label(name))
+}
+
+func (l *Lines) Progress(fraction float64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := time.Now()
+	left, ok := l.eta.add(now, fraction)
+	if now.Sub(l.printed) < l.interval {
+		return
+	}
+	l.printed = now
+	line := fmt.Sprintf("%s: %.0f%% (%s elapsed", label(l.stage), fraction*100, now.Sub(l.started).Round(time.Second))
+	if ok {
+		line += fmt.Sprintf(", ~%s left", left.Round(time.Second))
+	}
+	fmt.Fprintln(l.w, line+")")
+}
+
+func (l *Lines) Finish(string, error) {}
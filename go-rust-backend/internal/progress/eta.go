@@ -0,0 +1,84 @@
+package progress
+
+import "time"
+
+// etaWindow is how much recent history the rate is measured over. Long
+// enough to ride out ffmpeg's speed wobbling, short enough to notice a real
+// slowdown within a few seconds.
+const etaWindow = 10 * time.Second
+
+// etaSmoothing is the weight of a fresh estimate against the previous one
+// counted down to now
+const etaSmoothing = 0.3
+
+// Sample is one progress reading
+type Sample struct {
+	At       time.Time
+	Fraction float64
+}
+
+// Remaining estimates the time left from samples in time order, using the
+// average rate between the first and last so one slow or fast update barely
+// moves it. ok is false until progress has actually advanced.
+func Remaining(samples []Sample) (time.Duration, bool) {
+	if len(samples) < 2 {
+		return 0, false
+	}
+	first, last := samples[0], samples[len(samples)-1]
+	elapsed := last.At.Sub(first.At).Seconds()
+	advanced := last.Fraction - first.Fraction
+	if elapsed <= 0 || advanced <= 0 {
+		return 0, false
+	}
+	left := 1 - last.Fraction
+	if left < 0 {
+		left = 0
+	}
+	return time.Duration(left / (advanced / elapsed) * float64(time.Second)), true
+}
+
+// estimator keeps the sliding window for one stage and damps the estimate
+type estimator struct {
+	samples []Sample
+	eta     time.Duration
+	etaAt   time.Time
+	have    bool
+}
+
+func (e *estimator) reset() {
+	*e = estimator{}
+}
+
+// add records a reading and returns the smoothed time left
+func (e *estimator) add(now time.Time, fraction float64) (time.Duration, bool) {
+	// Going backwards means a new pass started; old rates say nothing about it
+	if n := len(e.samples); n > 0 && fraction < e.samples[n-1].Fraction {
+		e.reset()
+	}
+	e.samples = append(e.samples, Sample{At: now, Fraction: fraction})
+	cut := 0
+	for cut < len(e.samples)-2 && now.Sub(e.samples[cut].At) > etaWindow {
+		cut++
+	}
+	e.samples = e.samples[cut:]
+
+	// The previous estimate, counted down to now
+	projected := e.eta - now.Sub(e.etaAt)
+	if projected < 0 {
+		projected = 0
+	}
+
+	fresh, ok := Remaining(e.samples)
+	switch {
+	case !ok && e.have && projected > 0:
+		// Stalled for the whole window; keep counting down the last estimate
+		return projected, true
+	case !ok:
+		e.have = false
+		return 0, false
+	case e.have:
+		fresh = time.Duration(etaSmoothing*float64(fresh) + (1-etaSmoothing)*float64(projected))
+	}
+	e.eta, e.etaAt, e.have = fresh, now, true
+	return fresh, true
+}
@@ -0,0 +1,96 @@
+package progress
+
+import (
+	"testing"
+	"time"
+)
+
+var start = time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+// trace turns (seconds, fraction) pairs into samples
+func trace(points ...float64) []Sample {
+	samples := make([]Sample, 0, len(points)/2)
+	for i := 0; i+1 < len(points); i += 2 {
+		samples = append(samples, Sample{
+			At:       start.Add(time.Duration(points[i] * float64(time.Second))),
+			Fraction: points[i+1],
+		})
+	}
+	return samples
+}
+
+func TestRemaining(t *testing.T) {
+	tests := []struct {
+		name    string
+		samples []Sample
+		want    time.Duration
+		ok      bool
+	}{
+		{"no samples", nil, 0, false},
+		{"one sample", trace(0, 0.5), 0, false},
+		{"steady", trace(0, 0, 1, 0.1, 2, 0.2), 8 * time.Second, true},
+		{"started part way", trace(10, 0.5, 15, 0.75), 5 * time.Second, true},
+		// Only the first and last samples set the rate
+		{"wobble in between", trace(0, 0, 1, 0.19, 2, 0.2), 8 * time.Second, true},
+		{"stalled", trace(0, 0.4, 5, 0.4), 0, false},
+		{"went backwards", trace(0, 0.6, 1, 0.2), 0, false},
+		{"no time passed", trace(3, 0.1, 3, 0.2), 0, false},
+		{"finished", trace(0, 0, 4, 1), 0, true},
+		{"overshot", trace(0, 0, 4, 1.05), 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := Remaining(tt.samples)
+			if ok != tt.ok || got != tt.want {
+				t.Errorf("Remaining() = %v, %v; want %v, %v", got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestEstimator(t *testing.T) {
+	var e estimator
+	if _, ok := e.add(start, 0); ok {
+		t.Fatal("estimate from a single reading")
+	}
+	eta, ok := e.add(start.Add(time.Second), 0.1)
+	if !ok || eta != 9*time.Second {
+		t.Fatalf("first estimate = %v, %v; want 9s", eta, ok)
+	}
+
+	// Once the window holds only a stall, the last estimate keeps counting
+	// down instead of being dropped
+	e.reset()
+	e.add(start, 0)
+	e.add(start.Add(time.Second), 0.01) // 99s left
+	eta, ok = e.add(start.Add(12*time.Second), 0.01)
+	if !ok || eta != 88*time.Second {
+		t.Errorf("after a stall = %v, %v; want 88s, true", eta, ok)
+	}
+	// Until it runs out
+	if eta, ok = e.add(start.Add(200*time.Second), 0.01); ok {
+		t.Errorf("after an endless stall = %v, want no estimate", eta)
+	}
+
+	// A sudden speed-up only moves the estimate part of the way. The first
+	// reading has left the window by then.
+	e.reset()
+	e.add(start, 0)
+	e.add(start.Add(10*time.Second), 0.1) // 90s left
+	eta, _ = e.add(start.Add(11*time.Second), 0.5)
+	fresh, _ := Remaining(trace(10, 0.1, 11, 0.5))
+	projected := 89 * time.Second
+	want := time.Duration(etaSmoothing*float64(fresh) + (1-etaSmoothing)*float64(projected))
+	if eta != want {
+		t.Errorf("after a speed-up = %v, want %v", eta, want)
+	}
+
+	// Progress going backwards is a new pass, estimated from scratch
+	if _, ok := e.add(start.Add(12*time.Second), 0.05); ok {
+		t.Error("estimate carried over into a new pass")
+	}
+	eta, ok = e.add(start.Add(13*time.Second), 0.15)
+	if !ok || eta != 8500*time.Millisecond {
+		t.Errorf("new pass estimate = %v, %v; want 8.5s", eta, ok)
+	}
+}
@@ -0,0 +1,84 @@
+package progress
+
+import (
+	"encoding/json"
+	"io"
+	"math"
+	"sync"
+	"time"
+)
+
+// JSON writes one JSON object per line, for GUI wrappers and scripts:
+//
+//	{"event":"stage","stage":"render","frames":3600}
+//	{"event":"progress","stage":"render","fraction":0.25,"fps":112.4,"eta_secs":96}
+//	{"event":"done","output":"output/demo-edited.mp4","elapsed_secs":41.2}
+//
+// A failed edit ends with {"event":"error","error":"..."} instead of done.
+type JSON struct {
+	mu      sync.Mutex
+	enc     *json.Encoder
+	began   time.Time
+	stage   string
+	frames  int64
+	started time.Time
+	eta     estimator
+	now     func() time.Time // time.Now outside tests
+}
+
+func NewJSON(w io.Writer) *JSON {
+	return &JSON{enc: json.NewEncoder(w), began: time.Now(), now: time.Now}
+}
+
+type stageEvent struct {
+	Event  string `json:"event"`
+	Stage  string `json:"stage"`
+	Frames int64  `json:"frames,omitempty"`
+}
+
+type progressEvent struct {
+	Event    string  `json:"event"`
+	Stage    string  `json:"stage"`
+	Fraction float64 `json:"fraction"`
+	FPS      float64 `json:"fps,omitempty"`      // Average for the stage so far; omitted when frames are unknown
+	ETASecs  float64 `json:"eta_secs,omitempty"` // Omitted until progress has moved
+}
+
+type finishEvent struct {
+	Event       string  `json:"event"`
+	Output      string  `json:"output,omitempty"`
+	Error       string  `json:"error,omitempty"`
+	ElapsedSecs float64 `json:"elapsed_secs"`
+}
+
+func (j *JSON) Stage(name string, frames int64) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.stage, j.frames, j.started = name, frames, j.now()
+	j.eta.reset()
+	j.enc.Encode(stageEvent{Event: "stage", Stage: name, Frames: frames})
+}
+
+func (j *JSON) Progress(fraction float64) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	now := j.now()
+	event := progressEvent{Event: "progress", Stage: j.stage, Fraction: fraction}
+	if elapsed := now.Sub(j.started).Seconds(); j.frames > 0 && elapsed > 0 {
+		event.FPS = math.Round(fraction*float64(j.frames)/elapsed*10) / 10
+	}
+	if left, ok := j.eta.add(now, fraction); ok {
+		event.ETASecs = math.Round(left.Seconds())
+	}
+	j.enc.Encode(event)
+}
+
+func (j *JSON) Finish(output string, err error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	event := finishEvent{Event: "done", Output: output, ElapsedSecs: j.now().Sub(j.began).Seconds()}
+	if err != nil {
+		event = finishEvent{Event: "error", Error: err.Error(), ElapsedSecs: event.ElapsedSecs}
+	}
+	j.enc.Encode(event)
+}
@@ -0,0 +1,73 @@
+package progress
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+)
+
+// stepClock returns a clock that first reads start and moves on by the next
+// of steps, in seconds, after each reading
+func stepClock(steps ...float64) func() time.Time {
+	at := start
+	return func() time.Time {
+		now := at
+		if len(steps) > 0 {
+			at = at.Add(time.Duration(steps[0] * float64(time.Second)))
+			steps = steps[1:]
+		}
+		return now
+	}
+}
+
+func TestJSON(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{
+			name: "done",
+			want: `{"event":"stage","stage":"render","frames":3600}
+{"event":"progress","stage":"render","fraction":0}
+{"event":"progress","stage":"render","fraction":0.25,"fps":90,"eta_secs":30}
+{"event":"stage","stage":"encode"}
+{"event":"progress","stage":"encode","fraction":0.5}
+{"event":"done","output":"output/demo-edited.mp4","elapsed_secs":41.5}
+`,
+		},
+		{
+			name: "error",
+			err:  errors.New("ffmpeg failed"),
+			want: `{"event":"stage","stage":"render","frames":3600}
+{"event":"progress","stage":"render","fraction":0}
+{"event":"progress","stage":"render","fraction":0.25,"fps":90,"eta_secs":30}
+{"event":"stage","stage":"encode"}
+{"event":"progress","stage":"encode","fraction":0.5}
+{"event":"error","error":"ffmpeg failed","elapsed_secs":41.5}
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			j := NewJSON(&buf)
+			// Readings: render starts, 0% at once, 25% 10s in, encode
+			// starts 1.5s later, 50% 20s in, then the finish 10s after
+			j.now = stepClock(0, 10, 1.5, 20, 10)
+			j.began = start
+
+			j.Stage("render", 3600)
+			j.Progress(0)
+			j.Progress(0.25)
+			j.Stage("encode", 0) // Frames unknown: no fps
+			j.Progress(0.5)      // A single reading: no ETA yet
+			j.Finish("output/demo-edited.mp4", tt.err)
+
+			if got := buf.String(); got != tt.want {
+				t.Errorf("events:\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,78 @@
+// Package progress reports how far an edit has got, either as a terminal
+// progress line for people or as JSON lines for programs wrapping the CLI.
+package progress
+
+// Stages of an edit, in the order they run. Only the ones enabled by the
+// config are reported.
+const (
+	StagePreview   = "preview"   // Scaling the recording down for a preview
+	StageRender    = "render"    // Cursor effects; the long one
+	StageCamera    = "camera"    // Follow and click zoom
+	StageTimecode  = "timecode"  // Timecode burn-in
+	StageSubtitles = "subtitles" // Subtitle burn-in
+	StageClicks    = "clicks"    // Click sounds mixed into the audio
+)
+
+// Reporter receives progress for one edit. Calls come from the goroutine
+// running the edit, but implementations must tolerate being used from others.
+type Reporter interface {
+	// Stage starts a step; frames is how many video frames it processes, or
+	// 0 when unknown
+	Stage(name string, frames int64)
+
+	// Progress reports the current stage's completion from 0 to 1
+	Progress(fraction float64)
+
+	// Finish is called once with the edit's result
+	Finish(output string, err error)
+}
+
+// Discard ignores every report
+type Discard struct{}
+
+func (Discard) Stage(string, int64)  {}
+func (Discard) Progress(float64)     {}
+func (Discard) Finish(string, error) {}
+
+// Func adapts a plain completion callback. Only the render stage is
+// forwarded, so the value never jumps back to 0 when a later pass starts.
+func Func(report func(percent float32)) Reporter {
+	return &funcReporter{report: report}
+}
+
+type funcReporter struct {
+	report func(percent float32)
+	stage  string
+}
+
+func (f *funcReporter) Stage(name string, _ int64) {
+	f.stage = name
+}
+
+func (f *funcReporter) Progress(fraction float64) {
+	if f.stage == StageRender {
+		f.report(float32(fraction))
+	}
+}
+
+func (f *funcReporter) Finish(string, error) {}
+
+// label is the human-readable name of a stage
+func label(stage string) string {
+	switch stage {
+	case StagePreview:
+		return "Scaling down for preview"
+	case StageRender:
+		return "Processing video"
+	case StageCamera:
+		return "Moving the camera"
+	case StageTimecode:
+		return "Burning in timecode"
+	case StageSubtitles:
+		return "Burning in subtitles"
+	case StageClicks:
+		return "Adding click sounds"
+	default:
+		return stage
+	}
+}
@@ -0,0 +1,290 @@
+// Package project bundles a recording, its cursor data and the settings it
+// was made with into a <name>.focusframe directory, so it can be re-edited
+// with different effect settings long after the recording session.
+package project
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/vedantwpatil/Screen-Capture/internal/atomicfile"
+	"github.com/vedantwpatil/Screen-Capture/internal/config"
+)
+
+// Extension marks a project bundle directory
+const Extension = ".focusframe"
+
+// manifestVersion is bumped whenever the bundle layout changes incompatibly
+const manifestVersion = 1
+
+// Files inside a bundle. Only these names are stored, never absolute paths,
+// so a bundle can be moved or copied to another machine.
+const (
+	manifestFile = "project.json"
+	videoFile    = "recording.mp4"
+	cursorFile   = "cursor.json"
+	settingsFile = "settings.yaml"
+)
+
+// ErrStale means the bundled video no longer matches the hash taken when the
+// bundle was saved, e.g. it was replaced or re-encoded in place
+var ErrStale = errors.New("project video has changed since the project was saved")
+
+type manifest struct {
+	Version    int       `json:"version"`
+	Name       string    `json:"name"`
+	RecordedAt time.Time `json:"recorded_at"`
+	SavedAt    time.Time `json:"saved_at"`
+	Video      string    `json:"video"`
+	VideoHash  string    `json:"video_sha256"`
+	VideoSize  int64     `json:"video_size"`
+	Cursor     string    `json:"cursor"`
+	Settings   string    `json:"settings"`
+	Warnings   []string  `json:"warnings,omitempty"` // Raised while recording, e.g. dropped frames
+
+	// Start times of the click groups the user chose not to zoom on, in
+	// milliseconds since the recording started
+	SkippedClicks []float64 `json:"skipped_clicks_ms,omitempty"`
+}
+
+// Project is a loaded bundle with its paths resolved
+type Project struct {
+	Dir        string
+	Name       string
+	RecordedAt time.Time
+	VideoPath  string
+	CursorPath string
+	Warnings   []string // Raised while recording
+
+	// SkippedClicks are the start times of click groups the camera doesn't
+	// zoom on, kept so a re-render makes the same choices
+	SkippedClicks []time.Duration
+
+	// Settings are the ones in effect when the recording was made; edits
+	// normally use the current settings instead
+	Settings *config.Config
+}
+
+// IsBundle reports whether path names a project bundle
+func IsBundle(path string) bool {
+	return strings.HasSuffix(strings.TrimRight(path, `/\`), Extension)
+}
+
+// PathFor returns the bundle path for a recording, e.g. demo.focusframe for demo.mp4
+func PathFor(videoPath string) string {
+	return strings.TrimSuffix(videoPath, filepath.Ext(videoPath)) + Extension
+}
+
+// Save bundles a finished recording next to it and returns the bundle path.
+// The video is hard-linked when possible so bundling costs no disk space,
+// and copied otherwise. An existing bundle for the same recording is replaced.
+// warnings from the recording watchdog are kept in the manifest.
+func Save(cfg *config.Config, videoPath string, cursorPath string, recordedAt time.Time, warnings []string) (string, error) {
+	dir := PathFor(videoPath)
+	if err := os.RemoveAll(dir); err != nil {
+		return "", fmt.Errorf("failed to replace old project %s: %w", dir, err)
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", fmt.Errorf("failed to create project %s: %w", dir, err)
+	}
+
+	problem := func(err error) (string, error) {
+		os.RemoveAll(dir)
+		return "", err
+	}
+	if err := linkOrCopy(videoPath, filepath.Join(dir, videoFile)); err != nil {
+		return problem(fmt.Errorf("failed to add video to project: %w", err))
+	}
+	if err := linkOrCopy(cursorPath, filepath.Join(dir, cursorFile)); err != nil {
+		return problem(fmt.Errorf("failed to add cursor data to project: %w", err))
+	}
+	if err := cfg.Save(filepath.Join(dir, settingsFile)); err != nil {
+		return problem(fmt.Errorf("failed to add settings to project: %w", err))
+	}
+
+	hash, size, err := hashFile(filepath.Join(dir, videoFile))
+	if err != nil {
+		return problem(err)
+	}
+	m := manifest{
+		Version:    manifestVersion,
+		Name:       strings.TrimSuffix(filepath.Base(videoPath), filepath.Ext(videoPath)),
+		RecordedAt: recordedAt,
+		SavedAt:    time.Now(),
+		Video:      videoFile,
+		VideoHash:  hash,
+		VideoSize:  size,
+		Cursor:     cursorFile,
+		Settings:   settingsFile,
+		Warnings:   warnings,
+	}
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return problem(fmt.Errorf("failed to encode project manifest: %w", err))
+	}
+	// The manifest goes last, so a bundle without one was never finished
+	if err := atomicfile.WriteFile(filepath.Join(dir, manifestFile), data, 0o644); err != nil {
+		return problem(fmt.Errorf("failed to write project manifest: %w", err))
+	}
+	return dir, nil
+}
+
+// Load opens a bundle and checks the video against the saved hash, returning
+// an error wrapping ErrStale if it changed
+func Load(dir string) (*Project, error) {
+	m, err := readManifest(dir)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range []string{m.Video, m.Cursor, m.Settings} {
+		if name == "" || filepath.Base(name) != name {
+			return nil, fmt.Errorf("invalid project manifest %s: bad file name %q", dir, name)
+		}
+	}
+
+	p := &Project{
+		Dir:        dir,
+		Name:       m.Name,
+		RecordedAt: m.RecordedAt,
+		VideoPath:  filepath.Join(dir, m.Video),
+		CursorPath: filepath.Join(dir, m.Cursor),
+		Warnings:   m.Warnings,
+
+		SkippedClicks: fromMillis(m.SkippedClicks),
+	}
+
+	hash, size, err := hashFile(p.VideoPath)
+	if err != nil {
+		return nil, err
+	}
+	if size != m.VideoSize || hash != m.VideoHash {
+		return nil, fmt.Errorf("%s: %w", dir, ErrStale)
+	}
+
+	settings, err := config.Load(filepath.Join(dir, m.Settings))
+	if err != nil {
+		return nil, fmt.Errorf("project %s: %w", dir, err)
+	}
+	p.Settings = settings
+	return p, nil
+}
+
+// Find returns the bundle a recording belongs to: the one it sits in, or the
+// one saved next to it. It returns "" when there is neither.
+func Find(videoPath string) string {
+	for _, dir := range []string{filepath.Dir(videoPath), PathFor(videoPath)} {
+		if !IsBundle(dir) {
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(dir, manifestFile)); err == nil {
+			return dir
+		}
+	}
+	return ""
+}
+
+// SkippedClicks reads the click groups skipped in a bundle without loading
+// and verifying the whole project
+func SkippedClicks(dir string) ([]time.Duration, error) {
+	m, err := readManifest(dir)
+	if err != nil {
+		return nil, err
+	}
+	return fromMillis(m.SkippedClicks), nil
+}
+
+// SetSkippedClicks records the click groups to skip in a bundle's manifest
+func SetSkippedClicks(dir string, skipped []time.Duration) error {
+	m, err := readManifest(dir)
+	if err != nil {
+		return err
+	}
+	m.SkippedClicks = nil
+	for _, at := range skipped {
+		m.SkippedClicks = append(m.SkippedClicks, float64(at)/float64(time.Millisecond))
+	}
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode project manifest: %w", err)
+	}
+	if err := atomicfile.WriteFile(filepath.Join(dir, manifestFile), data, 0o644); err != nil {
+		return fmt.Errorf("failed to write project manifest: %w", err)
+	}
+	return nil
+}
+
+func readManifest(dir string) (manifest, error) {
+	data, err := os.ReadFile(filepath.Join(dir, manifestFile))
+	if errors.Is(err, fs.ErrNotExist) {
+		return manifest{}, fmt.Errorf("%s is not a project (no %s)", dir, manifestFile)
+	}
+	if err != nil {
+		return manifest{}, fmt.Errorf("failed to read project: %w", err)
+	}
+
+	var m manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return manifest{}, fmt.Errorf("invalid project manifest %s: %w", dir, err)
+	}
+	if m.Version != manifestVersion {
+		return manifest{}, fmt.Errorf("project %s has version %d; this build reads version %d", dir, m.Version, manifestVersion)
+	}
+	return m, nil
+}
+
+func fromMillis(ms []float64) []time.Duration {
+	var durations []time.Duration
+	for _, v := range ms {
+		durations = append(durations, time.Duration(v*float64(time.Millisecond)))
+	}
+	return durations
+}
+
+// EditedPath names the edit of a project next to its bundle, e.g.
+// demo-edited.mp4 for demo.focusframe
+func (p *Project) EditedPath() string {
+	return strings.TrimSuffix(filepath.Clean(p.Dir), Extension) + "-edited.mp4"
+}
+
+func hashFile(path string) (string, int64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to read project video: %w", err)
+	}
+	defer f.Close()
+	h := sha256.New()
+	size, err := io.Copy(h, f)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to hash project video: %w", err)
+	}
+	return hex.EncodeToString(h.Sum(nil)), size, nil
+}
+
+func linkOrCopy(src, dst string) error {
+	if err := os.Link(src, dst); err == nil {
+		return nil
+	}
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
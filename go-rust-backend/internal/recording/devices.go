@@ -0,0 +1,102 @@
+package recording
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os/exec"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/vedantwpatil/Screen-Capture/internal/ffmpegcmd"
+)
+
+// Device is a capture input as reported by ffmpeg
+type Device struct {
+	Index int    `json:"index"`
+	Name  string `json:"name"`
+	Kind  string `json:"kind"` // video or audio
+}
+
+// listDevicesTimeout stops a hung ffmpeg from blocking startup or diagnostics
+const listDevicesTimeout = 10 * time.Second
+
+// avfoundationDevice matches lines such as "[AVFoundation indev @ 0x7f8] [1] Capture screen 0"
+var avfoundationDevice = regexp.MustCompile(`\]\s+\[(\d+)\]\s+(.+)$`)
+
+// ListDevices returns the capture devices ffmpeg can record from
+func ListDevices(logger *slog.Logger) ([]Device, error) {
+	if runtime.GOOS != "darwin" {
+		return nil, fmt.Errorf("device listing is not supported on %s", runtime.GOOS)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), listDevicesTimeout)
+	defer cancel()
+	args := ffmpegcmd.New().Device("avfoundation", "", "-list_devices", "true").Args()
+	cmd := exec.CommandContext(ctx, "ffmpeg", args...)
+	logger.Debug("Running ffmpeg", "args", cmd.Args)
+
+	outputBytes, err := cmd.CombinedOutput()
+	if err != nil {
+		if len(outputBytes) == 0 {
+			return nil, fmt.Errorf("failed to run ffmpeg list_devices command: %v, output: %s", err, outputBytes)
+		}
+
+		// ffmpeg always exits non-zero here because "" is not a real input
+		logger.Debug("Ffmpeg list_devices exited non-zero, but produced output. Proceeding with parsing.", "err", err)
+	}
+
+	return parseDeviceList(string(outputBytes)), nil
+}
+
+func parseDeviceList(output string) []Device {
+	var devices []Device
+	kind := ""
+	for _, line := range strings.Split(output, "\n") {
+		switch {
+		case strings.Contains(line, "AVFoundation video devices:"):
+			kind = "video"
+			continue
+		case strings.Contains(line, "AVFoundation audio devices:"):
+			kind = "audio"
+			continue
+		}
+		if kind == "" {
+			continue
+		}
+
+		match := avfoundationDevice.FindStringSubmatch(strings.TrimSpace(line))
+		if match == nil {
+			continue
+		}
+		index, err := strconv.Atoi(match[1])
+		if err != nil {
+			continue
+		}
+		devices = append(devices, Device{Index: index, Name: match[2], Kind: kind})
+	}
+	return devices
+}
+
+func findScreenDeviceIndex(logger *slog.Logger) (string, error) {
+	devices, err := ListDevices(logger)
+	if err != nil {
+		return "", err
+	}
+
+	// Get main desktop device index
+	// TODO: Add audio support
+	// Currently not capturing the audio
+	for _, device := range devices {
+		if device.Kind == "video" && strings.Contains(device.Name, "Capture screen 0") {
+			logger.Debug("Located main device screen", "index", device.Index)
+			return strconv.Itoa(device.Index), nil
+		}
+	}
+
+	return "", errors.New("could not find 'Capture screen 0' in ffmpeg device list")
+}
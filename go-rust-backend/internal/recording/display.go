@@ -0,0 +1,44 @@
+package recording
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/go-vgo/robotgo"
+)
+
+// displayCheckPeriod is how often the screen size is compared with the one
+// the capture started on
+const displayCheckPeriod = time.Second
+
+// watchDisplay warns and calls stop once the main display's size differs from
+// what it was when the capture started, e.g. after a resolution switch or a
+// different monitor taking over. ffmpeg keeps writing the old frame size, so
+// the rest would be scaled or garbled and the cursor data would no longer
+// line up; stopping keeps everything before the change usable. It returns
+// when done is closed.
+func (r *Recorder) watchDisplay(done <-chan struct{}, stop func()) {
+	width, height := robotgo.GetScreenSize()
+	if width <= 0 || height <= 0 {
+		r.logger.Debug("Screen size unavailable; display changes will not be detected")
+		return
+	}
+	ticker := time.NewTicker(displayCheckPeriod)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+		}
+		w, h := robotgo.GetScreenSize()
+		if w <= 0 || h <= 0 || (w == width && h == height) {
+			continue
+		}
+		elapsed := time.Since(r.startTime)
+		r.warn(fmt.Sprintf("display changed from %dx%d to %dx%d at %s; the recording was stopped there, start a new one to continue",
+			width, height, w, h, elapsed.Round(time.Second)), Stats{Elapsed: elapsed})
+		stop()
+		return
+	}
+}
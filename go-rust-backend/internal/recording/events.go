@@ -0,0 +1,167 @@
+package recording
+
+import (
+	"bufio"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// subscriberBuffer is how many events a slow subscriber can fall behind by
+// before the oldest are dropped
+const subscriberBuffer = 32
+
+// EventKind says what happened to a recording
+type EventKind string
+
+const (
+	EventStarted  EventKind = "started"  // Capture launched; OutputPath is set
+	EventProgress EventKind = "progress" // Periodic Stats while capturing
+	EventWarning  EventKind = "warning"  // Message describes a problem that hasn't stopped the capture yet
+	EventStopped  EventKind = "stopped"  // Capture finished and the file is complete; Stats is the summary
+	EventError    EventKind = "error"    // Capture failed; Err says why
+)
+
+// Event is one update from a Recorder. Only the fields noted for its kind
+// are set.
+type Event struct {
+	Kind       EventKind
+	At         time.Time
+	OutputPath string
+	Stats      Stats
+	Message    string
+	Err        error
+	Warnings   []string // Stopped and Error: every warning raised during the capture
+}
+
+// Stats describe a capture so far, as reported by ffmpeg
+type Stats struct {
+	Elapsed       time.Duration
+	Frames        int64
+	FPS           float64
+	Speed         float64 // Encoding speed against real time; below 1 means falling behind
+	Size          int64   // Bytes written
+	DroppedFrames int64
+}
+
+// subscribers fans events out without ever blocking the publisher
+type subscribers struct {
+	mu   sync.Mutex
+	subs map[chan Event]struct{}
+}
+
+func (s *subscribers) add() chan Event {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.subs == nil {
+		s.subs = make(map[chan Event]struct{})
+	}
+	ch := make(chan Event, subscriberBuffer)
+	s.subs[ch] = struct{}{}
+	return ch
+}
+
+func (s *subscribers) remove(ch chan Event) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.subs[ch]; ok {
+		delete(s.subs, ch)
+		close(ch)
+	}
+}
+
+// publish hands event to every subscriber. A full buffer loses its oldest
+// event, which is almost always a stale Progress, so the newest state and
+// the final Stopped or Error always get through.
+func (s *subscribers) publish(event Event) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for ch := range s.subs {
+		select {
+		case ch <- event:
+			continue
+		default:
+		}
+		select {
+		case <-ch:
+		default:
+		}
+		// Only publish sends, under mu, so there is room now
+		ch <- event
+	}
+}
+
+// Subscribe returns a stream of this recorder's events and a function that
+// ends the subscription and closes the channel. Subscriptions outlive a
+// single recording, so a menu can subscribe once and see every Start.
+// Events are never waited for: a subscriber that falls behind loses its
+// oldest undelivered ones.
+func (r *Recorder) Subscribe() (<-chan Event, func()) {
+	ch := r.events.add()
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() { r.events.remove(ch) })
+	}
+}
+
+func (r *Recorder) publish(event Event) {
+	event.At = time.Now()
+	r.events.publish(event)
+}
+
+// watchProgress turns ffmpeg's -progress output into Progress events and
+// passes each update through the watchdog. It returns the last stats once
+// ffmpeg closes the stream.
+func (r *Recorder) watchProgress(out io.Reader) Stats {
+	var stats Stats
+	dog := newWatchdog(r.config.Recording.Watchdog, r.outputPath)
+
+	scanner := bufio.NewScanner(out)
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), "=")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		switch key {
+		case "frame":
+			stats.Frames, _ = strconv.ParseInt(value, 10, 64)
+		case "fps":
+			stats.FPS, _ = strconv.ParseFloat(value, 64)
+		case "total_size":
+			stats.Size, _ = strconv.ParseInt(value, 10, 64)
+		case "drop_frames":
+			stats.DroppedFrames, _ = strconv.ParseInt(value, 10, 64)
+		case "speed":
+			stats.Speed, _ = strconv.ParseFloat(strings.TrimSuffix(value, "x"), 64)
+		case "progress":
+			// Ends each block of keys
+			now := time.Now()
+			stats.Elapsed = now.Sub(r.startTime)
+			r.publish(Event{Kind: EventProgress, OutputPath: r.outputPath, Stats: stats})
+			for _, warning := range dog.check(now, stats) {
+				r.warn(warning, stats)
+			}
+		}
+	}
+	stats.Elapsed = time.Since(r.startTime)
+	return stats
+}
+
+// warn records a warning for the summary and publishes it
+func (r *Recorder) warn(message string, stats Stats) {
+	r.logger.Warn("Recording: " + message)
+	r.mu.Lock()
+	r.warnings = append(r.warnings, message)
+	r.mu.Unlock()
+	r.publish(Event{Kind: EventWarning, OutputPath: r.outputPath, Stats: stats, Message: message})
+}
+
+// Warnings returns every warning raised during the current or last capture
+func (r *Recorder) Warnings() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.warnings...)
+}
@@ -0,0 +1,243 @@
+package recording
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/vedantwpatil/Screen-Capture/internal/media"
+	"github.com/vedantwpatil/Screen-Capture/internal/project"
+	"github.com/vedantwpatil/Screen-Capture/internal/tracking"
+	"github.com/vedantwpatil/Screen-Capture/internal/video"
+)
+
+// editedSuffix and previewSuffix mark outputs of the edit pipeline, which
+// are not recordings themselves
+const (
+	editedSuffix  = "-edited.mp4"
+	previewSuffix = "-preview.mp4"
+)
+
+// Entry is one recording in the output directory with its related files
+type Entry struct {
+	Name        string // Base name, e.g. demo for demo.mp4
+	Path        string
+	Size        int64 // Video file only
+	TotalSize   int64 // Video, cursor sidecar, project bundle and edited version
+	Recorded    time.Time
+	Duration    time.Duration
+	Width       int
+	Height      int
+	CursorPath  string // Set when the sidecar exists
+	EditedPath  string // Set when an edit exists
+	ProjectPath string // Set when a project bundle exists
+	ProbeError  string
+}
+
+// Library lists and deletes recordings inside one output directory
+type Library struct {
+	dir string
+}
+
+func NewLibrary(dir string) (*Library, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, fmt.Errorf("invalid output directory %s: %w", dir, err)
+	}
+	return &Library{dir: abs}, nil
+}
+
+// List returns every recording, newest first. Unreadable videos are still
+// listed, with ProbeError explaining why duration and resolution are missing.
+func (l *Library) List() ([]Entry, error) {
+	dirEntries, err := os.ReadDir(l.dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read output directory: %w", err)
+	}
+
+	var entries []Entry
+	for _, de := range dirEntries {
+		name := de.Name()
+		// Dot files are partial renders and intermediates, never recordings
+		if !de.Type().IsRegular() || filepath.Ext(name) != ".mp4" || strings.HasSuffix(name, editedSuffix) || strings.HasSuffix(name, previewSuffix) || strings.HasPrefix(name, ".") {
+			continue
+		}
+		info, err := de.Info()
+		if err != nil {
+			continue
+		}
+
+		path := filepath.Join(l.dir, name)
+		entry := Entry{
+			Name:      strings.TrimSuffix(name, ".mp4"),
+			Path:      path,
+			Size:      info.Size(),
+			TotalSize: info.Size(),
+			Recorded:  info.ModTime(),
+		}
+		if size, ok := fileSize(tracking.HistoryPath(path)); ok {
+			entry.CursorPath = tracking.HistoryPath(path)
+			entry.TotalSize += size
+		}
+		if size, ok := bundleSize(project.PathFor(path), path); ok {
+			entry.ProjectPath = project.PathFor(path)
+			entry.TotalSize += size
+		}
+		edited := strings.TrimSuffix(path, ".mp4") + editedSuffix
+		if size, ok := fileSize(edited); ok {
+			entry.EditedPath = edited
+			entry.TotalSize += size
+		}
+
+		if probe, err := media.ProbeVideo(path); err != nil {
+			entry.ProbeError = err.Error()
+		} else {
+			entry.Duration = probe.Duration
+			entry.Width = probe.Width
+			entry.Height = probe.Height
+		}
+		entries = append(entries, entry)
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Recorded.After(entries[j].Recorded)
+	})
+	return entries, nil
+}
+
+// Delete removes a recording together with its cursor sidecar, camera plan,
+// preview and project bundle. The bundle hard-links the video, so the space
+// is only freed once both are gone. The edited version is kept, since it is
+// usually the file worth keeping.
+func (l *Library) Delete(entry Entry) error {
+	preview := strings.TrimSuffix(entry.Path, ".mp4") + previewSuffix
+	var problems []error
+	for _, path := range []string{entry.Path, entry.CursorPath, video.CameraPlanPath(entry.Path), preview} {
+		if path == "" {
+			continue
+		}
+		if err := l.remove(path); err != nil {
+			problems = append(problems, err)
+		}
+	}
+	if err := l.removeBundle(project.PathFor(entry.Path)); err != nil {
+		problems = append(problems, err)
+	}
+	return errors.Join(problems...)
+}
+
+// Prune deletes every recording older than maxAge and returns what was removed
+func (l *Library) Prune(maxAge time.Duration) ([]Entry, error) {
+	entries, err := l.List()
+	if err != nil {
+		return nil, err
+	}
+
+	cutoff := time.Now().Add(-maxAge)
+	var removed []Entry
+	var problems []error
+	for _, entry := range entries {
+		if !entry.Recorded.Before(cutoff) {
+			continue
+		}
+		if err := l.Delete(entry); err != nil {
+			problems = append(problems, err)
+			continue
+		}
+		removed = append(removed, entry)
+	}
+	return removed, errors.Join(problems...)
+}
+
+// remove deletes one file, refusing anything outside the output directory
+// or that is not a regular file (e.g. a symlink pointing elsewhere)
+func (l *Library) remove(path string) error {
+	if err := l.checkInside(path); err != nil {
+		return err
+	}
+	info, err := os.Lstat(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if !info.Mode().IsRegular() {
+		return fmt.Errorf("refusing to delete %s: not a regular file", path)
+	}
+	if err := os.Remove(path); err != nil {
+		return fmt.Errorf("failed to delete %s: %w", path, err)
+	}
+	return nil
+}
+
+// removeBundle deletes a project bundle directory, refusing anything outside
+// the output directory or that is not a directory (e.g. a symlink to one)
+func (l *Library) removeBundle(path string) error {
+	if err := l.checkInside(path); err != nil {
+		return err
+	}
+	info, err := os.Lstat(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("refusing to delete %s: not a project bundle", path)
+	}
+	if err := os.RemoveAll(path); err != nil {
+		return fmt.Errorf("failed to delete %s: %w", path, err)
+	}
+	return nil
+}
+
+func (l *Library) checkInside(path string) error {
+	rel, err := filepath.Rel(l.dir, path)
+	if err != nil || rel == "." || strings.HasPrefix(rel, "..") || filepath.IsAbs(rel) {
+		return fmt.Errorf("refusing to delete %s: outside %s", path, l.dir)
+	}
+	return nil
+}
+
+// bundleSize adds up the files in a project bundle, leaving out hard links
+// to the recording itself, which are already counted
+func bundleSize(dir, videoPath string) (int64, bool) {
+	if info, err := os.Lstat(dir); err != nil || !info.IsDir() {
+		return 0, false
+	}
+	videoInfo, err := os.Stat(videoPath)
+	if err != nil {
+		return 0, false
+	}
+	var total int64
+	filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || !d.Type().IsRegular() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil || os.SameFile(info, videoInfo) {
+			return nil
+		}
+		total += info.Size()
+		return nil
+	})
+	return total, true
+}
+
+func fileSize(path string) (int64, bool) {
+	info, err := os.Stat(path)
+	if err != nil || !info.Mode().IsRegular() {
+		return 0, false
+	}
+	return info.Size(), true
+}
@@ -0,0 +1,133 @@
+package recording
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/vedantwpatil/Screen-Capture/internal/config"
+	"github.com/vedantwpatil/Screen-Capture/internal/project"
+	"github.com/vedantwpatil/Screen-Capture/internal/tracking"
+)
+
+// writeBundledRecording makes dir/demo.mp4 with its cursor sidecar and a
+// project bundle, as a finished recording leaves them
+func writeBundledRecording(t *testing.T, dir string) string {
+	t.Helper()
+	video := filepath.Join(dir, "demo.mp4")
+	if err := os.WriteFile(video, make([]byte, 4096), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cursor := tracking.HistoryPath(video)
+	if err := tracking.SaveHistory(cursor, time.Now(), []tracking.CursorPosition{{X: 1, Y: 2}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := project.Save(config.NewConfig(), video, cursor, time.Now(), nil); err != nil {
+		t.Fatal(err)
+	}
+	return video
+}
+
+func fileBytes(t *testing.T, path string) int64 {
+	t.Helper()
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return info.Size()
+}
+
+func TestLibraryCountsProjectBundle(t *testing.T) {
+	dir := t.TempDir()
+	video := writeBundledRecording(t, dir)
+	bundle := project.PathFor(video)
+
+	lib, err := NewLibrary(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries, err := lib.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("got %d entries, want 1 (the bundle is not a recording)", len(entries))
+	}
+	entry := entries[0]
+	if entry.ProjectPath != bundle {
+		t.Errorf("ProjectPath = %q, want %q", entry.ProjectPath, bundle)
+	}
+
+	// The bundle's own files count; its hard link to the video doesn't count twice
+	var bundled int64
+	for _, name := range []string{"project.json", "cursor.json", "settings.yaml"} {
+		bundled += fileBytes(t, filepath.Join(bundle, name))
+	}
+	if linked, err := os.Stat(filepath.Join(bundle, "recording.mp4")); err != nil {
+		t.Fatal(err)
+	} else if videoInfo, _ := os.Stat(video); !os.SameFile(linked, videoInfo) {
+		bundled += linked.Size() // Copied, not linked, on this filesystem
+	}
+	want := fileBytes(t, video) + fileBytes(t, tracking.HistoryPath(video)) + bundled
+	if entry.TotalSize != want {
+		t.Errorf("TotalSize = %d, want %d", entry.TotalSize, want)
+	}
+}
+
+func TestLibraryDeleteRemovesProjectBundle(t *testing.T) {
+	dir := t.TempDir()
+	video := writeBundledRecording(t, dir)
+	edited := filepath.Join(dir, "demo-edited.mp4")
+	if err := os.WriteFile(edited, []byte("edit"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	lib, err := NewLibrary(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries, err := lib.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := lib.Delete(entries[0]); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	for _, path := range []string{video, tracking.HistoryPath(video), project.PathFor(video)} {
+		if _, err := os.Lstat(path); !os.IsNotExist(err) {
+			t.Errorf("%s still exists", filepath.Base(path))
+		}
+	}
+	if _, err := os.Stat(edited); err != nil {
+		t.Errorf("the edited version was removed: %v", err)
+	}
+}
+
+func TestLibraryDeleteRefusesLinkedBundle(t *testing.T) {
+	// A bundle path that is a symlink to a directory elsewhere is left alone
+	dir := t.TempDir()
+	elsewhere := t.TempDir()
+	keep := filepath.Join(elsewhere, "keep.txt")
+	if err := os.WriteFile(keep, []byte("keep"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	video := filepath.Join(dir, "demo.mp4")
+	if err := os.WriteFile(video, []byte("video"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(elsewhere, project.PathFor(video)); err != nil {
+		t.Skipf("symlinks unavailable: %v", err)
+	}
+
+	lib, err := NewLibrary(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := lib.Delete(Entry{Name: "demo", Path: video}); err == nil {
+		t.Error("Delete followed a symlinked bundle without complaint")
+	}
+	if _, err := os.Stat(keep); err != nil {
+		t.Errorf("a file outside the output directory was removed: %v", err)
+	}
+}
@@ -0,0 +1,74 @@
+package recording
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ErrInvalidName is wrapped by every name validation failure
+var ErrInvalidName = errors.New("invalid recording name")
+
+// reservedChars can't appear in file names on Windows; rejecting them
+// everywhere keeps output folders portable
+const reservedChars = `<>:"|?*\`
+
+// ValidateName checks a user-supplied recording name, given without the
+// .mp4 extension. Names are plain file names, any script allowed; with
+// allowSubdirs they may also contain "/"-separated subdirectories of the
+// output directory. "." and ".." segments, absolute paths and control
+// characters are always refused.
+func ValidateName(name string, allowSubdirs bool) error {
+	invalid := func(reason string) error {
+		return fmt.Errorf("%w %q: %s", ErrInvalidName, name, reason)
+	}
+	if strings.TrimSpace(name) == "" {
+		return invalid("must not be empty")
+	}
+	if !utf8.ValidString(name) {
+		return invalid("must be valid UTF-8")
+	}
+	for _, r := range name {
+		if unicode.IsControl(r) {
+			return invalid("must not contain control characters")
+		}
+	}
+	if strings.ContainsAny(name, reservedChars) {
+		return invalid(fmt.Sprintf("must not contain any of %s", reservedChars))
+	}
+	if strings.Contains(name, "/") && !allowSubdirs {
+		return invalid("must be a plain file name (set recording.allow_subdirs to use folders)")
+	}
+	for _, segment := range strings.Split(name, "/") {
+		switch {
+		case segment == "":
+			return invalid("must not start or end with a slash or contain empty folders")
+		case segment == "." || segment == "..":
+			return invalid(`must not contain "." or ".." folders`)
+		case strings.HasPrefix(segment, "."):
+			// Dot files are hidden, and the library treats them as partial renders
+			return invalid("folder and file names must not start with a dot")
+		case strings.TrimSpace(segment) != segment || strings.HasSuffix(segment, "."):
+			// Windows silently drops trailing dots and spaces
+			return invalid("folder and file names must not start or end with spaces or end with a dot")
+		}
+	}
+	return nil
+}
+
+// OutputPath validates name and returns <outputDir>/<name>.mp4, checking the
+// cleaned result still lies inside outputDir
+func OutputPath(outputDir string, name string, allowSubdirs bool) (string, error) {
+	if err := ValidateName(name, allowSubdirs); err != nil {
+		return "", err
+	}
+	path := filepath.Join(outputDir, filepath.FromSlash(name)+".mp4")
+	rel, err := filepath.Rel(outputDir, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) || filepath.IsAbs(rel) {
+		return "", fmt.Errorf("%w %q: resolves outside %s", ErrInvalidName, name, outputDir)
+	}
+	return path, nil
+}
@@ -0,0 +1,99 @@
+package recording
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+func TestValidateName(t *testing.T) {
+	tests := []struct {
+		name         string
+		input        string
+		allowSubdirs bool
+		wantErr      bool
+	}{
+		{"plain name", "demo", false, false},
+		{"spaces inside", "my demo take 2", false, false},
+		{"non-Latin", "デモ録画", false, false},
+		{"Cyrillic with emoji", "запись 🎬", false, false},
+		{"accents", "démonstration", false, false},
+
+		{"empty", "", false, true},
+		{"only spaces", "   ", false, true},
+		{"parent folder", "../x", false, true},
+		{"parent folder with subdirs", "../x", true, true},
+		{"escape through subdirs", "a/../../b", true, true},
+		{"absolute path", "/abs", false, true},
+		{"absolute path with subdirs", "/abs", true, true},
+		{"Windows drive", `C:\x`, false, true},
+		{"Windows drive with subdirs", `C:\x`, true, true},
+		{"backslash separator", `a\b`, true, true},
+		{"trailing dot", "demo.", false, true},
+		{"trailing space", "demo ", false, true},
+		{"leading space", " demo", false, true},
+		{"dot file", ".demo", false, true},
+		{"current folder", "./demo", true, true},
+		{"newline", "demo\nrm", false, true},
+		{"NUL", "demo\x00", false, true},
+		{"escape", "demo\x1b[31m", false, true},
+		{"DEL", "demo\x7f", false, true},
+		{"invalid UTF-8", "demo\xff", false, true},
+		{"reserved character", "demo?", false, true},
+
+		{"subfolder without allowSubdirs", "client/demo", false, true},
+		{"subfolder with allowSubdirs", "client/demo", true, false},
+		{"non-Latin subfolder", "клиент/デモ", true, false},
+		{"double slash without allowSubdirs", "a//b", false, true},
+		{"double slash with allowSubdirs", "a//b", true, true},
+		{"trailing slash", "client/", true, true},
+		{"trailing dot in folder", "client./demo", true, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateName(tt.input, tt.allowSubdirs)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ValidateName(%q, %v) error = %v, want error %v", tt.input, tt.allowSubdirs, err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrInvalidName) {
+				t.Errorf("error %v does not wrap ErrInvalidName", err)
+			}
+		})
+	}
+}
+
+func TestOutputPath(t *testing.T) {
+	dir := filepath.Join("output", "recordings")
+	tests := []struct {
+		name         string
+		outputDir    string
+		input        string
+		allowSubdirs bool
+		want         string // Empty when an error is expected
+	}{
+		{"plain name", dir, "demo", false, filepath.Join(dir, "demo.mp4")},
+		{"non-Latin name", dir, "デモ", false, filepath.Join(dir, "デモ.mp4")},
+		{"subfolder", dir, "client/demo", true, filepath.Join(dir, "client", "demo.mp4")},
+		{"output dir is cleaned", "output/../recordings", "demo", false, filepath.Join("recordings", "demo.mp4")},
+		{"escape through a cleaned ..", dir, "a/../../b", true, ""},
+		{"escape to the parent", dir, "../b", true, ""},
+		{"absolute name", dir, "/etc/passwd", true, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := OutputPath(tt.outputDir, tt.input, tt.allowSubdirs)
+			if tt.want == "" {
+				if err == nil || !errors.Is(err, ErrInvalidName) {
+					t.Fatalf("OutputPath(%q) = %q, %v; want an ErrInvalidName error", tt.input, got, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("OutputPath(%q) error = %v", tt.input, err)
+			}
+			if got != tt.want {
+				t.Errorf("OutputPath(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
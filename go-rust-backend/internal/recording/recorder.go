@@ -4,35 +4,50 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"log"
+	"io"
+	"io/fs"
+	"log/slog"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"runtime"
-	"strconv"
-	"strings"
 	"sync"
 	"time"
 
 	"github.com/vedantwpatil/Screen-Capture/internal/config"
+	"github.com/vedantwpatil/Screen-Capture/internal/ffmpegcmd"
+	"github.com/vedantwpatil/Screen-Capture/internal/media"
+	"github.com/vedantwpatil/Screen-Capture/internal/platform"
+	"github.com/vedantwpatil/Screen-Capture/internal/project"
+	"github.com/vedantwpatil/Screen-Capture/internal/session"
 	"github.com/vedantwpatil/Screen-Capture/internal/tracking"
 )
 
 type Recorder struct {
-	config        *config.Config
-	isRecording   bool
-	isDone        bool
-	outputPath    string
-	cursorHistory []tracking.CursorPosition
-	stopChan      chan struct{}
-	doneChan      chan struct{}
-	startTime     time.Time
-	mu            sync.Mutex
-}
-
-func NewRecorder(config *config.Config) *Recorder {
+	config      *config.Config
+	logger      *slog.Logger
+	isRecording bool
+	isDone      bool
+	outputPath  string
+	cursorPath  string // Sidecar written by SaveCursorHistory, if any
+	projectPath string // Bundle written by SaveProject, if any
+	tracker     *tracking.Tracker
+	stopping    bool       // Set by the first Stop of a capture
+	stopOnce    *sync.Once // Closes stopChan once per capture
+	stopChan    chan struct{}
+	doneChan    chan struct{}
+	startTime   time.Time
+	err         error    // Why the capture failed, if it did
+	warnings    []string // Raised by the watchdog during the capture
+	events      subscribers
+	mu          sync.Mutex
+}
+
+func NewRecorder(config *config.Config, logger *slog.Logger) *Recorder {
 	return &Recorder{
 		config:   config,
+		logger:   logger,
+		stopOnce: new(sync.Once),
 		stopChan: make(chan struct{}),
 		doneChan: make(chan struct{}),
 	}
@@ -46,18 +61,34 @@ func (r *Recorder) Start(baseName string) error {
 	}
 	r.mu.Unlock()
 
-	// Create output directory if it doesn't exist
-	outputDir := "output"
-	if err := os.MkdirAll(outputDir, 0755); err != nil {
+	outputPath, err := OutputPath(r.config.Recording.OutputDir, baseName, r.config.Recording.AllowSubdirs)
+	if err != nil {
+		return err
+	}
+
+	// Create output directory (and any subfolder in the name) if it doesn't exist
+	if err := os.MkdirAll(filepath.Dir(outputPath), 0o755); err != nil {
 		return fmt.Errorf("failed to create output directory: %w", err)
 	}
 
 	// Set up paths and state
-	r.outputPath = filepath.Join(outputDir, baseName+".mp4")
+	r.outputPath = outputPath
 	r.mu.Lock()
 	r.isRecording = true
 	r.isDone = false
-	r.cursorHistory = make([]tracking.CursorPosition, 0)
+	r.err = nil
+	r.cursorPath = ""
+	r.projectPath = ""
+	r.warnings = nil
+	// Every capture gets its own channels, so a Stop racing the end of the
+	// last one can't close or wait on this one's
+	r.stopping = false
+	r.stopOnce = new(sync.Once)
+	r.stopChan = make(chan struct{})
+	r.doneChan = make(chan struct{})
+	stop, done := r.stopChan, r.doneChan
+	r.tracker = tracking.NewTracker(r.logger, r.config.Recording.CursorSampleHz)
+	tracker := r.tracker
 	r.startTime = time.Now() // Set the start time
 	r.mu.Unlock()
 
@@ -66,97 +97,214 @@ func (r *Recorder) Start(baseName string) error {
 
 	// Start recording in a goroutine
 	go func() {
-		r.startRecording()
+		r.startRecording(stop, done)
 		cancel() // Cancel the context when recording stops
 	}()
 
 	// Start mouse tracking in a goroutine
-	go tracking.StartMouseTracking(
-		&r.cursorHistory,
-		r.startTime,
-		r.config.Recording.TargetFPS,
-		ctx,
-	)
+	go func() {
+		if err := tracker.Run(ctx, r.startTime); err != nil {
+			r.logger.Warn("Cursor tracking unavailable for this recording", "err", err)
+		}
+	}()
 
+	resolved, err := filepath.Abs(r.outputPath)
+	if err != nil {
+		resolved = r.outputPath
+	}
+	r.publish(Event{Kind: EventStarted, OutputPath: resolved})
 	return nil
 }
 
-func (r *Recorder) startRecording() {
-	defer close(r.doneChan)
+func (r *Recorder) startRecording(stop <-chan struct{}, done chan struct{}) {
+	defer close(done)
 
+	stats, err := r.capture(stop)
+	if err != nil {
+		r.logger.Error("Recording failed", "err", err)
+	}
+
+	r.mu.Lock()
+	r.isRecording = false
+	r.isDone = err == nil
+	r.err = err
+	r.mu.Unlock()
+
+	if err != nil {
+		r.publish(Event{Kind: EventError, OutputPath: r.outputPath, Stats: stats, Err: err, Warnings: r.Warnings()})
+	} else {
+		r.publish(Event{Kind: EventStopped, OutputPath: r.outputPath, Stats: stats, Warnings: r.Warnings()})
+	}
+}
+
+// capture runs ffmpeg until stop is closed and returns once the file is
+// finalized, along with ffmpeg's final stats
+func (r *Recorder) capture(stopChan <-chan struct{}) (Stats, error) {
 	var cmd *exec.Cmd
 	osType := runtime.GOOS
 
 	switch osType {
 	case "darwin":
-		index, err := findScreenDeviceIndex()
+		index, err := findScreenDeviceIndex(r.logger)
 		if err != nil {
-			log.Printf("Unable to capture the correct device screen: %v", err)
-			return
+			return Stats{}, fmt.Errorf("unable to capture the correct device screen: %w", err)
 		}
-		cmd = exec.Command("ffmpeg",
-			"-f", "avfoundation",
-			"-framerate", fmt.Sprintf("%d", r.config.Recording.TargetFPS),
-			"-i", index+":none",
-			"-c:v", "libx264",
-			"-pix_fmt", "yuv420p",
-			"-preset", "ultrafast",
-			"-y",
-			r.outputPath)
+		args := ffmpegcmd.New().
+			Interactive().                 // Stop writes "q" to stdin
+			Global("-progress", "pipe:1"). // Read by watchProgress
+			Device("avfoundation", index+":none", "-framerate", fmt.Sprintf("%d", r.config.Recording.TargetFPS)).
+			Output(r.outputPath, media.RecordingEncodeOptions(), media.RecordingMuxArgs()...).
+			Args()
+		cmd = exec.Command("ffmpeg", args...)
 	default:
-		log.Printf("Unsupported operating system: %s", osType)
-		return
+		return Stats{}, fmt.Errorf("unsupported operating system %q", osType)
 	}
 
 	stdinPipe, err := cmd.StdinPipe()
 	if err != nil {
-		log.Printf("Failed to get stdin pipe: %v", err)
-		return
+		return Stats{}, fmt.Errorf("failed to get stdin pipe: %w", err)
 	}
 	defer stdinPipe.Close()
+	progressPipe, err := cmd.StdoutPipe()
+	if err != nil {
+		return Stats{}, fmt.Errorf("failed to get stdout pipe: %w", err)
+	}
 
-	cmd.Stderr = os.Stderr
+	// ffmpeg's own status output is only useful when not running quietly,
+	// but its last lines explain a capture that produced nothing
+	stderr := &tailBuffer{limit: 4096}
+	cmd.Stderr = stderr
+	if r.logger.Enabled(context.Background(), slog.LevelInfo) {
+		cmd.Stderr = io.MultiWriter(os.Stderr, stderr)
+	}
 
+	r.logger.Debug("Running ffmpeg", "args", cmd.Args)
 	if err := cmd.Start(); err != nil {
-		log.Printf("Failed to start ffmpeg: %v", err)
-		return
+		return Stats{}, fmt.Errorf("failed to start ffmpeg: %w", err)
+	}
+	stats := make(chan Stats, 1)
+	go func() { stats <- r.watchProgress(progressPipe) }()
+
+	if r.config.Recording.ShowIndicator {
+		indicator := platform.ShowIndicator(r.startTime)
+		defer indicator.Close()
+	}
+
+	// Lets the next launch find this ffmpeg if we crash before stopping it
+	if err := session.Save(session.State{
+		PID:        os.Getpid(),
+		FFmpegPID:  cmd.Process.Pid,
+		OutputPath: r.outputPath,
+		StartedAt:  r.startTime,
+	}); err != nil {
+		r.logger.Warn("Failed to record session state; a crash now cannot be recovered", "err", err)
 	}
+	defer func() {
+		if err := session.Clear(); err != nil {
+			r.logger.Warn("Failed to clear session state", "err", err)
+		}
+	}()
 
-	// Wait for stop signal
+	// Stop, or the display changing under the capture, asks ffmpeg to finish
+	var quit sync.Once
+	stop := func() {
+		quit.Do(func() {
+			stdinPipe.Write([]byte("q\n"))
+			stdinPipe.Close()
+		})
+	}
+	exited := make(chan struct{})
+	defer close(exited)
 	go func() {
-		<-r.stopChan
-		stdinPipe.Write([]byte("q\n"))
-		stdinPipe.Close()
+		select {
+		case <-stopChan:
+			stop()
+		case <-exited:
+		}
 	}()
+	go r.watchDisplay(exited, stop)
 
+	// The pipe must be drained before Wait closes it
+	final := <-stats
 	if err := cmd.Wait(); err != nil {
-		log.Printf("FFmpeg process finished with status: %v", err)
+		r.logger.Warn("FFmpeg process finished with non-zero status", "err", err)
+	} else {
+		r.logger.Debug("FFmpeg process finished", "status", 0)
 	}
 
-	r.mu.Lock()
-	r.isRecording = false
-	r.isDone = true
-	r.mu.Unlock()
+	// A capture that failed at once (bad device, no screen recording
+	// permission) leaves an empty file, or one without frames, that would
+	// only fail later in the edit
+	if err := r.checkOutput(); err != nil {
+		return final, fmt.Errorf("%w\n%s", err, stderr.Tail(10))
+	}
+	return final, nil
+}
+
+// checkOutput makes sure the capture wrote video. Only a file that is
+// certainly empty is removed: one ffprobe couldn't read (ffprobe missing,
+// an unexpected output format) may still hold the whole recording.
+func (r *Recorder) checkOutput() error {
+	if stat, err := os.Stat(r.outputPath); err != nil {
+		return fmt.Errorf("recording produced no video: %w", err)
+	} else if stat.Size() == 0 {
+		os.Remove(r.outputPath)
+		return fmt.Errorf("recording produced no video: %s is empty", r.outputPath)
+	}
+
+	info, err := media.Probe(r.outputPath)
+	switch {
+	case err != nil:
+		return fmt.Errorf("recording saved to %s, but it could not be checked: %w", r.outputPath, err)
+	case info.VideoStreams == 0 || info.Duration <= 0:
+		os.Remove(r.outputPath)
+		return fmt.Errorf("recording produced no video: %s has no frames", r.outputPath)
+	}
+	return nil
+}
+
+// tailBuffer keeps the last limit bytes written to it
+type tailBuffer struct {
+	mu    sync.Mutex
+	limit int
+	data  []byte
+}
+
+func (b *tailBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.data = append(b.data, p...)
+	if len(b.data) > b.limit {
+		b.data = append(b.data[:0], b.data[len(b.data)-b.limit:]...)
+	}
+	return len(p), nil
+}
+
+// Tail returns the last n lines written
+func (b *tailBuffer) Tail(n int) string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return ffmpegcmd.LastLines(string(b.data), n)
 }
 
+// Stop asks the capture to finish and waits until the file is finalized.
+// Callers racing each other (the API and Ctrl+C, say) all wait for the same
+// capture; only the first signals ffmpeg.
 func (r *Recorder) Stop() error {
 	r.mu.Lock()
 	if !r.isRecording {
 		r.mu.Unlock()
 		return fmt.Errorf("no recording in progress")
 	}
+	first := !r.stopping
+	r.stopping = true
+	once, stop, done := r.stopOnce, r.stopChan, r.doneChan
 	r.mu.Unlock()
 
-	// Signal recording to stop
-	close(r.stopChan)
-
-	// Wait for recording to finish
-	<-r.doneChan
-
-	// Reset channels for next recording
-	r.stopChan = make(chan struct{})
-	r.doneChan = make(chan struct{})
-
+	if first {
+		once.Do(func() { close(stop) })
+	}
+	<-done
 	return nil
 }
 
@@ -172,71 +320,115 @@ func (r *Recorder) IsDone() bool {
 	return r.isDone
 }
 
+// Done is closed once the capture has finished, whether stopped or failed
+func (r *Recorder) Done() <-chan struct{} {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.doneChan
+}
+
+// Err reports why the last capture failed, or nil
+func (r *Recorder) Err() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.err
+}
+
 func (r *Recorder) GetOutputPath() string {
 	return r.outputPath
 }
 
 func (r *Recorder) GetCursorHistory() []tracking.CursorPosition {
-	return r.cursorHistory
+	r.mu.Lock()
+	tracker := r.tracker
+	r.mu.Unlock()
+	if tracker == nil {
+		return nil
+	}
+	return tracker.Samples()
 }
 
 func (r *Recorder) GetStartTime() time.Time {
 	return r.startTime
 }
 
-func findScreenDeviceIndex() (string, error) {
-	cmd := exec.Command("ffmpeg", "-f", "avfoundation", "-list_devices", "true", "-i", "")
+// SaveCursorHistory writes the tracked cursor data next to the recording so
+// it can be edited later without the session that captured it
+func (r *Recorder) SaveCursorHistory() (string, error) {
+	path := tracking.HistoryPath(r.outputPath)
+	if err := tracking.SaveHistory(path, r.startTime, r.GetCursorHistory()); err != nil {
+		return "", err
+	}
+	r.mu.Lock()
+	r.cursorPath = path
+	r.mu.Unlock()
+	return path, nil
+}
 
-	outputBytes, err := cmd.CombinedOutput()
-	if err != nil {
-		if len(outputBytes) == 0 {
-			return "", fmt.Errorf("failed to run ffmpeg list_devices command: %v, output: %s", err, outputBytes)
+// SaveProject bundles the finished recording, its cursor data and the
+// current settings into <name>.focusframe. It saves the cursor data first if
+// that hasn't happened yet, and does nothing when projects are disabled.
+func (r *Recorder) SaveProject() (string, error) {
+	if !r.config.Recording.SaveProject {
+		return "", nil
+	}
+	r.mu.Lock()
+	cursorPath := r.cursorPath
+	r.mu.Unlock()
+	if cursorPath == "" {
+		var err error
+		if cursorPath, err = r.SaveCursorHistory(); err != nil {
+			return "", err
 		}
+	}
 
-		fmt.Println("Ffmpeg list_devices exited non-zero, but produced output. Proceeding with parsing.")
+	path, err := project.Save(r.config, r.outputPath, cursorPath, r.startTime, r.Warnings())
+	if err != nil {
+		return "", err
 	}
+	r.mu.Lock()
+	r.projectPath = path
+	r.mu.Unlock()
+	return path, nil
+}
 
-	output := string(outputBytes)
-	lines := strings.Split(output, "\n")
+// Discard stops a capture in progress and deletes the video, cursor sidecar
+// and project this recorder wrote, leaving it ready for a new recording under
+// the same name. Only paths recorded by Start, SaveCursorHistory and
+// SaveProject are removed.
+func (r *Recorder) Discard() error {
+	if r.IsRecording() {
+		// Stop waits for ffmpeg to exit, so the file is closed before removal
+		// (Windows refuses to delete open files)
+		if err := r.Stop(); err != nil {
+			return err
+		}
+	}
 
-	// Get main desktop device index
-	inVideoDevices := false
-	videoDeviceIndex := 0
-	for _, line := range lines {
-		if strings.Contains(line, "AVFoundation video devices:") {
-			inVideoDevices = true
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var problems []error
+	for _, path := range []string{r.outputPath, r.cursorPath} {
+		if path == "" {
 			continue
 		}
-		// TODO: Add audio support
-		// Currently not capturing the audio
-		if strings.Contains(line, "AVFoundation audio devices:") {
-			inVideoDevices = false
-			break
+		if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			problems = append(problems, fmt.Errorf("failed to discard %s: %w", path, err))
 		}
-
-		if inVideoDevices {
-
-			trimmedLine := strings.TrimSpace(line)
-			if strings.Contains(trimmedLine, "Capture screen 0") {
-				fmt.Println("Located main device screen")
-				return strconv.Itoa(videoDeviceIndex), nil
-			}
-
-			if strings.Contains(trimmedLine, "]") && len(trimmedLine) > 0 {
-				videoDeviceIndex++
-			}
+	}
+	if r.projectPath != "" {
+		if err := os.RemoveAll(r.projectPath); err != nil {
+			problems = append(problems, fmt.Errorf("failed to discard %s: %w", r.projectPath, err))
 		}
 	}
+	r.logger.Info("Discarded recording", "output", r.outputPath)
 
-	return "", errors.New("could not find 'Capture screen 0' in ffmpeg device list")
-}
-
-func GetVideoResolution(path string) (string, error) {
-	cmd := exec.Command("ffprobe", "-v", "error", "-select_streams", "v:0", "-show_entries", "stream=width,height", "-of", "csv=s=x:p=0", path)
-	out, err := cmd.CombinedOutput()
-	if err != nil {
-		return "Failed to get the video resolution. The file path tried was: " + path, err
-	}
-	resolution := strings.TrimSpace(string(out))
-	return resolution, nil
+	r.outputPath = ""
+	r.cursorPath = ""
+	r.projectPath = ""
+	r.tracker = nil
+	r.warnings = nil
+	r.isDone = false
+	r.err = nil
+	return errors.Join(problems...)
 }
@@ -0,0 +1,97 @@
+package recording
+
+import (
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/vedantwpatil/Screen-Capture/internal/config"
+)
+
+func TestCheckOutput(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  []byte
+		wantKept bool
+	}{
+		{"empty file is removed", nil, false},
+		// Whether ffprobe is missing or rejects the data, the file may hold
+		// a recording it can't read, so it stays
+		{"unreadable file is kept", []byte("not an mp4 file, but not empty either"), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "demo.mp4")
+			if err := os.WriteFile(path, tt.content, 0o644); err != nil {
+				t.Fatal(err)
+			}
+			r := &Recorder{outputPath: path}
+			if err := r.checkOutput(); err == nil {
+				t.Fatal("checkOutput accepted a file without video")
+			}
+			_, err := os.Stat(path)
+			if kept := err == nil; kept != tt.wantKept {
+				t.Errorf("file kept = %v, want %v", kept, tt.wantKept)
+			}
+		})
+	}
+}
+
+func TestCheckOutputMissingFile(t *testing.T) {
+	r := &Recorder{outputPath: filepath.Join(t.TempDir(), "none.mp4")}
+	if err := r.checkOutput(); err == nil {
+		t.Error("checkOutput accepted a missing file")
+	}
+}
+
+// fakeCapture marks r as recording and finishes like a capture once stopped
+func fakeCapture(r *Recorder) {
+	r.mu.Lock()
+	r.isRecording = true
+	stop, done := r.stopChan, r.doneChan
+	r.mu.Unlock()
+	go func() {
+		<-stop
+		time.Sleep(10 * time.Millisecond) // ffmpeg finalizing the file
+		r.mu.Lock()
+		r.isRecording = false
+		r.mu.Unlock()
+		close(done)
+	}()
+}
+
+func TestStopConcurrently(t *testing.T) {
+	r := NewRecorder(config.NewConfig(), slog.New(slog.NewTextHandler(io.Discard, nil)))
+	fakeCapture(r)
+
+	// Every caller waits for the capture; none closes the stop channel twice
+	const callers = 8
+	var wg sync.WaitGroup
+	errs := make(chan error, callers)
+	for range callers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- r.Stop()
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil && err.Error() != "no recording in progress" {
+			t.Errorf("Stop() = %v", err)
+		}
+	}
+	if r.IsRecording() {
+		t.Error("still recording after Stop")
+	}
+
+	// Once finished, stopping again is refused rather than a panic
+	if err := r.Stop(); err == nil {
+		t.Error("Stop() after the capture ended succeeded")
+	}
+}
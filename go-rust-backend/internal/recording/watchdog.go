@@ -0,0 +1,154 @@
+package recording
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/vedantwpatil/Screen-Capture/internal/config"
+	"github.com/vedantwpatil/Screen-Capture/internal/platform"
+)
+
+// growthWindow is how much history the file growth rate is measured over;
+// encoders write in bursts, so anything much shorter swings wildly
+const growthWindow = time.Minute
+
+// The encoder counts as falling behind after slowUpdates consecutive ffmpeg
+// updates (~3s) below slowSpeed
+const (
+	slowSpeed   = 0.9
+	slowUpdates = 6
+)
+
+// diskCheckPeriod limits free space lookups; Statfs is cheap, but not every
+// half second cheap
+const diskCheckPeriod = 5 * time.Second
+
+// Reading is the file size and dropped frame count at one moment
+type Reading struct {
+	At            time.Time
+	Size          int64
+	DroppedFrames int64
+}
+
+// GrowthRate is the bytes per second written between the first and last
+// readings, in time order. ok is false until the file has grown.
+func GrowthRate(readings []Reading) (float64, bool) {
+	if len(readings) < 2 {
+		return 0, false
+	}
+	first, last := readings[0], readings[len(readings)-1]
+	elapsed := last.At.Sub(first.At).Seconds()
+	grown := last.Size - first.Size
+	if elapsed <= 0 || grown <= 0 {
+		return 0, false
+	}
+	return float64(grown) / elapsed, true
+}
+
+// TimeToFull is how long free bytes last at rate bytes per second; ok is
+// false when nothing is being written
+func TimeToFull(free uint64, rate float64) (time.Duration, bool) {
+	if rate <= 0 {
+		return 0, false
+	}
+	return time.Duration(float64(free) / rate * float64(time.Second)), true
+}
+
+// DroppedSince counts frames dropped after since, from readings in time
+// order; the latest reading before since is the baseline
+func DroppedSince(readings []Reading, since time.Time) int64 {
+	if len(readings) == 0 {
+		return 0
+	}
+	baseline := int64(0)
+	for _, r := range readings {
+		if r.At.After(since) {
+			break
+		}
+		baseline = r.DroppedFrames
+	}
+	return readings[len(readings)-1].DroppedFrames - baseline
+}
+
+// watchdog raises Warning events from ffmpeg's stats while capturing
+type watchdog struct {
+	cfg      config.WatchdogConfig
+	dir      string
+	readings []Reading
+
+	slow        int // Consecutive updates below slowSpeed
+	slowWarned  bool
+	diskWarned  bool
+	dropsWarned time.Time // Drops are warned about at most once per window
+	diskChecked time.Time
+	nextLimit   time.Duration // Next soft duration limit to announce
+}
+
+func newWatchdog(cfg config.WatchdogConfig, outputPath string) *watchdog {
+	return &watchdog{
+		cfg:       cfg,
+		dir:       filepath.Dir(outputPath),
+		nextLimit: time.Duration(cfg.SoftLimitMins) * time.Minute,
+	}
+}
+
+// check takes the latest stats and returns any warnings they call for
+func (w *watchdog) check(now time.Time, stats Stats) []string {
+	var warnings []string
+	w.readings = append(w.readings, Reading{At: now, Size: stats.Size, DroppedFrames: stats.DroppedFrames})
+	// One reading older than the windows stays as their baseline
+	keep := max(growthWindow, time.Duration(w.cfg.DroppedWindowSecs)*time.Second)
+	cut := 0
+	for cut < len(w.readings)-2 && now.Sub(w.readings[cut+1].At) > keep {
+		cut++
+	}
+	w.readings = w.readings[cut:]
+
+	if stats.Speed > 0 && stats.Speed < slowSpeed {
+		w.slow++
+	} else if stats.Speed >= slowSpeed {
+		w.slow, w.slowWarned = 0, false
+	}
+	if w.slow >= slowUpdates && !w.slowWarned {
+		w.slowWarned = true
+		warnings = append(warnings, fmt.Sprintf("encoder is falling behind (%.2fx real time); try a lower frame rate", stats.Speed))
+	}
+
+	if limit := w.cfg.DroppedFramesWarn; limit > 0 {
+		window := time.Duration(w.cfg.DroppedWindowSecs) * time.Second
+		dropped := DroppedSince(w.readings, now.Add(-window))
+		if dropped >= int64(limit) && now.Sub(w.dropsWarned) >= window {
+			w.dropsWarned = now
+			warnings = append(warnings, fmt.Sprintf("%d frames dropped in the last %s", dropped, window))
+		}
+	}
+
+	if w.cfg.DiskFullWarnMins > 0 && !w.diskWarned && now.Sub(w.diskChecked) >= diskCheckPeriod {
+		w.diskChecked = now
+		warnings = append(warnings, w.checkDisk()...)
+	}
+
+	if w.nextLimit > 0 && stats.Elapsed >= w.nextLimit {
+		warnings = append(warnings, fmt.Sprintf("recording has passed %s", w.nextLimit))
+		w.nextLimit += time.Duration(w.cfg.SoftLimitMins) * time.Minute
+	}
+	return warnings
+}
+
+func (w *watchdog) checkDisk() []string {
+	rate, ok := GrowthRate(w.readings)
+	if !ok {
+		return nil
+	}
+	free, err := platform.FreeSpace(w.dir)
+	if err != nil {
+		return nil
+	}
+	left, ok := TimeToFull(free, rate)
+	if !ok || left > time.Duration(w.cfg.DiskFullWarnMins)*time.Minute {
+		return nil
+	}
+	w.diskWarned = true
+	return []string{fmt.Sprintf("disk in %s will fill in about %s at the current rate", w.dir, left.Round(time.Second))}
+}
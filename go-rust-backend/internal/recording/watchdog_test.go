@@ -0,0 +1,139 @@
+package recording
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vedantwpatil/Screen-Capture/internal/config"
+)
+
+var t0 = time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+func at(secs float64) time.Time {
+	return t0.Add(time.Duration(secs * float64(time.Second)))
+}
+
+func TestGrowthRate(t *testing.T) {
+	tests := []struct {
+		name     string
+		readings []Reading
+		want     float64
+		ok       bool
+	}{
+		{"no readings", nil, 0, false},
+		{"one reading", []Reading{{At: at(0), Size: 100}}, 0, false},
+		{"steady", []Reading{{At: at(0), Size: 0}, {At: at(1), Size: 1000}, {At: at(2), Size: 2000}}, 1000, true},
+		// A burst in between doesn't change the average
+		{"bursty", []Reading{{At: at(0), Size: 0}, {At: at(1), Size: 3900}, {At: at(4), Size: 4000}}, 1000, true},
+		{"not growing", []Reading{{At: at(0), Size: 500}, {At: at(10), Size: 500}}, 0, false},
+		{"shrinking", []Reading{{At: at(0), Size: 500}, {At: at(10), Size: 100}}, 0, false},
+		{"no time passed", []Reading{{At: at(5), Size: 0}, {At: at(5), Size: 100}}, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := GrowthRate(tt.readings)
+			if ok != tt.ok || got != tt.want {
+				t.Errorf("GrowthRate() = %g, %v; want %g, %v", got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestTimeToFull(t *testing.T) {
+	tests := []struct {
+		name string
+		free uint64
+		rate float64
+		want time.Duration
+		ok   bool
+	}{
+		{"a minute left", 60_000_000, 1_000_000, time.Minute, true},
+		{"disk already full", 0, 1000, 0, true},
+		{"fractional seconds", 3, 2, 1500 * time.Millisecond, true},
+		{"nothing written", 1 << 30, 0, 0, false},
+		{"negative rate", 1 << 30, -5, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := TimeToFull(tt.free, tt.rate)
+			if ok != tt.ok || got != tt.want {
+				t.Errorf("TimeToFull() = %v, %v; want %v, %v", got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestDroppedSince(t *testing.T) {
+	readings := []Reading{
+		{At: at(0), DroppedFrames: 0},
+		{At: at(10), DroppedFrames: 5},
+		{At: at(20), DroppedFrames: 12},
+		{At: at(30), DroppedFrames: 40},
+	}
+	tests := []struct {
+		name     string
+		readings []Reading
+		since    time.Time
+		want     int64
+	}{
+		{"no readings", nil, at(0), 0},
+		{"before everything", readings, at(-5), 40},
+		{"from the first", readings, at(0), 40},
+		// The latest reading at or before since is the baseline
+		{"between readings", readings, at(15), 35},
+		{"on a reading", readings, at(20), 28},
+		{"after the last", readings, at(31), 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DroppedSince(tt.readings, tt.since); got != tt.want {
+				t.Errorf("DroppedSince() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWatchdogCheck(t *testing.T) {
+	cfg := config.WatchdogConfig{
+		DroppedFramesWarn: 10,
+		DroppedWindowSecs: 30,
+		SoftLimitMins:     1,
+	}
+	w := newWatchdog(cfg, t.TempDir()+"/demo.mp4")
+
+	var warnings []string
+	for i := 0; i <= 150; i++ {
+		secs := float64(i) / 2
+		stats := Stats{
+			Elapsed: time.Duration(secs * float64(time.Second)),
+			Speed:   1,
+		}
+		// Ten slow updates from 10s; 12 frames dropped between 40s and 45s
+		if secs >= 10 && secs < 15 {
+			stats.Speed = 0.5
+		}
+		if secs >= 40 {
+			stats.DroppedFrames = int64(min(12, 2*(secs-40)+2))
+		}
+		warnings = append(warnings, w.check(at(secs), stats)...)
+	}
+
+	want := []string{
+		"encoder is falling behind (0.50x real time)",
+		"10 frames dropped in the last 30s", // As soon as the limit is reached
+		"recording has passed 1m0s",
+	}
+	if len(warnings) != len(want) {
+		t.Fatalf("warnings = %q, want %d", warnings, len(want))
+	}
+	for i, prefix := range want {
+		if !strings.HasPrefix(warnings[i], prefix) {
+			t.Errorf("warning %d = %q, want it to start with %q", i, warnings[i], prefix)
+		}
+	}
+	// Readings older than the windows are dropped, keeping one baseline
+	if first := w.readings[0].At; at(75).Sub(first) > growthWindow+time.Second {
+		t.Errorf("oldest reading kept is from %v, %v before the last", first, at(75).Sub(first))
+	}
+}
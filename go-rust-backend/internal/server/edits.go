@@ -0,0 +1,187 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/vedantwpatil/Screen-Capture/internal/editing"
+	"github.com/vedantwpatil/Screen-Capture/internal/tracking"
+)
+
+// progressInterval is how often a progress stream reports while an edit runs
+const progressInterval = 250 * time.Millisecond
+
+// editRetention is how long a finished edit nobody has streamed stays listed
+const editRetention = 10 * time.Minute
+
+type startEditRequest struct {
+	Input  string `json:"input"`
+	Cursor string `json:"cursor,omitempty"` // Defaults to the recording's .cursor.json sidecar
+	Output string `json:"output,omitempty"` // Defaults to <name>-edited.mp4
+}
+
+type editStatus struct {
+	ID       string  `json:"id"`
+	Output   string  `json:"output"`
+	Progress float32 `json:"progress"`
+	Done     bool    `json:"done"`
+	Error    string  `json:"error,omitempty"`
+}
+
+// edit is one pipeline run started through the API
+type edit struct {
+	id     string
+	output string
+	done   chan struct{}
+
+	mu       sync.Mutex
+	progress float32
+	err      error
+}
+
+func (e *edit) status() editStatus {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	status := editStatus{ID: e.id, Output: e.output, Progress: e.progress}
+	select {
+	case <-e.done:
+		status.Done = true
+		if e.err != nil {
+			status.Error = e.err.Error()
+		}
+	default:
+	}
+	return status
+}
+
+// POST /edits starts the effects pipeline on a finished recording
+func (s *Server) startEdit(w http.ResponseWriter, r *http.Request) {
+	var req startEditRequest
+	if err := decodeBody(w, r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if req.Input == "" {
+		writeError(w, http.StatusBadRequest, errors.New("input is required"))
+		return
+	}
+	if _, err := os.Stat(req.Input); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if req.Cursor == "" {
+		req.Cursor = tracking.HistoryPath(req.Input)
+	}
+	if req.Output == "" {
+		req.Output = editing.EditedPath(req.Input)
+	}
+
+	history, startTime, err := tracking.LoadHistory(req.Cursor)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	cfg, err := s.loadConfig()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, fmt.Errorf("configuration problems: %w", err))
+		return
+	}
+
+	s.mu.Lock()
+	e := &edit{id: s.nextEditID(), output: req.Output, done: make(chan struct{})}
+	s.edits[e.id] = e
+	ctx := s.ctx
+	s.running.Add(1)
+	s.mu.Unlock()
+
+	go func() {
+		defer s.running.Done()
+		defer close(e.done)
+		err := s.runEdit(ctx, cfg, editing.Request{
+			InputPath:    req.Input,
+			OutputPath:   req.Output,
+			MouseHistory: history,
+			StartTime:    startTime,
+			Progress: func(percent float32) {
+				e.mu.Lock()
+				e.progress = percent
+				e.mu.Unlock()
+			},
+		})
+		e.mu.Lock()
+		e.err = err
+		if err == nil {
+			e.progress = 1
+		}
+		e.mu.Unlock()
+		if err != nil {
+			s.logger.Error("Edit failed", "id", e.id, "err", err)
+		}
+		time.AfterFunc(s.keepEdit, func() { s.forgetEdit(e.id) })
+	}()
+
+	writeJSON(w, http.StatusAccepted, e.status())
+}
+
+// GET /edits/{id}/progress streams progress as server-sent events until the
+// edit finishes: "progress" events while running, then one "done" or "error"
+func (s *Server) editProgress(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	e, ok := s.edits[r.PathValue("id")]
+	s.mu.Unlock()
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Errorf("no edit with id %q", r.PathValue("id")))
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, errors.New("streaming is not supported"))
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+
+	ticker := time.NewTicker(progressInterval)
+	defer ticker.Stop()
+	for {
+		status := e.status()
+		event := "progress"
+		if status.Done {
+			event = "done"
+			if status.Error != "" {
+				event = "error"
+			}
+		}
+		data, _ := json.Marshal(status)
+		if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, data); err != nil {
+			return
+		}
+		flusher.Flush()
+		if status.Done {
+			// The client has the result, so the edit need not wait out its retention
+			s.forgetEdit(e.id)
+			return
+		}
+
+		select {
+		case <-r.Context().Done():
+			return
+		case <-e.done:
+		case <-ticker.C:
+		}
+	}
+}
+
+// forgetEdit drops a finished edit; its progress endpoint then returns 404
+func (s *Server) forgetEdit(id string) {
+	s.mu.Lock()
+	delete(s.edits, id)
+	s.mu.Unlock()
+}
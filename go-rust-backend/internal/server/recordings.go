@@ -0,0 +1,216 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/vedantwpatil/Screen-Capture/internal/recording"
+)
+
+type startRecordingRequest struct {
+	Name string `json:"name"` // Base file name without extension
+}
+
+type recordingStats struct {
+	Recording     bool    `json:"recording"`
+	Output        string  `json:"output"`
+	ElapsedSecs   float64 `json:"elapsed_secs"`
+	CursorSamples int     `json:"cursor_samples"`
+	Error         string  `json:"error,omitempty"`
+}
+
+type recordingEvent struct {
+	Output        string   `json:"output"`
+	ElapsedSecs   float64  `json:"elapsed_secs"`
+	Frames        int64    `json:"frames"`
+	FPS           float64  `json:"fps"`
+	Speed         float64  `json:"speed"`
+	Size          int64    `json:"size"`
+	DroppedFrames int64    `json:"dropped_frames"`
+	Message       string   `json:"message,omitempty"`
+	Error         string   `json:"error,omitempty"`
+	Warnings      []string `json:"warnings,omitempty"` // On stopped and error
+}
+
+type recordingResult struct {
+	Output       string   `json:"output"`
+	Cursor       string   `json:"cursor"`
+	Project      string   `json:"project,omitempty"`
+	DurationSecs float64  `json:"duration_secs"`
+	Warnings     []string `json:"warnings,omitempty"`
+}
+
+// POST /recordings starts a recording; 409 if one is already running
+func (s *Server) startRecording(w http.ResponseWriter, r *http.Request) {
+	var req startRecordingRequest
+	if err := decodeBody(w, r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	cfg, err := s.loadConfig()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, fmt.Errorf("configuration problems: %w", err))
+		return
+	}
+	if err := recording.ValidateName(req.Name, cfg.Recording.AllowSubdirs); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.recorder != nil && s.recorder.IsRecording() {
+		writeError(w, http.StatusConflict, errors.New("a recording is already in progress"))
+		return
+	}
+
+	recorder := s.newRecorder(cfg, s.logger)
+	if err := recorder.Start(req.Name); err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	s.recorder = recorder
+	writeJSON(w, http.StatusCreated, statsOf(recorder))
+}
+
+// DELETE /recordings/current stops the recording and waits for the file;
+// 409 while another request is already stopping it
+func (s *Server) stopRecording(w http.ResponseWriter, r *http.Request) {
+	recorder, err := s.beginStop()
+	if errors.Is(err, errStopping) {
+		writeError(w, http.StatusConflict, err)
+		return
+	} else if err != nil {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+	defer s.endStop()
+
+	if err := recorder.Stop(); err != nil {
+		writeError(w, http.StatusConflict, err)
+		return
+	}
+	if err := recorder.Err(); err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	cursorPath, err := recorder.SaveCursorHistory()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	projectPath, err := recorder.SaveProject()
+	if err != nil {
+		s.logger.Warn("Failed to save project", "err", err)
+	}
+
+	writeJSON(w, http.StatusOK, recordingResult{
+		Output:       recorder.GetOutputPath(),
+		Cursor:       cursorPath,
+		Project:      projectPath,
+		DurationSecs: time.Since(recorder.GetStartTime()).Seconds(),
+		Warnings:     recorder.Warnings(),
+	})
+}
+
+// GET /recordings/current/stats reports on the current or last recording
+func (s *Server) recordingStats(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	recorder := s.recorder
+	s.mu.Unlock()
+	if recorder == nil {
+		writeError(w, http.StatusNotFound, errors.New("nothing has been recorded yet"))
+		return
+	}
+	writeJSON(w, http.StatusOK, statsOf(recorder))
+}
+
+func statsOf(recorder recorder) recordingStats {
+	stats := recordingStats{
+		Recording:     recorder.IsRecording(),
+		Output:        recorder.GetOutputPath(),
+		ElapsedSecs:   time.Since(recorder.GetStartTime()).Seconds(),
+		CursorSamples: len(recorder.GetCursorHistory()),
+	}
+	if err := recorder.Err(); err != nil {
+		stats.Error = err.Error()
+	}
+	return stats
+}
+
+// GET /recordings/current/events streams the recording's events as
+// server-sent events named after their kind ("progress", "warning", ...)
+// until it stops or fails. A recording that already ended gets a single
+// "stopped" or "error" event.
+func (s *Server) recordingEvents(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	recorder := s.recorder
+	s.mu.Unlock()
+	if recorder == nil {
+		writeError(w, http.StatusNotFound, errors.New("nothing has been recorded yet"))
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, errors.New("streaming is not supported"))
+		return
+	}
+
+	// Subscribe before checking, so an ending in between isn't missed
+	events, unsubscribe := recorder.Subscribe()
+	defer unsubscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+
+	send := func(event recording.Event) bool {
+		data, _ := json.Marshal(recordingEvent{
+			Output:        event.OutputPath,
+			ElapsedSecs:   event.Stats.Elapsed.Seconds(),
+			Frames:        event.Stats.Frames,
+			FPS:           event.Stats.FPS,
+			Speed:         event.Stats.Speed,
+			Size:          event.Stats.Size,
+			DroppedFrames: event.Stats.DroppedFrames,
+			Message:       event.Message,
+			Error:         errorText(event.Err),
+			Warnings:      event.Warnings,
+		})
+		if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Kind, data); err != nil {
+			return false
+		}
+		flusher.Flush()
+		return event.Kind != recording.EventStopped && event.Kind != recording.EventError
+	}
+
+	if !recorder.IsRecording() {
+		final := recording.Event{Kind: recording.EventStopped, OutputPath: recorder.GetOutputPath(), Warnings: recorder.Warnings()}
+		if err := recorder.Err(); err != nil {
+			final.Kind, final.Err = recording.EventError, err
+		}
+		send(final)
+		return
+	}
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case event := <-events:
+			if !send(event) {
+				return
+			}
+		}
+	}
+}
+
+func errorText(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
@@ -0,0 +1,234 @@
+// Package server exposes recording and editing over a local JSON HTTP API so
+// a GUI shell can drive FocusFrame
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/vedantwpatil/Screen-Capture/internal/config"
+	"github.com/vedantwpatil/Screen-Capture/internal/editing"
+	"github.com/vedantwpatil/Screen-Capture/internal/recording"
+	"github.com/vedantwpatil/Screen-Capture/internal/tracking"
+)
+
+// shutdownTimeout bounds how long in-flight requests get once the context ends
+const shutdownTimeout = 5 * time.Second
+
+// recorder is the part of *recording.Recorder the API drives
+type recorder interface {
+	Start(baseName string) error
+	Stop() error
+	IsRecording() bool
+	Err() error
+	Warnings() []string
+	Subscribe() (<-chan recording.Event, func())
+	GetOutputPath() string
+	GetStartTime() time.Time
+	GetCursorHistory() []tracking.CursorPosition
+	SaveCursorHistory() (string, error)
+	SaveProject() (string, error)
+}
+
+// Server owns at most one recording and any number of edits
+type Server struct {
+	loadConfig  func() (*config.Config, error) // Re-read for every recording or edit
+	newRecorder func(*config.Config, *slog.Logger) recorder
+	runEdit     func(context.Context, *config.Config, editing.Request) error
+	logger      *slog.Logger
+	token       string
+	keepEdit    time.Duration // How long a finished edit's status stays available
+
+	mu       sync.Mutex
+	recorder recorder // Current or most recent recording
+	stopping bool     // A stop is finalizing the recording
+	edits    map[string]*edit
+	nextEdit int
+	ctx      context.Context // Parent of every edit; cancelled on shutdown
+	running  sync.WaitGroup  // Edits still rendering
+}
+
+// New creates a server with a fresh random access token
+func New(loadConfig func() (*config.Config, error), logger *slog.Logger) (*Server, error) {
+	token, err := newToken()
+	if err != nil {
+		return nil, err
+	}
+	return &Server{
+		loadConfig: loadConfig,
+		newRecorder: func(cfg *config.Config, logger *slog.Logger) recorder {
+			return recording.NewRecorder(cfg, logger)
+		},
+		runEdit:  editing.Edit,
+		logger:   logger,
+		token:    token,
+		edits:    make(map[string]*edit),
+		keepEdit: editRetention,
+		ctx:      context.Background(),
+	}, nil
+}
+
+// Token is the secret clients must send as "Authorization: Bearer <token>"
+func (s *Server) Token() string {
+	return s.token
+}
+
+// Handler returns the API routes, all behind token authentication
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /recordings", s.startRecording)
+	mux.HandleFunc("DELETE /recordings/current", s.stopRecording)
+	mux.HandleFunc("GET /recordings/current/stats", s.recordingStats)
+	mux.HandleFunc("GET /recordings/current/events", s.recordingEvents)
+	mux.HandleFunc("POST /edits", s.startEdit)
+	mux.HandleFunc("GET /edits/{id}/progress", s.editProgress)
+	return s.authenticate(mux)
+}
+
+// ListenAndServe serves on addr until ctx is cancelled, then stops any
+// recording, cancels running edits and shuts the listener down
+func (s *Server) ListenAndServe(ctx context.Context, addr string) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", addr, err)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	s.mu.Lock()
+	s.ctx = ctx
+	s.mu.Unlock()
+
+	srv := &http.Server{
+		Handler: s.Handler(),
+		// Request contexts end with ctx so progress streams don't hold up shutdown
+		BaseContext:       func(net.Listener) context.Context { return ctx },
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	serveErr := make(chan error, 1)
+	go func() { serveErr <- srv.Serve(listener) }()
+	s.logger.Info("API listening", "addr", listener.Addr().String())
+
+	select {
+	case err := <-serveErr:
+		cancel()
+		s.stopAll()
+		s.running.Wait()
+		return err
+	case <-ctx.Done():
+	}
+
+	s.logger.Info("Shutting down API")
+	s.stopAll()
+	shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancelShutdown()
+	err = srv.Shutdown(shutdownCtx)
+
+	// Cancelled edits remove their partial output before returning
+	s.running.Wait()
+	if err != nil {
+		return fmt.Errorf("API shutdown failed: %w", err)
+	}
+	return nil
+}
+
+// stopAll finalizes a running recording so the file stays playable
+func (s *Server) stopAll() {
+	if recorder, err := s.beginStop(); err == nil {
+		defer s.endStop()
+		if err := recorder.Stop(); err != nil {
+			s.logger.Error("Error stopping recording", "err", err)
+		} else if _, err := recorder.SaveCursorHistory(); err != nil {
+			s.logger.Warn("Failed to save cursor data", "err", err)
+		}
+	}
+	// Edits derive from s.ctx and are cancelled along with it
+}
+
+var (
+	errNotRecording = errors.New("no recording in progress")
+	errStopping     = errors.New("the recording is already stopping")
+)
+
+// beginStop claims the running recording so only one caller stops it; the
+// caller must call endStop once the recording is finalized
+func (s *Server) beginStop() (recorder, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch {
+	case s.stopping:
+		return nil, errStopping
+	case s.recorder == nil || !s.recorder.IsRecording():
+		return nil, errNotRecording
+	}
+	s.stopping = true
+	return s.recorder, nil
+}
+
+func (s *Server) endStop() {
+	s.mu.Lock()
+	s.stopping = false
+	s.mu.Unlock()
+}
+
+func (s *Server) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok {
+			// EventSource cannot set headers, so progress streams may pass it in the query
+			token = r.URL.Query().Get("token")
+		}
+		if subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) != 1 {
+			writeError(w, http.StatusUnauthorized, errors.New("missing or invalid token"))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func newToken() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate API token: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+func (s *Server) nextEditID() string {
+	s.nextEdit++
+	return strconv.Itoa(s.nextEdit)
+}
+
+func decodeBody(w http.ResponseWriter, r *http.Request, v any) error {
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return fmt.Errorf("invalid request body: %w", err)
+	}
+	return nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		slog.Debug("Failed to write API response", "err", err)
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
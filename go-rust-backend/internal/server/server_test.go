@@ -0,0 +1,371 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/vedantwpatil/Screen-Capture/internal/config"
+	"github.com/vedantwpatil/Screen-Capture/internal/editing"
+	"github.com/vedantwpatil/Screen-Capture/internal/recording"
+	"github.com/vedantwpatil/Screen-Capture/internal/tracking"
+)
+
+// fakeRecorder records until Stop without running ffmpeg
+type fakeRecorder struct {
+	mu        sync.Mutex
+	recording bool
+	started   time.Time
+}
+
+func (f *fakeRecorder) Start(string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.recording, f.started = true, time.Now()
+	return nil
+}
+
+func (f *fakeRecorder) Stop() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if !f.recording {
+		return errors.New("no recording in progress")
+	}
+	f.recording = false
+	return nil
+}
+
+func (f *fakeRecorder) IsRecording() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.recording
+}
+
+func (f *fakeRecorder) Err() error         { return nil }
+func (f *fakeRecorder) Warnings() []string { return nil }
+func (f *fakeRecorder) Subscribe() (<-chan recording.Event, func()) {
+	return make(chan recording.Event), func() {}
+}
+func (f *fakeRecorder) GetOutputPath() string                       { return "recordings/demo.mp4" }
+func (f *fakeRecorder) GetStartTime() time.Time                     { return f.started }
+func (f *fakeRecorder) GetCursorHistory() []tracking.CursorPosition { return nil }
+func (f *fakeRecorder) SaveCursorHistory() (string, error)          { return "recordings/demo.cursor.json", nil }
+func (f *fakeRecorder) SaveProject() (string, error)                { return "", nil }
+
+func newTestServer(t *testing.T) *Server {
+	t.Helper()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	s, err := New(func() (*config.Config, error) { return config.NewConfig(), nil }, logger)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	s.newRecorder = func(*config.Config, *slog.Logger) recorder { return &fakeRecorder{} }
+	s.runEdit = func(ctx context.Context, _ *config.Config, _ editing.Request) error { return nil }
+	return s
+}
+
+func do(t *testing.T, h http.Handler, method, target, token, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func errorOf(t *testing.T, rec *httptest.ResponseRecorder) string {
+	t.Helper()
+	var body struct {
+		Error string `json:"error"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("response %q is not a JSON error: %v", rec.Body.String(), err)
+	}
+	return body.Error
+}
+
+// writeRecording makes an input file and cursor sidecar that POST /edits accepts
+func writeRecording(t *testing.T) string {
+	t.Helper()
+	input := filepath.Join(t.TempDir(), "demo.mp4")
+	if err := os.WriteFile(input, []byte("video"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	history := []tracking.CursorPosition{{X: 1, Y: 2}, {X: 3, Y: 4, ClickTimeStamp: 100 * time.Millisecond}}
+	if err := tracking.SaveHistory(tracking.HistoryPath(input), time.Now(), history); err != nil {
+		t.Fatal(err)
+	}
+	return input
+}
+
+func TestAuthentication(t *testing.T) {
+	s := newTestServer(t)
+	h := s.Handler()
+
+	tests := []struct {
+		name   string
+		header string
+		query  string
+		want   int
+	}{
+		{"no token", "", "", http.StatusUnauthorized},
+		{"wrong token", "Bearer nope", "", http.StatusUnauthorized},
+		{"not a bearer token", s.Token(), "", http.StatusUnauthorized},
+		{"wrong query token", "", "?token=nope", http.StatusUnauthorized},
+		// Past authentication, there is simply nothing recorded yet
+		{"header token", "Bearer " + s.Token(), "", http.StatusNotFound},
+		{"query token", "", "?token=" + s.Token(), http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/recordings/current/stats"+tt.query, nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+			if rec.Code != tt.want {
+				t.Fatalf("status = %d, want %d (%s)", rec.Code, tt.want, rec.Body.String())
+			}
+			if tt.want == http.StatusUnauthorized && errorOf(t, rec) == "" {
+				t.Error("401 response has no error message")
+			}
+		})
+	}
+}
+
+func TestRecordingLifecycle(t *testing.T) {
+	s := newTestServer(t)
+	h := s.Handler()
+	token := s.Token()
+
+	if rec := do(t, h, http.MethodDelete, "/recordings/current", token, ""); rec.Code != http.StatusNotFound {
+		t.Fatalf("DELETE before any recording: status = %d, want 404", rec.Code)
+	}
+	if rec := do(t, h, http.MethodPost, "/recordings", token, `{"name":"demo"}`); rec.Code != http.StatusCreated {
+		t.Fatalf("first POST: status = %d, want 201 (%s)", rec.Code, rec.Body.String())
+	}
+	rec := do(t, h, http.MethodPost, "/recordings", token, `{"name":"other"}`)
+	if rec.Code != http.StatusConflict {
+		t.Fatalf("second POST: status = %d, want 409 (%s)", rec.Code, rec.Body.String())
+	}
+	if !strings.Contains(errorOf(t, rec), "already in progress") {
+		t.Errorf("second POST error = %q", errorOf(t, rec))
+	}
+
+	if rec := do(t, h, http.MethodDelete, "/recordings/current", token, ""); rec.Code != http.StatusOK {
+		t.Fatalf("DELETE: status = %d, want 200 (%s)", rec.Code, rec.Body.String())
+	}
+	if rec := do(t, h, http.MethodDelete, "/recordings/current", token, ""); rec.Code != http.StatusNotFound {
+		t.Fatalf("DELETE after stopping: status = %d, want 404", rec.Code)
+	}
+	// A finished recording can be followed by a new one
+	if rec := do(t, h, http.MethodPost, "/recordings", token, `{"name":"again"}`); rec.Code != http.StatusCreated {
+		t.Fatalf("POST after stopping: status = %d, want 201 (%s)", rec.Code, rec.Body.String())
+	}
+}
+
+// slowRecorder takes until finish is closed to stop
+type slowRecorder struct {
+	fakeRecorder
+	finish chan struct{}
+}
+
+func (f *slowRecorder) Stop() error {
+	<-f.finish
+	return f.fakeRecorder.Stop()
+}
+
+func TestStopRecordingOnce(t *testing.T) {
+	s := newTestServer(t)
+	slow := &slowRecorder{finish: make(chan struct{})}
+	s.newRecorder = func(*config.Config, *slog.Logger) recorder { return slow }
+	h := s.Handler()
+	token := s.Token()
+
+	if rec := do(t, h, http.MethodPost, "/recordings", token, `{"name":"demo"}`); rec.Code != http.StatusCreated {
+		t.Fatalf("POST: status = %d, want 201 (%s)", rec.Code, rec.Body.String())
+	}
+	first := make(chan int)
+	go func() { first <- do(t, h, http.MethodDelete, "/recordings/current", token, "").Code }()
+	for deadline := time.Now().Add(5 * time.Second); ; time.Sleep(time.Millisecond) {
+		s.mu.Lock()
+		stopping := s.stopping
+		s.mu.Unlock()
+		if stopping {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("the first DELETE never started stopping")
+		}
+	}
+
+	// While the first is finalizing, another stop is refused instead of racing it
+	if rec := do(t, h, http.MethodDelete, "/recordings/current", token, ""); rec.Code != http.StatusConflict {
+		t.Errorf("DELETE while stopping: status = %d, want 409", rec.Code)
+	}
+	s.stopAll() // Shutdown racing the request leaves it alone too
+
+	close(slow.finish)
+	if code := <-first; code != http.StatusOK {
+		t.Errorf("first DELETE: status = %d, want 200", code)
+	}
+	if rec := do(t, h, http.MethodDelete, "/recordings/current", token, ""); rec.Code != http.StatusNotFound {
+		t.Errorf("DELETE after stopping: status = %d, want 404", rec.Code)
+	}
+}
+
+func TestStartRecordingRejectsBadRequests(t *testing.T) {
+	s := newTestServer(t)
+	h := s.Handler()
+
+	for _, body := range []string{``, `{"name":`, `{"name":"demo","extra":1}`, `{"name":"../escape"}`} {
+		if rec := do(t, h, http.MethodPost, "/recordings", s.Token(), body); rec.Code != http.StatusBadRequest {
+			t.Errorf("body %q: status = %d, want 400", body, rec.Code)
+		}
+	}
+	if s.recorder != nil {
+		t.Error("a rejected request started a recording")
+	}
+}
+
+func TestEditProgress(t *testing.T) {
+	s := newTestServer(t)
+	h := s.Handler()
+	token := s.Token()
+
+	rec := do(t, h, http.MethodGet, "/edits/7/progress", token, "")
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("unknown edit: status = %d, want 404", rec.Code)
+	}
+	if !strings.Contains(errorOf(t, rec), `"7"`) {
+		t.Errorf("unknown edit error = %q, want it to name the id", errorOf(t, rec))
+	}
+
+	rec = do(t, h, http.MethodPost, "/edits", token, `{"input":"`+writeRecording(t)+`"}`)
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("POST /edits: status = %d, want 202 (%s)", rec.Code, rec.Body.String())
+	}
+	var status editStatus
+	if err := json.Unmarshal(rec.Body.Bytes(), &status); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(status.Output, "demo-edited.mp4") {
+		t.Errorf("output = %q, want the default edited path", status.Output)
+	}
+
+	// The stream ends with a single "done" event once the edit finishes
+	rec = do(t, h, http.MethodGet, "/edits/"+status.ID+"/progress", token, "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("progress: status = %d, want 200", rec.Code)
+	}
+	events := strings.Split(strings.TrimSpace(rec.Body.String()), "\n\n")
+	if last := events[len(events)-1]; !strings.HasPrefix(last, "event: done\n") {
+		t.Errorf("last event = %q, want done", last)
+	}
+
+	// Having delivered the result, the server forgets the edit
+	rec = do(t, h, http.MethodGet, "/edits/"+status.ID+"/progress", token, "")
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("progress after done: status = %d, want 404", rec.Code)
+	}
+}
+
+func TestUnwatchedEditsExpire(t *testing.T) {
+	s := newTestServer(t)
+	s.keepEdit = 50 * time.Millisecond
+	h := s.Handler()
+
+	rec := do(t, h, http.MethodPost, "/edits", s.Token(), `{"input":"`+writeRecording(t)+`"}`)
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("POST /edits: status = %d, want 202 (%s)", rec.Code, rec.Body.String())
+	}
+	for deadline := time.Now().Add(5 * time.Second); ; {
+		s.mu.Lock()
+		n := len(s.edits)
+		s.mu.Unlock()
+		if n == 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("finished edit never evicted")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestShutdownCancelsEdits(t *testing.T) {
+	s := newTestServer(t)
+	started := make(chan struct{})
+	s.runEdit = func(ctx context.Context, _ *config.Config, _ editing.Request) error {
+		close(started)
+		<-ctx.Done()
+		return ctx.Err()
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := listener.Addr().String()
+	listener.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	served := make(chan error, 1)
+	go func() { served <- s.ListenAndServe(ctx, addr) }()
+
+	body := `{"input":"` + writeRecording(t) + `"}`
+	var resp *http.Response
+	for deadline := time.Now().Add(5 * time.Second); ; {
+		req, _ := http.NewRequest(http.MethodPost, "http://"+addr+"/edits", strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+s.Token())
+		if resp, err = http.DefaultClient.Do(req); err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("server never came up: %v", err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusAccepted {
+		t.Fatalf("POST /edits: status = %d, want 202", resp.StatusCode)
+	}
+
+	select {
+	case <-started:
+	case <-time.After(5 * time.Second):
+		t.Fatal("edit never started")
+	}
+	cancel()
+	select {
+	case err := <-served:
+		if err != nil {
+			t.Fatalf("ListenAndServe: %v", err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("shutdown did not wait for, or did not cancel, the running edit")
+	}
+
+	s.mu.Lock()
+	e := s.edits["1"]
+	s.mu.Unlock()
+	status := e.status()
+	if !status.Done || !strings.Contains(status.Error, context.Canceled.Error()) {
+		t.Errorf("edit after shutdown = %+v, want done and cancelled", status)
+	}
+}
@@ -0,0 +1,36 @@
+//go:build !windows
+
+package session
+
+import (
+	"errors"
+	"os/exec"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+func processAlive(pid int) bool {
+	if pid <= 0 {
+		return false
+	}
+	err := syscall.Kill(pid, 0)
+	// EPERM means the process exists but belongs to someone else
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
+
+func processCommandLine(pid int) (string, error) {
+	output, err := exec.Command("ps", "-p", strconv.Itoa(pid), "-o", "command=").Output()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+func interruptProcess(pid int) error {
+	return syscall.Kill(pid, syscall.SIGINT)
+}
+
+func killProcess(pid int) error {
+	return syscall.Kill(pid, syscall.SIGKILL)
+}
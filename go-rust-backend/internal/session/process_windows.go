@@ -0,0 +1,39 @@
+package session
+
+import (
+	"errors"
+	"os"
+)
+
+var errUnsupported = errors.New("not supported on Windows")
+
+func processAlive(pid int) bool {
+	if pid <= 0 {
+		return false
+	}
+	// FindProcess opens a handle on Windows and fails for exited processes
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	p.Release()
+	return true
+}
+
+// processCommandLine is unavailable, so pids are never verified and orphaned
+// captures are reported rather than terminated
+func processCommandLine(pid int) (string, error) {
+	return "", errUnsupported
+}
+
+func interruptProcess(pid int) error {
+	return errUnsupported
+}
+
+func killProcess(pid int) error {
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return err
+	}
+	return p.Kill()
+}
@@ -0,0 +1,177 @@
+// Package session records the running capture on disk so a crashed run can
+// be detected and cleaned up on the next launch
+package session
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/vedantwpatil/Screen-Capture/internal/atomicfile"
+	"github.com/vedantwpatil/Screen-Capture/internal/ffmpegcmd"
+	"github.com/vedantwpatil/Screen-Capture/internal/media"
+)
+
+// State is what a recording leaves behind while it runs
+type State struct {
+	PID        int       `json:"pid"`        // FocusFrame process that started the capture
+	FFmpegPID  int       `json:"ffmpeg_pid"` // Capture process
+	OutputPath string    `json:"output_path"`
+	StartedAt  time.Time `json:"started_at"`
+}
+
+// Path is where the state file lives; it is per user, not per output directory,
+// so a crash is found even if the config changed since
+func Path() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("no cache directory for the session file: %w", err)
+	}
+	return filepath.Join(dir, "focusframe", "session.json"), nil
+}
+
+// Save writes the state file for a capture that just started
+func Save(state State) error {
+	path, err := Path()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create session directory: %w", err)
+	}
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	if err := atomicfile.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write session file: %w", err)
+	}
+	return nil
+}
+
+// Clear removes the state file after a clean stop
+func Clear() error {
+	path, err := Path()
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to remove session file: %w", err)
+	}
+	return nil
+}
+
+// Load returns the state left by a previous capture, or nil if there is none
+func Load() (*State, error) {
+	path, err := Path()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read session file: %w", err)
+	}
+	var state State
+	if err := json.Unmarshal(data, &state); err != nil {
+		// A truncated file from a crash mid-write carries nothing to recover
+		slog.Warn("Ignoring unreadable session file", "file", path, "err", err)
+		return nil, Clear()
+	}
+	return &state, nil
+}
+
+// OwnerRunning reports whether the FocusFrame process that wrote the state is
+// still alive, i.e. the capture is not orphaned at all
+func (s *State) OwnerRunning() bool {
+	if s.PID == os.Getpid() || !processAlive(s.PID) {
+		return false
+	}
+	// The pid may have been reused by an unrelated program since the crash
+	exe, err := os.Executable()
+	if err != nil {
+		return true
+	}
+	cmdline, err := processCommandLine(s.PID)
+	if err != nil {
+		return true
+	}
+	return strings.Contains(cmdline, filepath.Base(exe))
+}
+
+// FFmpegRunning reports whether the recorded capture process is still alive.
+// verified is false when the pid could not be confirmed to be our ffmpeg, in
+// which case it must not be signalled.
+func (s *State) FFmpegRunning() (alive, verified bool) {
+	if !processAlive(s.FFmpegPID) {
+		return false, false
+	}
+	cmdline, err := processCommandLine(s.FFmpegPID)
+	if err != nil {
+		return true, false
+	}
+	return true, strings.Contains(cmdline, "ffmpeg") && strings.Contains(cmdline, s.OutputPath)
+}
+
+// TerminateFFmpeg interrupts the orphaned capture, which makes ffmpeg write
+// the file trailer, and kills it if it has not exited within timeout
+func (s *State) TerminateFFmpeg(timeout time.Duration) error {
+	if err := interruptProcess(s.FFmpegPID); err != nil {
+		return fmt.Errorf("failed to interrupt ffmpeg (pid %d): %w", s.FFmpegPID, err)
+	}
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		if !processAlive(s.FFmpegPID) {
+			return nil
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	slog.Warn("ffmpeg did not exit after interrupt, killing it", "pid", s.FFmpegPID)
+	if err := killProcess(s.FFmpegPID); err != nil {
+		return fmt.Errorf("failed to kill ffmpeg (pid %d): %w", s.FFmpegPID, err)
+	}
+	return nil
+}
+
+// Salvage checks the partial recording. Captures are fragmented MP4, so one
+// cut short is normally readable and returned as is; otherwise its streams
+// are remuxed into <name>-recovered.mp4. An error means the file could not
+// be recovered.
+func Salvage(ctx context.Context, path string) (string, error) {
+	if _, err := os.Stat(path); err != nil {
+		return "", fmt.Errorf("partial recording is missing: %w", err)
+	}
+	if info, err := media.Probe(path); err == nil && info.VideoStreams > 0 {
+		return path, nil
+	}
+
+	recovered := strings.TrimSuffix(path, filepath.Ext(path)) + "-recovered.mp4"
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Minute)
+	defer cancel()
+	args := ffmpegcmd.New().
+		Global("-v", "error").
+		Input(path).
+		Output(recovered, media.EncodeOptions{}, "-c", "copy").
+		Args()
+	cmd := exec.CommandContext(ctx, "ffmpeg", args...)
+	slog.Debug("Running ffmpeg", "args", cmd.Args)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		os.Remove(recovered)
+		return "", fmt.Errorf("recording %s is unrecoverable: %w: %s", path, err, strings.TrimSpace(string(output)))
+	}
+	if _, err := media.Probe(recovered); err != nil {
+		os.Remove(recovered)
+		return "", fmt.Errorf("recording %s is unrecoverable: %w", path, err)
+	}
+	return recovered, nil
+}
@@ -0,0 +1,137 @@
+//go:build !windows
+
+package session
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestHelperProcess stands in for a FocusFrame process when run by
+// startSelf; on its own it does nothing
+func TestHelperProcess(t *testing.T) {
+	if os.Getenv("FOCUSFRAME_SESSION_HELPER") != "1" {
+		return
+	}
+	time.Sleep(time.Minute)
+	os.Exit(0)
+}
+
+// start runs cmd until the test ends
+func start(t *testing.T, cmd *exec.Cmd) int {
+	t.Helper()
+	if err := cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		cmd.Process.Kill()
+		cmd.Wait()
+	})
+	return cmd.Process.Pid
+}
+
+// startSelf starts another copy of the test binary, which looks like FocusFrame
+func startSelf(t *testing.T) int {
+	exe, err := os.Executable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cmd := exec.Command(exe, "-test.run=^TestHelperProcess$")
+	cmd.Env = append(os.Environ(), "FOCUSFRAME_SESSION_HELPER=1")
+	return start(t, cmd)
+}
+
+// startFakeFFmpeg starts a script named ffmpeg with output among its arguments
+func startFakeFFmpeg(t *testing.T, output string) int {
+	script := filepath.Join(t.TempDir(), "ffmpeg")
+	if err := os.WriteFile(script, []byte("#!/bin/sh\nwhile :; do sleep 0.1; done\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	return start(t, exec.Command(script, "-f", "avfoundation", "-i", "1:none", output))
+}
+
+// startForeign starts an unrelated program, as if it had reused a pid
+func startForeign(t *testing.T) int {
+	return start(t, exec.Command("sleep", "60"))
+}
+
+// exitedPid returns the pid of a process that has already exited
+func exitedPid(t *testing.T) int {
+	cmd := exec.Command("true")
+	if err := cmd.Run(); err != nil {
+		t.Fatal(err)
+	}
+	return cmd.Process.Pid
+}
+
+func TestOwnerRunning(t *testing.T) {
+	tests := []struct {
+		name string
+		pid  func(t *testing.T) int
+		want bool
+	}{
+		{"another FocusFrame", startSelf, true},
+		{"pid reused by another program", startForeign, false},
+		{"exited", exitedPid, false},
+		{"this process", func(*testing.T) int { return os.Getpid() }, false},
+		{"no pid", func(*testing.T) int { return 0 }, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state := State{PID: tt.pid(t), OutputPath: "output/demo.mp4"}
+			if got := state.OwnerRunning(); got != tt.want {
+				t.Errorf("OwnerRunning() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFFmpegRunning(t *testing.T) {
+	output := filepath.Join(t.TempDir(), "demo.mp4")
+	tests := []struct {
+		name                  string
+		pid                   func(t *testing.T) int
+		output                string
+		wantAlive, wantVerify bool
+	}{
+		{"our capture", func(t *testing.T) int { return startFakeFFmpeg(t, output) }, output, true, true},
+		// Alive, but must not be signalled
+		{"pid reused by another program", startForeign, output, true, false},
+		{"ffmpeg writing another file", func(t *testing.T) int { return startFakeFFmpeg(t, output) }, "output/other.mp4", true, false},
+		{"exited", exitedPid, output, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state := State{PID: os.Getpid(), FFmpegPID: tt.pid(t), OutputPath: tt.output}
+			alive, verified := state.FFmpegRunning()
+			if alive != tt.wantAlive || verified != tt.wantVerify {
+				t.Errorf("FFmpegRunning() = %v, %v; want %v, %v", alive, verified, tt.wantAlive, tt.wantVerify)
+			}
+		})
+	}
+}
+
+func TestTerminateFFmpeg(t *testing.T) {
+	output := filepath.Join(t.TempDir(), "demo.mp4")
+	cmd := exec.Command("sh", "-c", "trap 'exit 0' INT; while :; do sleep 0.1; done", "ffmpeg", output)
+	pid := start(t, cmd)
+	// Reap the process as soon as it exits so it doesn't linger as a zombie
+	exited := make(chan struct{})
+	go func() {
+		cmd.Process.Wait()
+		close(exited)
+	}()
+
+	state := State{FFmpegPID: pid, OutputPath: output}
+	if err := state.TerminateFFmpeg(5 * time.Second); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-exited:
+	case <-time.After(5 * time.Second):
+		t.Fatal("ffmpeg is still running")
+	}
+}
@@ -0,0 +1,67 @@
+package timeline
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/vedantwpatil/Screen-Capture/internal/atomicfile"
+	"github.com/vedantwpatil/Screen-Capture/internal/ffmpegcmd"
+	"github.com/vedantwpatil/Screen-Capture/internal/media"
+	"github.com/vedantwpatil/Screen-Capture/internal/tracking"
+)
+
+// Export concatenates the clips into outputPath and writes the merged cursor
+// data next to it, so the result can be edited like any recording. Clips
+// are scaled to fit the first clip's frame (with bars where the aspect
+// differs) at a common frame rate. Recordings carry no audio, so neither
+// does the result. encode should be lossless or near it, since the edit
+// encodes the video again.
+func Export(ctx context.Context, t *Timeline, outputPath string, encode media.EncodeOptions) error {
+	if err := t.Validate(); err != nil {
+		return err
+	}
+	width, height, fps := t.Format()
+
+	cmd := ffmpegcmd.New()
+	var graph strings.Builder
+	for i, c := range t.Clips {
+		// Seeking before the input is fast, and exact since every frame is
+		// decoded again
+		cmd.Input(c.Path, "-ss", seconds(c.In), "-t", seconds(c.Length()))
+		fmt.Fprintf(&graph,
+			"[%d:v:0]scale=%d:%d:force_original_aspect_ratio=decrease,pad=%d:%d:(ow-iw)/2:(oh-ih)/2,fps=%g,setsar=1,format=yuv420p[v%d];",
+			i, width, height, width, height, fps, i)
+	}
+	for i := range t.Clips {
+		fmt.Fprintf(&graph, "[v%d]", i)
+	}
+	fmt.Fprintf(&graph, "concat=n=%d:v=1:a=0[out]", len(t.Clips))
+
+	partial := atomicfile.PartialPath(outputPath)
+	args := cmd.FilterComplex(graph.String()).
+		Map("[out]").
+		Output(partial, encode, "-an").
+		Args()
+	slog.Info("Stitching clips", "clips", len(t.Clips), "length", t.Length(), "output", outputPath)
+	if err := ffmpegcmd.Render(ctx, args, partial, outputPath); err != nil {
+		if ctx.Err() != nil {
+			return err
+		}
+		return fmt.Errorf("failed to stitch clips: %w", err)
+	}
+
+	history := t.CursorHistory()
+	if err := tracking.SaveHistory(tracking.HistoryPath(outputPath), t.Clips[0].StartTime, history); err != nil {
+		return err
+	}
+	return nil
+}
+
+// seconds formats d for ffmpeg's time options
+func seconds(d time.Duration) string {
+	return strconv.FormatFloat(d.Seconds(), 'f', 3, 64)
+}
@@ -0,0 +1,251 @@
+// Package timeline stitches several recordings (takes) into one: the clips
+// are trimmed, normalized to one frame size and rate, and concatenated, and
+// their cursor histories are merged onto the stitched video's clock so
+// click effects work across clip boundaries.
+package timeline
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/vedantwpatil/Screen-Capture/internal/media"
+	"github.com/vedantwpatil/Screen-Capture/internal/project"
+	"github.com/vedantwpatil/Screen-Capture/internal/tracking"
+)
+
+// Clip is one take on the timeline
+type Clip struct {
+	Path      string
+	History   []tracking.CursorPosition
+	StartTime time.Time // Wall-clock start of the capture
+	Info      media.Info
+
+	// In and Out trim the clip; Out zero keeps everything after In
+	In  time.Duration
+	Out time.Duration
+}
+
+// LoadClip reads a recording with its cursor sidecar, or a .focusframe
+// project, untrimmed
+func LoadClip(path string) (Clip, error) {
+	videoPath, cursorPath := path, tracking.HistoryPath(path)
+	if project.IsBundle(path) {
+		p, err := project.Load(path)
+		if err != nil {
+			return Clip{}, err
+		}
+		videoPath, cursorPath = p.VideoPath, p.CursorPath
+	}
+	history, startTime, err := tracking.LoadHistory(cursorPath)
+	if err != nil {
+		return Clip{}, err
+	}
+	info, err := media.Probe(videoPath)
+	if err != nil {
+		return Clip{}, err
+	}
+	return Clip{Path: videoPath, History: history, StartTime: startTime, Info: info}, nil
+}
+
+// Name is the clip's file name without extension, for listings
+func (c Clip) Name() string {
+	return strings.TrimSuffix(filepath.Base(c.Path), filepath.Ext(c.Path))
+}
+
+// End is where the clip stops in its own recording
+func (c Clip) End() time.Duration {
+	if c.Out <= 0 || c.Out > c.Info.Duration {
+		return c.Info.Duration
+	}
+	return c.Out
+}
+
+// Length is how long the clip lasts on the timeline
+func (c Clip) Length() time.Duration {
+	return max(c.End()-c.In, 0)
+}
+
+// Validate checks the trim points against the recording
+func (c Clip) Validate() error {
+	switch {
+	case c.Info.Width <= 0 || c.Info.Height <= 0:
+		return fmt.Errorf("%s: no video stream", c.Name())
+	case c.In < 0 || c.Out < 0:
+		return fmt.Errorf("%s: trim points must not be negative", c.Name())
+	case c.Out > 0 && c.Out <= c.In:
+		return fmt.Errorf("%s: trim end %s must come after its start %s", c.Name(), c.Out, c.In)
+	case c.In >= c.Info.Duration:
+		return fmt.Errorf("%s: trim start %s is past the end of the %s recording", c.Name(), c.In, c.Info.Duration.Round(time.Millisecond))
+	}
+	return nil
+}
+
+// ParseTrim reads a trim such as "5s-1m20s", "5s-" (to the end) or "-1m"
+// (from the start)
+func ParseTrim(value string) (in, out time.Duration, err error) {
+	from, to, ok := strings.Cut(value, "-")
+	if !ok {
+		return 0, 0, fmt.Errorf("invalid trim %q, expected e.g. 5s-1m20s, 5s- or -1m", value)
+	}
+	parse := func(s string) (time.Duration, error) {
+		if s == "" {
+			return 0, nil
+		}
+		d, err := time.ParseDuration(s)
+		if err != nil || d < 0 {
+			return 0, fmt.Errorf("invalid trim %q, expected e.g. 5s-1m20s, 5s- or -1m", value)
+		}
+		return d, nil
+	}
+	if in, err = parse(from); err != nil {
+		return 0, 0, err
+	}
+	if out, err = parse(to); err != nil {
+		return 0, 0, err
+	}
+	if out > 0 && out <= in {
+		return 0, 0, fmt.Errorf("invalid trim %q: the end must come after the start", value)
+	}
+	return in, out, nil
+}
+
+// Timeline is an ordered list of clips exported as one video
+type Timeline struct {
+	Clips []Clip
+}
+
+// Validate checks there is something to export and every clip is usable
+func (t *Timeline) Validate() error {
+	if len(t.Clips) == 0 {
+		return errors.New("the timeline has no clips")
+	}
+	var problems []error
+	for _, c := range t.Clips {
+		problems = append(problems, c.Validate())
+	}
+	return errors.Join(problems...)
+}
+
+// Length is the duration of the stitched video
+func (t *Timeline) Length() time.Duration {
+	var total time.Duration
+	for _, c := range t.Clips {
+		total += c.Length()
+	}
+	return total
+}
+
+// Format is the frame size and rate every clip is normalized to: the first
+// clip's size, rounded down to even, at the highest clip frame rate
+func (t *Timeline) Format() (width int, height int, fps float64) {
+	first := t.Clips[0].Info
+	width, height = first.Width&^1, first.Height&^1
+	for _, c := range t.Clips {
+		fps = max(fps, c.Info.FPS)
+	}
+	if fps <= 0 {
+		fps = 30
+	}
+	return width, height, math.Round(fps)
+}
+
+// fit maps a clip's pixels onto the output frame: scaled to fit, then
+// centred with bars, as the export's scale and pad filters do
+func fit(info media.Info, width int, height int) (scale float64, offset tracking.Vec2) {
+	scale = math.Min(float64(width)/float64(info.Width), float64(height)/float64(info.Height))
+	offset = tracking.Vec2{
+		X: math.Floor((float64(width) - float64(info.Width)*scale) / 2),
+		Y: math.Floor((float64(height) - float64(info.Height)*scale) / 2),
+	}
+	return scale, offset
+}
+
+// CursorHistory merges the clips' cursor data onto the stitched video's
+// clock. Samples outside a clip's trim are dropped, the rest are shifted by
+// the length of the clips before it and mapped onto the output frame. Each
+// clip gets a sample where it starts and ends on the timeline, so the cursor
+// holds its position up to a cut and then jumps, rather than drifting
+// towards the next clip's first sample.
+func (t *Timeline) CursorHistory() []tracking.CursorPosition {
+	if len(t.Clips) == 0 {
+		return nil
+	}
+	width, height, _ := t.Format()
+	var merged []tracking.CursorPosition
+	var offset time.Duration
+	for _, c := range t.Clips {
+		moves, clicks := splitByKind(c.History)
+		if len(moves) == 0 {
+			offset += c.Length()
+			continue
+		}
+		scale, pad := fit(c.Info, width, height)
+		place := func(p tracking.CursorPosition, at time.Duration, pos tracking.Vec2) tracking.CursorPosition {
+			pos = pos.Scale(scale).Add(pad)
+			p.X = int16(math.Round(pos.X))
+			p.Y = int16(math.Round(pos.Y))
+			p.ClickTimeStamp = offset + at - c.In
+			return p
+		}
+
+		in, end := c.In, c.End()
+		merged = append(merged, place(moves[0], in, positionAt(moves, in)))
+		for _, p := range moves {
+			if p.ClickTimeStamp > in && p.ClickTimeStamp < end {
+				merged = append(merged, place(p, p.ClickTimeStamp, p.Position()))
+			}
+		}
+		merged = append(merged, place(moves[len(moves)-1], end, positionAt(moves, end)))
+		for _, p := range clicks {
+			if p.ClickTimeStamp >= in && p.ClickTimeStamp < end {
+				merged = append(merged, place(p, p.ClickTimeStamp, p.Position()))
+			}
+		}
+		offset += c.Length()
+	}
+	// Clicks were appended per clip after its movement; boundary samples that
+	// share a time keep their order, so the outgoing clip's end comes first
+	sort.SliceStable(merged, func(i, j int) bool { return merged[i].ClickTimeStamp < merged[j].ClickTimeStamp })
+	return merged
+}
+
+// splitByKind separates movement samples from clicks, each sorted by time
+func splitByKind(history []tracking.CursorPosition) (moves, clicks []tracking.CursorPosition) {
+	for _, p := range history {
+		if p.Click {
+			clicks = append(clicks, p)
+		} else {
+			moves = append(moves, p)
+		}
+	}
+	byTime := func(events []tracking.CursorPosition) func(i, j int) bool {
+		return func(i, j int) bool { return events[i].ClickTimeStamp < events[j].ClickTimeStamp }
+	}
+	sort.SliceStable(moves, byTime(moves))
+	sort.SliceStable(clicks, byTime(clicks))
+	return moves, clicks
+}
+
+// positionAt linearly interpolates the movement samples at t, holding the
+// first and last positions outside them
+func positionAt(moves []tracking.CursorPosition, t time.Duration) tracking.Vec2 {
+	i := sort.Search(len(moves), func(i int) bool { return moves[i].ClickTimeStamp >= t })
+	switch {
+	case i == 0:
+		return moves[0].Position()
+	case i == len(moves):
+		return moves[len(moves)-1].Position()
+	}
+	prev, next := moves[i-1], moves[i]
+	span := next.ClickTimeStamp - prev.ClickTimeStamp
+	if span <= 0 {
+		return next.Position()
+	}
+	frac := float64(t-prev.ClickTimeStamp) / float64(span)
+	return prev.Position().Add(next.Position().Sub(prev.Position()).Scale(frac))
+}
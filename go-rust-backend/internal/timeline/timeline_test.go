@@ -0,0 +1,142 @@
+package timeline
+
+import (
+	"testing"
+	"time"
+
+	"github.com/vedantwpatil/Screen-Capture/internal/media"
+	"github.com/vedantwpatil/Screen-Capture/internal/tracking"
+)
+
+func move(secs float64, x, y int16) tracking.CursorPosition {
+	return tracking.CursorPosition{X: x, Y: y, ClickTimeStamp: time.Duration(secs * float64(time.Second))}
+}
+
+func click(secs float64, x, y int16) tracking.CursorPosition {
+	p := move(secs, x, y)
+	p.Click = true
+	return p
+}
+
+func info(width, height int, secs float64) media.Info {
+	return media.Info{Width: width, Height: height, FPS: 30, Duration: time.Duration(secs * float64(time.Second))}
+}
+
+func TestCursorHistory(t *testing.T) {
+	tl := Timeline{Clips: []Clip{
+		{
+			Path:    "a.mp4",
+			Info:    info(1920, 1080, 10),
+			History: []tracking.CursorPosition{move(0, 100, 100), click(2, 300, 100), move(5, 600, 100), move(10, 1100, 100)},
+		},
+		{
+			// Half size, so scaled up 2x; trimmed to 2s-6s
+			Path: "b.mp4",
+			Info: info(960, 540, 10),
+			In:   2 * time.Second,
+			Out:  6 * time.Second,
+			History: []tracking.CursorPosition{
+				move(0, 0, 0), click(1, 100, 0), click(3, 300, 0), move(4, 400, 0), click(6, 600, 0), move(8, 800, 0),
+			},
+		},
+		{
+			// Clicks without movement can't be placed; the clip still takes its time
+			Path:    "c.mp4",
+			Info:    info(1920, 1080, 1),
+			History: []tracking.CursorPosition{click(0.5, 10, 10)},
+		},
+		{
+			// Square, so centred with 420px bars either side
+			Path:    "d.mp4",
+			Info:    info(1080, 1080, 2),
+			History: []tracking.CursorPosition{move(2, 100, 100), move(0, 0, 0)},
+		},
+	}}
+
+	want := []tracking.CursorPosition{
+		// a: its first sample where it starts, then everything, then its end
+		move(0, 100, 100),
+		click(2, 300, 100),
+		move(5, 600, 100),
+		move(10, 1100, 100),
+		// b starts at 10s, at its position 2s in; the click at 1s is trimmed
+		// away and the one at 6s falls on the trim end
+		move(10, 400, 0),
+		click(11, 600, 0),
+		move(12, 800, 0),
+		move(14, 1200, 0),
+		// c adds 1s and no samples; d is placed after it
+		move(15, 420, 0),
+		move(17, 520, 100),
+	}
+
+	got := tl.CursorHistory()
+	if len(got) != len(want) {
+		t.Fatalf("got %d samples, want %d:\n%v", len(got), len(want), got)
+	}
+	for i := range want {
+		g, w := got[i], want[i]
+		if g.X != w.X || g.Y != w.Y || g.ClickTimeStamp != w.ClickTimeStamp || g.Click != w.Click {
+			t.Errorf("sample %d = (%d, %d) at %v click %v; want (%d, %d) at %v click %v",
+				i, g.X, g.Y, g.ClickTimeStamp, g.Click, w.X, w.Y, w.ClickTimeStamp, w.Click)
+		}
+	}
+	if end := got[len(got)-1].ClickTimeStamp; end != tl.Length() {
+		t.Errorf("last sample at %v, want the timeline's end %v", end, tl.Length())
+	}
+}
+
+func TestCursorHistoryInterpolatesTrimPoints(t *testing.T) {
+	// No samples fall inside the trim; the cursor is placed where it was
+	tl := Timeline{Clips: []Clip{{
+		Path:    "a.mp4",
+		Info:    info(1920, 1080, 20),
+		In:      5 * time.Second,
+		Out:     15 * time.Second,
+		History: []tracking.CursorPosition{move(1, 50, 60), move(18, 500, 600)},
+	}}}
+	got := tl.CursorHistory()
+	if len(got) != 2 {
+		t.Fatalf("got %v, want the start and end samples only", got)
+	}
+	// Interpolated between the samples either side of each trim point
+	if got[0].ClickTimeStamp != 0 || got[0].X != 156 || got[0].Y != 187 {
+		t.Errorf("start = (%d, %d) at %v, want (156, 187) at 0", got[0].X, got[0].Y, got[0].ClickTimeStamp)
+	}
+	if got[1].ClickTimeStamp != 10*time.Second || got[1].X != 421 || got[1].Y != 505 {
+		t.Errorf("end = (%d, %d) at %v, want (421, 505) at 10s", got[1].X, got[1].Y, got[1].ClickTimeStamp)
+	}
+}
+
+func TestCursorHistoryEmpty(t *testing.T) {
+	if got := (&Timeline{}).CursorHistory(); got != nil {
+		t.Errorf("empty timeline gave %v", got)
+	}
+	tl := Timeline{Clips: []Clip{{Path: "a.mp4", Info: info(1920, 1080, 5)}}}
+	if got := tl.CursorHistory(); len(got) != 0 {
+		t.Errorf("clip without cursor data gave %v", got)
+	}
+}
+
+func TestParseTrim(t *testing.T) {
+	tests := []struct {
+		value   string
+		in, out time.Duration
+		wantErr bool
+	}{
+		{"5s-1m20s", 5 * time.Second, 80 * time.Second, false},
+		{"5s-", 5 * time.Second, 0, false},
+		{"-1m", 0, time.Minute, false},
+		{"-", 0, 0, false},
+		{"5s", 0, 0, true},
+		{"10s-5s", 0, 0, true},
+		{"5s-5s", 0, 0, true},
+		{"abc-1m", 0, 0, true},
+	}
+	for _, tt := range tests {
+		in, out, err := ParseTrim(tt.value)
+		if (err != nil) != tt.wantErr || in != tt.in || out != tt.out {
+			t.Errorf("ParseTrim(%q) = %v, %v, %v; want %v, %v, error %v", tt.value, in, out, err, tt.in, tt.out, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package tracking
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/vedantwpatil/Screen-Capture/internal/atomicfile"
+	"github.com/vedantwpatil/Screen-Capture/internal/cursorshape"
+)
+
+// historyVersion is bumped whenever the sidecar layout changes incompatibly
+const historyVersion = 1
+
+// historyFile is the JSON sidecar written next to each recording
+type historyFile struct {
+	Version   int             `json:"version"`
+	StartedAt time.Time       `json:"started_at"`
+	Samples   []historySample `json:"samples"`
+}
+
+type historySample struct {
+	X      int16   `json:"x"`
+	Y      int16   `json:"y"`
+	TimeMS float64 `json:"t_ms"` // Milliseconds since the recording started
+	Click  bool    `json:"click,omitempty"`
+	Button uint16  `json:"button,omitempty"`
+	Shape  string  `json:"shape,omitempty"` // Empty for the arrow, so older sidecars read as all arrow
+}
+
+// HistoryPath returns the sidecar path for a recording, e.g. demo.cursor.json for demo.mp4
+func HistoryPath(videoPath string) string {
+	return strings.TrimSuffix(videoPath, filepath.Ext(videoPath)) + ".cursor.json"
+}
+
+// SaveHistory writes the cursor samples captured during a recording
+func SaveHistory(path string, startedAt time.Time, positions []CursorPosition) error {
+	file := historyFile{
+		Version:   historyVersion,
+		StartedAt: startedAt,
+		Samples:   make([]historySample, 0, len(positions)),
+	}
+	for _, p := range positions {
+		sample := historySample{
+			X:      p.X,
+			Y:      p.Y,
+			TimeMS: float64(p.ClickTimeStamp) / float64(time.Millisecond),
+			Click:  p.Click,
+			Button: p.Button,
+		}
+		if p.Shape != cursorshape.Arrow {
+			sample.Shape = p.Shape.String()
+		}
+		file.Samples = append(file.Samples, sample)
+	}
+
+	data, err := json.Marshal(file)
+	if err != nil {
+		return fmt.Errorf("failed to encode cursor history: %w", err)
+	}
+	if err := atomicfile.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write cursor history: %w", err)
+	}
+	return nil
+}
+
+// LoadHistory reads a sidecar written by SaveHistory
+func LoadHistory(path string) ([]CursorPosition, time.Time, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, time.Time{}, fmt.Errorf("failed to read cursor history: %w", err)
+	}
+
+	var file historyFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, time.Time{}, fmt.Errorf("failed to parse cursor history %s: %w", path, err)
+	}
+	if file.Version != historyVersion {
+		return nil, time.Time{}, fmt.Errorf("cursor history %s has unsupported version %d", path, file.Version)
+	}
+
+	positions := make([]CursorPosition, 0, len(file.Samples))
+	for i, s := range file.Samples {
+		shape, err := cursorshape.Parse(s.Shape)
+		if err != nil {
+			return nil, time.Time{}, fmt.Errorf("cursor history %s, sample %d: %w", path, i, err)
+		}
+		positions = append(positions, CursorPosition{
+			X:              s.X,
+			Y:              s.Y,
+			ClickTimeStamp: time.Duration(s.TimeMS * float64(time.Millisecond)),
+			Click:          s.Click,
+			Button:         s.Button,
+			Shape:          shape,
+		})
+	}
+	return positions, file.StartedAt, nil
+}
+
+// ScaleHistory maps cursor samples onto a frame sx and sy times the size of
+// the one they were recorded in, e.g. a scaled-down copy of the video
+func ScaleHistory(history []CursorPosition, sx, sy float64) []CursorPosition {
+	scaled := make([]CursorPosition, len(history))
+	for i, p := range history {
+		p.X = int16(math.Round(float64(p.X) * sx))
+		p.Y = int16(math.Round(float64(p.Y) * sy))
+		scaled[i] = p
+	}
+	return scaled
+}
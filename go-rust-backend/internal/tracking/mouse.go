@@ -2,38 +2,77 @@ package tracking
 
 import (
 	"context"
-	"fmt"
+	"errors"
+	"log/slog"
+	"sync"
 	"time"
 
 	"github.com/go-vgo/robotgo"
 	hook "github.com/robotn/gohook"
+	"github.com/vedantwpatil/Screen-Capture/internal/cursorshape"
+	"github.com/vedantwpatil/Screen-Capture/internal/platform"
 )
 
-// Captures the mouse position and times when the mouse is clicked
-func StartMouseTracking(mouseEvents *[]CursorPosition, startingTime time.Time, targetFPS int, ctx context.Context) {
+// ErrTrackerBusy is returned when another tracker is already listening for
+// clicks; gohook's event hook is process-wide, so only one can run at a time
+var ErrTrackerBusy = errors.New("another cursor tracker is already running")
+
+// hookMu guards gohook's global registration and event loop
+var hookMu sync.Mutex
+
+// shapeCheckPeriod limits cursor shape lookups, which compare cursor images
+// on macOS; samples in between carry the last shape seen
+const shapeCheckPeriod = 100 * time.Millisecond
+
+// Tracker captures the mouse position sampleHz times a second and times when
+// the mouse is clicked. Samples carry their real timestamps, so the sampling
+// rate does not have to match the video frame rate.
+type Tracker struct {
+	logger   *slog.Logger
+	sampleHz int
+
+	mu      sync.Mutex
+	samples []CursorPosition
+	shape   cursorshape.Shape // Latest cursor shape, also given to clicks
+}
+
+func NewTracker(logger *slog.Logger, sampleHz int) *Tracker {
+	return &Tracker{logger: logger, sampleHz: sampleHz}
+}
+
+// Run tracks until ctx is cancelled, timing samples from startingTime. The
+// click hook is torn down before Run returns, so it can be called again.
+func (t *Tracker) Run(ctx context.Context, startingTime time.Time) error {
+	if !hookMu.TryLock() {
+		return ErrTrackerBusy
+	}
+	defer hookMu.Unlock()
+
 	// Register mouse location
+	var wg sync.WaitGroup
+	wg.Add(1)
 	go func() {
-		mousePos := CursorPosition{}
+		defer wg.Done()
+		ticker := time.NewTicker(time.Second / time.Duration(t.sampleHz))
+		defer ticker.Stop()
+
+		var shapeChecked time.Time
 		for {
 			select {
-
 			case <-ctx.Done():
-				fmt.Println("Mouse location tracking stopped...")
+				t.logger.Debug("Mouse location tracking stopped")
 				return
-			default:
+			case now := <-ticker.C:
+				if now.Sub(shapeChecked) >= shapeCheckPeriod {
+					shapeChecked = now
+					t.setShape(platform.CursorShape())
+				}
 				xMouse, yMouse := robotgo.Location()
-
-				currentTime := time.Now()
-				elapsedTime := currentTime.Sub(startingTime)
-
-				mousePos.X = int16(xMouse)
-				mousePos.Y = int16(yMouse)
-
-				mousePos.ClickTimeStamp = elapsedTime
-				*mouseEvents = append(*mouseEvents, mousePos)
-
-				// To capture mouse location only at every frame
-				time.Sleep(1 * time.Second / time.Duration(targetFPS))
+				t.add(CursorPosition{
+					X:              int16(xMouse),
+					Y:              int16(yMouse),
+					ClickTimeStamp: time.Since(startingTime),
+				})
 			}
 		}
 	}()
@@ -41,27 +80,53 @@ func StartMouseTracking(mouseEvents *[]CursorPosition, startingTime time.Time, t
 	// Register mouse click times
 	hook.Register(hook.MouseDown, []string{}, func(e hook.Event) {
 		if e.Button == hook.MouseMap["left"] || e.Button == 1 {
-
-			currentTime := time.Now()
-			elapsedTime := currentTime.Sub(startingTime)
+			elapsedTime := time.Since(startingTime)
 
 			// Log click events
-			fmt.Printf("Click detected at position (%d, %d) with timestamp: %v\n", e.X, e.Y, elapsedTime)
+			t.logger.Debug("Click detected", "x", e.X, "y", e.Y, "timestamp", elapsedTime)
 
-			clickEvent := CursorPosition{
+			t.add(CursorPosition{
 				X:              e.X,
 				Y:              e.Y,
 				ClickTimeStamp: elapsedTime,
-			}
-			*mouseEvents = append(*mouseEvents, clickEvent)
+				Click:          true,
+				Button:         e.Button,
+			})
 		}
 	})
 
 	evChan := hook.Start()
+	processed := hook.Process(evChan)
+	t.logger.Debug("Hook process started. Waiting for events...")
+
+	<-ctx.Done()
+	// End also clears every registered callback, so the next Run starts clean
+	hook.End()
+	<-processed
+	wg.Wait()
 
-	fmt.Println("Hook process started. Waiting for events...")
-	// Start processing events. This blocks until hook.End() is called.
-	<-hook.Process(evChan)
+	t.logger.Debug("Hook process stopped")
+	return nil
+}
+
+// Samples returns a copy of everything tracked so far
+func (t *Tracker) Samples() []CursorPosition {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	samples := make([]CursorPosition, len(t.samples))
+	copy(samples, t.samples)
+	return samples
+}
+
+func (t *Tracker) add(p CursorPosition) {
+	t.mu.Lock()
+	p.Shape = t.shape
+	t.samples = append(t.samples, p)
+	t.mu.Unlock()
+}
 
-	fmt.Println("Hook process stopped.")
+func (t *Tracker) setShape(shape cursorshape.Shape) {
+	t.mu.Lock()
+	t.shape = shape
+	t.mu.Unlock()
 }
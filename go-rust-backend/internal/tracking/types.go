@@ -1,6 +1,10 @@
 package tracking
 
-import "time"
+import (
+	"time"
+
+	"github.com/vedantwpatil/Screen-Capture/internal/cursorshape"
+)
 
 // MouseEvent holds information about a mouse click event during recording.
 // Exported fields (starting with uppercase) allow access from other packages.
@@ -9,6 +13,37 @@ type CursorPosition struct {
 	Y              int16         // Y coordinate of the mouse click
 	ClickTimeStamp time.Duration // Time elapsed since recording started
 	Velocity       float64
+	Click          bool   // Set for button presses; movement samples leave it false
+	Button         uint16 // Mouse button for clicks, as reported by the hook
+	Shape          cursorshape.Shape
+}
+
+// Position returns the cursor coordinates as a float vector for smoothing math.
+// Captured coordinates are whole pixels, but anything derived from them
+// (spline coefficients, spring integration) must stay fractional.
+func (p CursorPosition) Position() Vec2 {
+	return Vec2{X: float64(p.X), Y: float64(p.Y)}
+}
+
+// Vec2 is a 2D point or direction in floating point pixel space.
+type Vec2 struct {
+	X float64
+	Y float64
+}
+
+// Add returns the component-wise sum of two vectors.
+func (v Vec2) Add(o Vec2) Vec2 {
+	return Vec2{X: v.X + o.X, Y: v.Y + o.Y}
+}
+
+// Sub returns the component-wise difference v - o.
+func (v Vec2) Sub(o Vec2) Vec2 {
+	return Vec2{X: v.X - o.X, Y: v.Y - o.Y}
+}
+
+// Scale multiplies both components by s.
+func (v Vec2) Scale(s float64) Vec2 {
+	return Vec2{X: v.X * s, Y: v.Y * s}
 }
 
 // You might also define a slice type for convenience if needed elsewhere:
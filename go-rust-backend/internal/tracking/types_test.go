@@ -0,0 +1,32 @@
+package tracking
+
+import "testing"
+
+func TestPosition(t *testing.T) {
+	p := CursorPosition{X: -12, Y: 1080}
+	if got, want := p.Position(), (Vec2{X: -12, Y: 1080}); got != want {
+		t.Errorf("Position() = %v, want %v", got, want)
+	}
+}
+
+func TestVec2(t *testing.T) {
+	a := Vec2{X: 1.5, Y: -2}
+	b := Vec2{X: 0.25, Y: 4}
+	tests := []struct {
+		name string
+		got  Vec2
+		want Vec2
+	}{
+		{"add", a.Add(b), Vec2{X: 1.75, Y: 2}},
+		{"sub", a.Sub(b), Vec2{X: 1.25, Y: -6}},
+		{"scale", a.Scale(0.5), Vec2{X: 0.75, Y: -1}},
+		{"scale by zero", a.Scale(0), Vec2{}},
+		// Fractions survive, which whole-pixel samples alone would lose
+		{"midpoint", a.Add(b.Sub(a).Scale(0.5)), Vec2{X: 0.875, Y: 1}},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s = %v, want %v", tt.name, tt.got, tt.want)
+		}
+	}
+}
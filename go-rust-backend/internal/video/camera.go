@@ -0,0 +1,248 @@
+package video
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/vedantwpatil/Screen-Capture/internal/atomicfile"
+	"github.com/vedantwpatil/Screen-Capture/internal/config"
+	"github.com/vedantwpatil/Screen-Capture/internal/media"
+	"github.com/vedantwpatil/Screen-Capture/internal/tracking"
+)
+
+// cameraPlanVersion is bumped whenever the plan layout changes incompatibly
+const cameraPlanVersion = 1
+
+// maxCameraZoom matches the largest zoom the config accepts
+const maxCameraZoom = 8
+
+// CameraFrame is where the camera looks in one frame and where the cursor is
+type CameraFrame struct {
+	CropX   float64 `json:"crop_x"` // Top-left of the view in source pixels
+	CropY   float64 `json:"crop_y"`
+	Zoom    float64 `json:"zoom"` // The view is the frame size divided by this; 1 shows everything
+	CursorX float64 `json:"cursor_x"`
+	CursorY float64 `json:"cursor_y"`
+}
+
+// CameraPlan is the camera and cursor position for every frame of a
+// recording, worked out once from the cursor history and the effect settings
+// so every effect agrees on them. It is saved next to the recording as
+// <name>.camera.json; hand edits there are used by later renders as long as
+// the recording and settings it was built from stay the same.
+type CameraPlan struct {
+	Version int     `json:"version"`
+	Width   int     `json:"width"` // Source frame size
+	Height  int     `json:"height"`
+	FPS     float64 `json:"fps"` // Frame i is shown at i/FPS seconds
+
+	// Inputs fingerprints the history, video and settings the plan was built
+	// from; a saved plan whose inputs differ is rebuilt
+	Inputs string        `json:"inputs"`
+	Frames []CameraFrame `json:"frames"`
+}
+
+// Crop is the part of a frame the camera shows, in whole pixels
+type Crop struct {
+	X, Y, W, H int
+}
+
+// CameraPlanPath returns the plan path for a recording, e.g. demo.camera.json for demo.mp4
+func CameraPlanPath(videoPath string) string {
+	return strings.TrimSuffix(videoPath, filepath.Ext(videoPath)) + ".camera.json"
+}
+
+// BuildCameraPlan resamples the cursor history along a spline once per frame
+// of the video described by info, smooths it with the cursor spring, and
+// moves the camera after it: a follow zoom between clicks and the click
+// zoom around them, each easing in and out over the follow window
+func BuildCameraPlan(history []tracking.CursorPosition, info media.Info, cfg *config.Config) (*CameraPlan, error) {
+	if info.Width <= 0 || info.Height <= 0 || info.FPS <= 0 {
+		return nil, fmt.Errorf("%w: can't plan a camera for a %dx%d video at %g fps", ErrInvalidInput, info.Width, info.Height, info.FPS)
+	}
+	moves, _ := splitCursorEvents(history)
+	if len(moves) == 0 {
+		return nil, fmt.Errorf("%w: no cursor movement recorded", ErrInvalidInput)
+	}
+
+	fps := math.Round(info.FPS)
+	videoConfig := DefaultVideoConfig(int32(fps))
+	videoConfig.SmoothingAlpha = cfg.Effects.Smoothing.SplineAlpha
+	videoConfig.Responsiveness = cfg.Effects.Smoothing.Responsiveness
+	videoConfig.Smoothness = cfg.Effects.Smoothing.Smoothness
+	frames := int(info.Duration.Seconds()*fps) + 1
+	path := smoothCursorPath(moves, videoConfig, frames)
+	if len(path) == 0 {
+		return nil, fmt.Errorf("%w: no points produced from %d raw samples", ErrSmoothingFailed, len(moves))
+	}
+
+	return &CameraPlan{
+		Version: cameraPlanVersion,
+		Width:   info.Width,
+		Height:  info.Height,
+		FPS:     fps,
+		Inputs:  cameraInputs(history, info, cfg),
+		Frames:  planCamera(path, ClickOffsets(history), cameraOptionsFrom(cfg, info.Width, info.Height, fps)),
+	}, nil
+}
+
+// CameraPlanFor returns the saved plan for a recording when it was built
+// from the same inputs, and otherwise builds a new one and saves it
+func CameraPlanFor(videoPath string, history []tracking.CursorPosition, info media.Info, cfg *config.Config) (*CameraPlan, error) {
+	path := CameraPlanPath(videoPath)
+	saved, err := LoadCameraPlan(path)
+	switch {
+	case err == nil && saved.Inputs == cameraInputs(history, info, cfg):
+		return saved, nil
+	case err == nil:
+		slog.Info("Recording or settings changed, rebuilding the camera plan", "file", path)
+	case !errors.Is(err, os.ErrNotExist):
+		slog.Warn("Ignoring unusable camera plan", "err", err)
+	}
+
+	plan, err := BuildCameraPlan(history, info, cfg)
+	if err != nil {
+		return nil, err
+	}
+	// The plan is only a convenience for hand edits, so failing to keep it
+	// doesn't stop the render
+	if err := plan.Save(path); err != nil {
+		slog.Warn("Failed to save camera plan", "err", err)
+	}
+	return plan, nil
+}
+
+// Save writes the plan as indented JSON, so it can be edited by hand
+func (p *CameraPlan) Save(path string) error {
+	data, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode camera plan: %w", err)
+	}
+	if err := atomicfile.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write camera plan: %w", err)
+	}
+	return nil
+}
+
+// LoadCameraPlan reads a plan written by Save and checks it is usable.
+// A missing file gives an error wrapping os.ErrNotExist.
+func LoadCameraPlan(path string) (*CameraPlan, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var p CameraPlan
+	if err := json.Unmarshal(data, &p); err != nil {
+		return nil, fmt.Errorf("failed to parse camera plan %s: %w", path, err)
+	}
+	if p.Version != cameraPlanVersion {
+		return nil, fmt.Errorf("camera plan %s has unsupported version %d", path, p.Version)
+	}
+	if err := p.Validate(); err != nil {
+		return nil, fmt.Errorf("camera plan %s: %w", path, err)
+	}
+	return &p, nil
+}
+
+// Validate checks the values a hand edit could break. Views that reach past
+// the frame edge are fine; Crop pulls them back in.
+func (p *CameraPlan) Validate() error {
+	if p.Width <= 0 || p.Height <= 0 || p.FPS <= 0 {
+		return fmt.Errorf("invalid frame size %dx%d or fps %g", p.Width, p.Height, p.FPS)
+	}
+	if len(p.Frames) == 0 {
+		return errors.New("no frames")
+	}
+	for i, f := range p.Frames {
+		for _, v := range []float64{f.CropX, f.CropY, f.Zoom, f.CursorX, f.CursorY} {
+			if math.IsNaN(v) || math.IsInf(v, 0) {
+				return fmt.Errorf("frame %d: values must be finite numbers", i)
+			}
+		}
+		if f.Zoom < 1 || f.Zoom > maxCameraZoom {
+			return fmt.Errorf("frame %d: zoom must be between 1 and %d, got %g", i, maxCameraZoom, f.Zoom)
+		}
+	}
+	return nil
+}
+
+// Moves reports whether the camera ever zooms in; a plan that doesn't
+// leaves every frame as it is
+func (p *CameraPlan) Moves() bool {
+	for _, f := range p.Frames {
+		if f.Zoom > 1 {
+			return true
+		}
+	}
+	return false
+}
+
+// Crop returns frame i's view in whole pixels: an even size, so encoders
+// accept it once scaled, placed inside the frame
+func (p *CameraPlan) Crop(i int) Crop {
+	f := p.Frames[i]
+	c := Crop{
+		W: max(min(evenFloor(float64(p.Width)/f.Zoom), p.Width), 2),
+		H: max(min(evenFloor(float64(p.Height)/f.Zoom), p.Height), 2),
+	}
+	c.X = min(max(int(math.Round(f.CropX)), 0), max(p.Width-c.W, 0))
+	c.Y = min(max(int(math.Round(f.CropY)), 0), max(p.Height-c.H, 0))
+	return c
+}
+
+// Scaled returns a copy of the plan for the same video resized by sx and sy,
+// e.g. a preview render. Zoom and timing are unchanged.
+func (p *CameraPlan) Scaled(sx, sy float64) *CameraPlan {
+	scaled := *p
+	scaled.Width = int(math.Round(float64(p.Width) * sx))
+	scaled.Height = int(math.Round(float64(p.Height) * sy))
+	scaled.Frames = make([]CameraFrame, len(p.Frames))
+	for i, f := range p.Frames {
+		scaled.Frames[i] = CameraFrame{
+			CropX:   f.CropX * sx,
+			CropY:   f.CropY * sy,
+			Zoom:    f.Zoom,
+			CursorX: f.CursorX * sx,
+			CursorY: f.CursorY * sy,
+		}
+	}
+	return &scaled
+}
+
+// CursorPath returns the smoothed cursor position for every frame
+func (p *CameraPlan) CursorPath() []tracking.Vec2 {
+	path := make([]tracking.Vec2, len(p.Frames))
+	for i, f := range p.Frames {
+		path[i] = tracking.Vec2{X: f.CursorX, Y: f.CursorY}
+	}
+	return path
+}
+
+// cameraInputs fingerprints everything BuildCameraPlan reads
+func cameraInputs(history []tracking.CursorPosition, info media.Info, cfg *config.Config) string {
+	h := sha256.New()
+	json.NewEncoder(h).Encode(struct {
+		Version   int
+		History   []tracking.CursorPosition
+		Width     int
+		Height    int
+		FPS       float64
+		Duration  time.Duration
+		Zoom      config.ZoomConfig
+		Follow    config.FollowConfig
+		Smoothing config.SmoothingConfig
+	}{
+		cameraPlanVersion, history, info.Width, info.Height, info.FPS, info.Duration,
+		cfg.Effects.Zoom, cfg.Effects.Follow, cfg.Effects.Smoothing,
+	})
+	return hex.EncodeToString(h.Sum(nil))
+}
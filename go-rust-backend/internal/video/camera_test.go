@@ -0,0 +1,308 @@
+package video
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vedantwpatil/Screen-Capture/internal/easing"
+	"github.com/vedantwpatil/Screen-Capture/internal/tracking"
+)
+
+func testCameraOptions() cameraOptions {
+	return cameraOptions{
+		Width:     1920,
+		Height:    1080,
+		FPS:       30,
+		Zoom:      1.5,
+		ClickZoom: 2.25,
+		Window:    time.Second,
+		DeadZone:  0.5,
+		Release:   0.8,
+		Easing:    easing.Smoothstep,
+	}
+}
+
+// stillPath holds the cursor at p for n frames
+func stillPath(p tracking.Vec2, n int) []tracking.Vec2 {
+	path := make([]tracking.Vec2, n)
+	for i := range path {
+		path[i] = p
+	}
+	return path
+}
+
+// checkInFrame fails unless every view lies inside the frame
+func checkInFrame(t *testing.T, frames []CameraFrame, opts cameraOptions) {
+	t.Helper()
+	const slack = 1e-9
+	for i, f := range frames {
+		w, h := float64(opts.Width)/f.Zoom, float64(opts.Height)/f.Zoom
+		if f.CropX < -slack || f.CropY < -slack || f.CropX+w > float64(opts.Width)+slack || f.CropY+h > float64(opts.Height)+slack {
+			t.Fatalf("frame %d: view (%g, %g) %gx%g leaves the %dx%d frame", i, f.CropX, f.CropY, w, h, opts.Width, opts.Height)
+		}
+	}
+}
+
+func TestPlanCamera(t *testing.T) {
+	const n = 90 // Three seconds at 30 fps
+	last := time.Duration(n-1) * time.Second / 30
+	center := tracking.Vec2{X: 960, Y: 540}
+
+	tests := []struct {
+		name   string
+		path   []tracking.Vec2
+		clicks []time.Duration
+		opts   func(*cameraOptions)
+		check  func(t *testing.T, frames []CameraFrame, opts cameraOptions)
+	}{
+		{
+			name: "stationary cursor keeps the follow zoom and never pans",
+			path: stillPath(center, n),
+			check: func(t *testing.T, frames []CameraFrame, opts cameraOptions) {
+				for i, f := range frames {
+					if f.Zoom != opts.Zoom || f.CropX != frames[0].CropX || f.CropY != frames[0].CropY {
+						t.Fatalf("frame %d moved: %+v, first %+v", i, f, frames[0])
+					}
+				}
+			},
+		},
+		{
+			name:   "click at the first frame starts fully zoomed",
+			path:   stillPath(center, n),
+			clicks: []time.Duration{0},
+			check: func(t *testing.T, frames []CameraFrame, opts cameraOptions) {
+				if got := frames[0].Zoom; got != opts.ClickZoom {
+					t.Errorf("frame 0 zoom = %g, want %g", got, opts.ClickZoom)
+				}
+				if got := frames[n-1].Zoom; got != opts.Zoom {
+					t.Errorf("last frame zoom = %g, want the follow zoom %g once the window has passed", got, opts.Zoom)
+				}
+			},
+		},
+		{
+			name:   "click at the last frame ends fully zoomed",
+			path:   stillPath(center, n),
+			clicks: []time.Duration{last},
+			check: func(t *testing.T, frames []CameraFrame, opts cameraOptions) {
+				if got := frames[n-1].Zoom; math.Abs(got-opts.ClickZoom) > 1e-9 {
+					t.Errorf("last frame zoom = %g, want %g", got, opts.ClickZoom)
+				}
+				if got := frames[0].Zoom; got != opts.Zoom {
+					t.Errorf("frame 0 zoom = %g, want %g before the window starts", got, opts.Zoom)
+				}
+			},
+		},
+		{
+			name:   "cursor in the top-left corner clamps the view to the frame",
+			path:   stillPath(tracking.Vec2{}, n),
+			clicks: []time.Duration{time.Second},
+			check: func(t *testing.T, frames []CameraFrame, opts cameraOptions) {
+				checkInFrame(t, frames, opts)
+				if f := frames[n-1]; f.CropX != 0 || f.CropY != 0 {
+					t.Errorf("last view starts at (%g, %g), want the corner", f.CropX, f.CropY)
+				}
+			},
+		},
+		{
+			name:   "cursor in the bottom-right corner clamps the view to the frame",
+			path:   stillPath(tracking.Vec2{X: 1920, Y: 1080}, n),
+			clicks: []time.Duration{time.Second},
+			check: func(t *testing.T, frames []CameraFrame, opts cameraOptions) {
+				checkInFrame(t, frames, opts)
+				f := frames[n-1]
+				if right := f.CropX + 1920/f.Zoom; math.Abs(right-1920) > 1e-9 {
+					t.Errorf("last view ends at x=%g, want the right edge", right)
+				}
+			},
+		},
+		{
+			name:   "sweeping cursor stays inside the frame",
+			path:   sweep(tracking.Vec2{X: -200, Y: -200}, tracking.Vec2{X: 2200, Y: 1300}, n),
+			clicks: []time.Duration{500 * time.Millisecond, 2 * time.Second},
+			check:  checkInFrame,
+		},
+		{
+			name:   "zero follow window ignores clicks",
+			path:   stillPath(center, n),
+			clicks: []time.Duration{0, time.Second, last},
+			opts:   func(o *cameraOptions) { o.Window = 0 },
+			check: func(t *testing.T, frames []CameraFrame, opts cameraOptions) {
+				for i, f := range frames {
+					if f.Zoom != opts.Zoom {
+						t.Fatalf("frame %d zoom = %g, want %g", i, f.Zoom, opts.Zoom)
+					}
+				}
+			},
+		},
+		{
+			name:   "out of range zooms are clamped",
+			path:   stillPath(center, n),
+			opts:   func(o *cameraOptions) { o.Zoom, o.ClickZoom = 0.5, 20 },
+			clicks: []time.Duration{time.Second},
+			check: func(t *testing.T, frames []CameraFrame, opts cameraOptions) {
+				for i, f := range frames {
+					if f.Zoom < 1 || f.Zoom > maxCameraZoom {
+						t.Fatalf("frame %d zoom = %g, outside 1..%d", i, f.Zoom, maxCameraZoom)
+					}
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := testCameraOptions()
+			if tt.opts != nil {
+				tt.opts(&opts)
+			}
+			frames := planCamera(tt.path, tt.clicks, opts)
+			if len(frames) != len(tt.path) {
+				t.Fatalf("got %d frames for a %d frame path", len(frames), len(tt.path))
+			}
+			for i, f := range frames {
+				if f.CursorX != tt.path[i].X || f.CursorY != tt.path[i].Y {
+					t.Fatalf("frame %d cursor = (%g, %g), want %v", i, f.CursorX, f.CursorY, tt.path[i])
+				}
+			}
+			tt.check(t, frames, opts)
+		})
+	}
+}
+
+func TestPlanCameraRejectsEmptyInput(t *testing.T) {
+	opts := testCameraOptions()
+	if frames := planCamera(nil, nil, opts); frames != nil {
+		t.Errorf("empty path gave %d frames", len(frames))
+	}
+	opts.FPS = 0
+	if frames := planCamera(stillPath(tracking.Vec2{}, 3), nil, opts); frames != nil {
+		t.Errorf("zero fps gave %d frames", len(frames))
+	}
+}
+
+// sweep moves the cursor in a straight line from a to b over n frames
+func sweep(a, b tracking.Vec2, n int) []tracking.Vec2 {
+	path := make([]tracking.Vec2, n)
+	for i := range path {
+		path[i] = a.Add(b.Sub(a).Scale(float64(i) / float64(n-1)))
+	}
+	return path
+}
+
+func TestCameraPlanCrop(t *testing.T) {
+	tests := []struct {
+		name          string
+		width, height int
+		frame         CameraFrame
+		want          Crop
+	}{
+		{"even frame unzoomed", 1920, 1080, CameraFrame{Zoom: 1}, Crop{0, 0, 1920, 1080}},
+		{"odd frame unzoomed drops a line", 1921, 1081, CameraFrame{Zoom: 1}, Crop{0, 0, 1920, 1080}},
+		{"odd frame zoomed", 1921, 1081, CameraFrame{CropX: 100.4, CropY: 50.6, Zoom: 1.5}, Crop{100, 51, 1280, 720}},
+		{"odd view size rounds down to even", 1001, 999, CameraFrame{Zoom: 3}, Crop{0, 0, 332, 332}},
+		{"view past the right edge is pulled back", 1921, 1081, CameraFrame{CropX: 5000, CropY: 5000, Zoom: 2}, Crop{961, 541, 960, 540}},
+		{"view past the left edge is pulled back", 1920, 1080, CameraFrame{CropX: -50, CropY: -1, Zoom: 2}, Crop{0, 0, 960, 540}},
+		{"tiny frame keeps at least 2 pixels", 3, 3, CameraFrame{Zoom: 8}, Crop{0, 0, 2, 2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan := &CameraPlan{Width: tt.width, Height: tt.height, FPS: 30, Frames: []CameraFrame{tt.frame}}
+			got := plan.Crop(0)
+			if got != tt.want {
+				t.Errorf("Crop = %+v, want %+v", got, tt.want)
+			}
+			if got.W%2 != 0 || got.H%2 != 0 {
+				t.Errorf("Crop size %dx%d is not even", got.W, got.H)
+			}
+		})
+	}
+}
+
+func validPlan() *CameraPlan {
+	return &CameraPlan{
+		Version: cameraPlanVersion,
+		Width:   1920,
+		Height:  1080,
+		FPS:     60,
+		Inputs:  "abc123",
+		Frames: []CameraFrame{
+			{CropX: 0, CropY: 0, Zoom: 1, CursorX: 10, CursorY: 20},
+			{CropX: 320.5, CropY: 180.25, Zoom: 1.5, CursorX: 960, CursorY: 540},
+		},
+	}
+}
+
+func TestCameraPlanRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "demo.camera.json")
+	want := validPlan()
+	if err := want.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	got, err := LoadCameraPlan(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("loaded %+v, want %+v", got, want)
+	}
+}
+
+func TestLoadCameraPlanRejects(t *testing.T) {
+	tests := []struct {
+		name    string
+		edit    func(p *CameraPlan)
+		wantErr string
+	}{
+		{"unsupported version", func(p *CameraPlan) { p.Version = cameraPlanVersion + 1 }, "unsupported version"},
+		{"zoom below 1", func(p *CameraPlan) { p.Frames[1].Zoom = 0.5 }, "zoom must be between 1 and 8"},
+		{"zoom above 8", func(p *CameraPlan) { p.Frames[1].Zoom = 9 }, "zoom must be between 1 and 8"},
+		{"no frames", func(p *CameraPlan) { p.Frames = nil }, "no frames"},
+		{"zero fps", func(p *CameraPlan) { p.FPS = 0 }, "invalid frame size"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan := validPlan()
+			tt.edit(plan)
+			path := filepath.Join(t.TempDir(), "demo.camera.json")
+			if err := plan.Save(path); err != nil {
+				t.Fatal(err)
+			}
+			_, err := LoadCameraPlan(path)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("LoadCameraPlan error = %v, want one mentioning %q", err, tt.wantErr)
+			}
+		})
+	}
+
+	t.Run("missing file", func(t *testing.T) {
+		_, err := LoadCameraPlan(filepath.Join(t.TempDir(), "none.camera.json"))
+		if !os.IsNotExist(err) {
+			t.Errorf("error = %v, want one wrapping os.ErrNotExist", err)
+		}
+	})
+	t.Run("malformed JSON", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "demo.camera.json")
+		if err := os.WriteFile(path, []byte(`{"version": 1, "frames": [`), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadCameraPlan(path); err == nil {
+			t.Error("malformed plan loaded without error")
+		}
+	})
+}
+
+// JSON can't carry NaN or infinities, so only Validate sees them, e.g. from
+// a plan built in memory
+func TestCameraPlanValidateRejectsNonFinite(t *testing.T) {
+	for _, v := range []float64{math.NaN(), math.Inf(1), math.Inf(-1)} {
+		plan := validPlan()
+		plan.Frames[1].CursorX = v
+		if err := plan.Validate(); err == nil || !strings.Contains(err.Error(), "finite") {
+			t.Errorf("cursor x %g: Validate error = %v, want a finite-number error", v, err)
+		}
+	}
+}
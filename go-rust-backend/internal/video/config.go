@@ -0,0 +1,222 @@
+package video
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/vedantwpatil/Screen-Capture/internal/cursorshape"
+	"github.com/vedantwpatil/Screen-Capture/internal/tracking"
+)
+
+// Errors returned by ProcessVideoWithCursor, matching the engine's status codes.
+// The backend's own message is appended, so use errors.Is to classify failures.
+var (
+	ErrInvalidInput    = errors.New("invalid input")
+	ErrSmoothingFailed = errors.New("cursor smoothing failed")
+	ErrEncodeFailed    = errors.New("video encoding failed")
+	ErrCursorSprite    = errors.New("cursor sprite could not be loaded")
+)
+
+// VideoConfig configures cursor smoothing behavior for video processing.
+type VideoConfig struct {
+	// SmoothingAlpha is the Catmull-Rom spline parameter (0.5 = centripetal, recommended)
+	SmoothingAlpha float64
+
+	// Responsiveness controls the "spring stiffness" of cursor physics (0-1)
+	// Maps to: tension = lerp(50.0, 500.0, responsiveness)
+	// 0.0 = slow, floaty tracking (~400ms settling time)
+	// 1.0 = snappy, immediate tracking (~60ms settling time)
+	Responsiveness float64
+
+	// Smoothness controls the "damping" to prevent overshoot (0-1)
+	// Maps to: friction = lerp(5.0, 50.0, smoothness)
+	// 0.0 = slight overshoot allowed (underdamped, bouncy)
+	// 1.0 = no overshoot, critically damped (Screen Studio default)
+	Smoothness float64
+
+	// FrameRate is the output video frame rate (e.g., 60). Cursor samples are
+	// resampled to it by timestamp, whatever rate they were captured at.
+	FrameRate int32
+
+	// LogLevel controls Rust logging verbosity: 0=off, 1=error, 2=warn, 3=info, 4=debug, 5=trace
+	LogLevel int32
+
+	// MotionBlurStrength controls the directional blur on the rendered cursor
+	// 0.0 = disabled
+	// 1.0 = ghost copies span the full distance travelled in one frame
+	MotionBlurStrength float64
+
+	// MotionBlurThreshold is the cursor speed (pixels per frame) below which no blur is drawn
+	MotionBlurThreshold float64
+
+	// CursorScale resizes the sprite before compositing (1.0 = native size)
+	CursorScale float64
+
+	// CursorHotspotX/Y is the pointer tip in unscaled sprite pixels; it is
+	// placed on the recorded cursor position
+	CursorHotspotX float64
+	CursorHotspotY float64
+
+	// TrailDuration is how far back along the path the cursor trail reaches;
+	// zero draws no trail
+	TrailDuration time.Duration
+
+	// TrailOpacity is the opacity of the trail's nearest ghost; farther ones fade out
+	TrailOpacity float64
+
+	// CursorShapes are drawn instead of the sprite while the recorded cursor
+	// has their shape
+	CursorShapes map[cursorshape.Shape]ShapeSprite
+
+	// Preview trades quality for speed where the backend allows it; the
+	// ffmpeg backend encodes with the ultrafast preset
+	Preview bool
+}
+
+// ShapeSprite is the sprite for one cursor shape
+type ShapeSprite struct {
+	Path string
+
+	// HotspotX/Y is the point placed on the cursor position, in unscaled
+	// sprite pixels like CursorHotspotX/Y
+	HotspotX float64
+	HotspotY float64
+}
+
+// DefaultVideoConfig returns a balanced configuration for smooth cursor tracking.
+func DefaultVideoConfig(frameRate int32) VideoConfig {
+	return VideoConfig{
+		SmoothingAlpha: 0.5, // Centripetal Catmull-Rom
+		Responsiveness: 0.5, // Balanced response time
+		Smoothness:     0.7, // Mostly smooth with minimal overshoot
+		FrameRate:      frameRate,
+		LogLevel:       3, // Info level
+
+		MotionBlurStrength:  0.5, // Trail covers half of each frame's travel
+		MotionBlurThreshold: 8.0, // Only blur clearly fast movement
+
+		CursorScale: 1.0,
+	}
+}
+
+// Spring model shared with the engine: tension = lerp(50, 500, Responsiveness),
+// friction = lerp(5, 50, Smoothness), unit mass
+func springTension(responsiveness float64) float64 { return lerp(50.0, 500.0, responsiveness) }
+func springFriction(smoothness float64) float64    { return lerp(5.0, 50.0, smoothness) }
+
+// DampingRatio is the cursor spring's damping ratio: below 1 it overshoots,
+// 1 settles fastest without overshoot, above 1 lags behind the pointer
+func (c VideoConfig) DampingRatio() float64 {
+	return springFriction(c.Smoothness) / (2 * math.Sqrt(springTension(c.Responsiveness)))
+}
+
+// minDampingRatio is where overshoot turns into visible wobble around clicks
+// (e.g. full responsiveness with zero smoothness gives 0.11)
+const minDampingRatio = 0.15
+
+// Validate rejects settings that would send NaNs or runaway motion into the
+// renderer, with a hint at a sensible value for each problem
+func (c VideoConfig) Validate() error {
+	var problems []error
+	unit := func(name string, v float64, hint string) {
+		if math.IsNaN(v) || v < 0 || v > 1 {
+			problems = append(problems, fmt.Errorf("%s %v is outside 0..1; %s", name, v, hint))
+		}
+	}
+	unit("smoothing alpha", c.SmoothingAlpha, "0.5 (centripetal) avoids loops at sharp turns")
+	unit("responsiveness", c.Responsiveness, "try 0.3-0.7")
+	unit("smoothness", c.Smoothness, "try 0.5-1.0")
+
+	if len(problems) == 0 {
+		if ratio := c.DampingRatio(); ratio < minDampingRatio {
+			problems = append(problems, fmt.Errorf(
+				"responsiveness %.2f with smoothness %.2f gives damping ratio %.2f and the cursor will wobble; raise smoothness or lower responsiveness",
+				c.Responsiveness, c.Smoothness, ratio))
+		}
+	}
+
+	if c.FrameRate <= 0 || c.FrameRate > 240 {
+		problems = append(problems, fmt.Errorf("frame rate %d is outside 1..240; try 30 or 60", c.FrameRate))
+	}
+	if c.LogLevel < 0 || c.LogLevel > 5 {
+		problems = append(problems, fmt.Errorf("log level %d is outside 0..5", c.LogLevel))
+	}
+	if math.IsNaN(c.MotionBlurStrength) || c.MotionBlurStrength < 0 || c.MotionBlurStrength > 1 {
+		problems = append(problems, fmt.Errorf("motion blur strength %v is outside 0..1; 0 disables it, 0.5 is subtle", c.MotionBlurStrength))
+	}
+	if math.IsNaN(c.MotionBlurThreshold) || c.MotionBlurThreshold < 0 {
+		problems = append(problems, fmt.Errorf("motion blur threshold %v must not be negative; try 8", c.MotionBlurThreshold))
+	}
+	if math.IsNaN(c.CursorScale) || c.CursorScale <= 0 || c.CursorScale > 8 {
+		problems = append(problems, fmt.Errorf("cursor scale %v is outside (0, 8]; 1 is native size", c.CursorScale))
+	}
+	if c.CursorHotspotX < 0 || c.CursorHotspotY < 0 {
+		problems = append(problems, fmt.Errorf("cursor hotspot (%v, %v) must not be negative", c.CursorHotspotX, c.CursorHotspotY))
+	}
+	if c.TrailDuration < 0 || c.TrailDuration > time.Second {
+		problems = append(problems, fmt.Errorf("cursor trail duration %s is outside 0..1s; try 200ms", c.TrailDuration))
+	}
+	if math.IsNaN(c.TrailOpacity) || c.TrailOpacity < 0 || c.TrailOpacity > 1 {
+		problems = append(problems, fmt.Errorf("cursor trail opacity %v is outside 0..1; try 0.5", c.TrailOpacity))
+	}
+	for shape, sprite := range c.CursorShapes {
+		if sprite.Path == "" {
+			problems = append(problems, fmt.Errorf("%s cursor has no sprite", shape))
+		}
+		if sprite.HotspotX < 0 || sprite.HotspotY < 0 {
+			problems = append(problems, fmt.Errorf("%s cursor hotspot (%v, %v) must not be negative", shape, sprite.HotspotX, sprite.HotspotY))
+		}
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("%w: video config: %w", ErrInvalidInput, errors.Join(problems...))
+	}
+	return nil
+}
+
+func lerp(a, b, t float64) float64 {
+	return a + (b-a)*t
+}
+
+// checkPaths catches missing files before crossing the FFI, where the engine
+// could only report them as a generic failure.
+func checkPaths(inputVideoPath, outputVideoPath, cursorSpritePath string) error {
+	if _, err := os.Stat(inputVideoPath); err != nil {
+		return fmt.Errorf("%w: input video: %w", ErrInvalidInput, err)
+	}
+	if _, err := os.Stat(cursorSpritePath); err != nil {
+		return fmt.Errorf("%w: %w", ErrCursorSprite, err)
+	}
+	if _, err := os.Stat(filepath.Dir(outputVideoPath)); err != nil {
+		return fmt.Errorf("%w: output directory: %w", ErrInvalidInput, err)
+	}
+	return nil
+}
+
+// splitCursorEvents separates movement samples from clicks. Both are recorded
+// into one history by different goroutines, so each half is re-sorted by time.
+func splitCursorEvents(history []tracking.CursorPosition) (moves, clicks []tracking.CursorPosition) {
+	for _, p := range history {
+		if p.Click {
+			clicks = append(clicks, p)
+		} else {
+			moves = append(moves, p)
+		}
+	}
+	byTime := func(events []tracking.CursorPosition) func(i, j int) bool {
+		return func(i, j int) bool { return events[i].ClickTimeStamp < events[j].ClickTimeStamp }
+	}
+	sort.SliceStable(moves, byTime(moves))
+	sort.SliceStable(clicks, byTime(clicks))
+	return moves, clicks
+}
+
+// durationToMillis converts a timestamp to the engine's fractional milliseconds
+func durationToMillis(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
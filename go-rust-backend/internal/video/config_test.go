@@ -0,0 +1,103 @@
+package video
+
+import (
+	"testing"
+	"time"
+
+	"github.com/vedantwpatil/Screen-Capture/internal/tracking"
+)
+
+func TestSplitCursorEvents(t *testing.T) {
+	move := func(ms float64) tracking.CursorPosition {
+		return tracking.CursorPosition{ClickTimeStamp: time.Duration(ms * float64(time.Millisecond))}
+	}
+	click := func(ms float64, button uint16) tracking.CursorPosition {
+		p := move(ms)
+		p.Click, p.Button = true, button
+		return p
+	}
+
+	tests := []struct {
+		name       string
+		history    []tracking.CursorPosition
+		wantMoves  []float64 // Timestamps in ms, in the order returned
+		wantClicks []float64
+	}{
+		{"empty", nil, nil, nil},
+		{
+			name:      "moves only",
+			history:   []tracking.CursorPosition{move(0), move(16.5), move(33)},
+			wantMoves: []float64{0, 16.5, 33},
+		},
+		{
+			name:       "clicks interleaved with moves",
+			history:    []tracking.CursorPosition{move(0), click(10, 1), move(16), move(32), click(40, 3)},
+			wantMoves:  []float64{0, 16, 32},
+			wantClicks: []float64{10, 40},
+		},
+		{
+			// The hook and the sampler append from different goroutines
+			name:       "out of order input is sorted",
+			history:    []tracking.CursorPosition{move(32), click(40, 1), move(0), click(5, 1), move(16)},
+			wantMoves:  []float64{0, 16, 32},
+			wantClicks: []float64{5, 40},
+		},
+		{
+			name:       "sub-millisecond timestamps survive",
+			history:    []tracking.CursorPosition{move(0.25), move(1.5), click(2.125, 1), move(1000.001)},
+			wantMoves:  []float64{0.25, 1.5, 1000.001},
+			wantClicks: []float64{2.125},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			moves, clicks := splitCursorEvents(tt.history)
+			check := func(kind string, got []tracking.CursorPosition, want []float64, isClick bool) {
+				t.Helper()
+				if len(got) != len(want) {
+					t.Fatalf("got %d %s, want %d", len(got), kind, len(want))
+				}
+				for i, p := range got {
+					if p.Click != isClick {
+						t.Errorf("%s[%d] has Click = %v", kind, i, p.Click)
+					}
+					if ms := durationToMillis(p.ClickTimeStamp); ms != want[i] {
+						t.Errorf("%s[%d] at %vms, want %vms", kind, i, ms, want[i])
+					}
+				}
+			}
+			check("moves", moves, tt.wantMoves, false)
+			check("clicks", clicks, tt.wantClicks, true)
+		})
+	}
+}
+
+func TestSplitCursorEventsKeepsButtons(t *testing.T) {
+	// Two clicks at the same instant keep their recorded order
+	history := []tracking.CursorPosition{
+		{Click: true, Button: 1, ClickTimeStamp: time.Second},
+		{Click: true, Button: 3, ClickTimeStamp: time.Second},
+	}
+	_, clicks := splitCursorEvents(history)
+	if len(clicks) != 2 || clicks[0].Button != 1 || clicks[1].Button != 3 {
+		t.Errorf("clicks = %+v, want buttons 1 then 3", clicks)
+	}
+}
+
+func TestDurationToMillis(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want float64
+	}{
+		{0, 0},
+		{time.Millisecond, 1},
+		{1500 * time.Microsecond, 1.5},
+		{time.Nanosecond, 1e-6},
+		{time.Hour + 250*time.Microsecond, 3_600_000.25},
+	}
+	for _, tt := range tests {
+		if got := durationToMillis(tt.d); got != tt.want {
+			t.Errorf("durationToMillis(%v) = %v, want %v", tt.d, got, tt.want)
+		}
+	}
+}
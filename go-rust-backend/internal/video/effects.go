@@ -1,3 +1,5 @@
+//go:build rustengine
+
 package video
 
 /*
@@ -12,47 +14,28 @@ extern void goProgressGateway(void *user_data, float percent);
 import "C"
 
 import (
+	"bytes"
+	"context"
 	"fmt"
+	"log/slog"
+	"os"
 	"runtime/cgo"
+	"slices"
+	"sync/atomic"
 	"unsafe"
 
+	"github.com/vedantwpatil/Screen-Capture/internal/cursorshape"
 	"github.com/vedantwpatil/Screen-Capture/internal/tracking"
 )
 
-// VideoConfig configures cursor smoothing behavior for video processing.
-type VideoConfig struct {
-	// SmoothingAlpha is the Catmull-Rom spline parameter (0.5 = centripetal, recommended)
-	SmoothingAlpha float64
-
-	// Responsiveness controls the "spring stiffness" of cursor physics (0-1)
-	// Maps to: tension = lerp(50.0, 500.0, responsiveness)
-	// 0.0 = slow, floaty tracking (~400ms settling time)
-	// 1.0 = snappy, immediate tracking (~60ms settling time)
-	Responsiveness float64
+// Engine names the cursor renderer compiled into this build
+const Engine = "rust"
 
-	// Smoothness controls the "damping" to prevent overshoot (0-1)
-	// Maps to: friction = lerp(5.0, 50.0, smoothness)
-	// 0.0 = slight overshoot allowed (underdamped, bouncy)
-	// 1.0 = no overshoot, critically damped (Screen Studio default)
-	Smoothness float64
+// errorBufferSize is the space given to the engine for its error message
+const errorBufferSize = 1024
 
-	// FrameRate is the video frame rate (e.g., 60)
-	FrameRate int32
-
-	// LogLevel controls Rust logging verbosity: 0=off, 1=error, 2=warn, 3=info, 4=debug, 5=trace
-	LogLevel int32
-}
-
-// DefaultVideoConfig returns a balanced configuration for smooth cursor tracking.
-func DefaultVideoConfig(frameRate int32) VideoConfig {
-	return VideoConfig{
-		SmoothingAlpha: 0.5, // Centripetal Catmull-Rom
-		Responsiveness: 0.5, // Balanced response time
-		Smoothness:     0.7, // Mostly smooth with minimal overshoot
-		FrameRate:      frameRate,
-		LogLevel:       3, // Info level
-	}
-}
+// errCodeCancelled is returned by the engine when the cancel flag was set
+const errCodeCancelled = -6
 
 // The Gateway Function - Exported for C to call
 //
@@ -84,16 +67,29 @@ func goProgressGateway(userData unsafe.Pointer, percent C.float) {
 
 // ProcessVideoWithCursor renders a video with smooth cursor overlay.
 // This function is thread-safe and can be called concurrently.
+// Cancelling ctx stops the engine, removes the partial output and returns ctx.Err().
+// The engine smooths mouseHistory itself, so plan is not used here.
 func ProcessVideoWithCursor(
+	ctx context.Context,
 	inputVideoPath string,
 	outputVideoPath string,
 	cursorSpritePath string,
 	mouseHistory []tracking.CursorPosition,
+	plan *CameraPlan,
 	config VideoConfig,
 	progressHandler func(float32),
 ) error {
 	if len(mouseHistory) == 0 {
-		return fmt.Errorf("no mouse history provided")
+		return fmt.Errorf("%w: no mouse history provided", ErrInvalidInput)
+	}
+	if err := config.Validate(); err != nil {
+		return err
+	}
+	if err := checkPaths(inputVideoPath, outputVideoPath, cursorSpritePath); err != nil {
+		return err
+	}
+	if err := ctx.Err(); err != nil {
+		return err
 	}
 
 	// Convert strings to C strings (heap allocation)
@@ -106,27 +102,56 @@ func ProcessVideoWithCursor(
 	cCursorPath := C.CString(cursorSpritePath)
 	defer C.free(unsafe.Pointer(cCursorPath))
 
+	// Movement drives the smoothed path; clicks travel in their own array
+	moves, clicks := splitCursorEvents(mouseHistory)
+	if len(moves) == 0 {
+		return fmt.Errorf("%w: no cursor movement samples, only %d clicks", ErrInvalidInput, len(clicks))
+	}
+
 	// Debug
-	if len(mouseHistory) > 0 {
-		first := mouseHistory[0]
-		ts := float64(first.ClickTimeStamp.Nanoseconds()) / 1e6
-		fmt.Printf("[Go] First Point: X=%.2f Y=%.2f TS=%.4f ms\n", first.X, first.Y, ts)
+	first := moves[0]
+	slog.Debug("First cursor point", "x", first.X, "y", first.Y,
+		"timestamp_ms", durationToMillis(first.ClickTimeStamp), "clicks", len(clicks))
+	if first.ClickTimeStamp < 0 {
+		return fmt.Errorf("%w: negative cursor timestamp %v, check mouse history capture", ErrInvalidInput, first.ClickTimeStamp)
+	}
 
-		if ts < 0 {
-			return fmt.Errorf("FATAL: Negative timestamps detected in Go input. Check mouse history capture.")
+	// Prepare cursor points (kept as floats; the renderer composites at sub-pixel precision)
+	cPoints := make([]C.CPoint, len(moves))
+	for i, p := range moves {
+		pos := p.Position()
+		cPoints[i] = C.CPoint{
+			x:            C.float(pos.X),
+			y:            C.float(pos.Y),
+			timestamp_ms: C.double(durationToMillis(p.ClickTimeStamp)),
 		}
 	}
 
-	// Prepare cursor points
-	cPoints := make([]C.CPoint, len(mouseHistory))
-	for i, p := range mouseHistory {
-		timestampMillis := float64(p.ClickTimeStamp.Nanoseconds()) / 1_000_000.0
-		cPoints[i] = C.CPoint{
-			x:            C.float(p.X),
-			y:            C.float(p.Y),
-			timestamp_ms: C.double(timestampMillis),
+	var cClicksPtr *C.CClickEvent
+	cClicks := make([]C.CClickEvent, len(clicks))
+	for i, c := range clicks {
+		pos := c.Position()
+		cClicks[i] = C.CClickEvent{
+			x:            C.float(pos.X),
+			y:            C.float(pos.Y),
+			timestamp_ms: C.double(durationToMillis(c.ClickTimeStamp)),
+			button:       C.uint32_t(c.Button),
 		}
 	}
+	if len(cClicks) > 0 {
+		cClicksPtr = &cClicks[0]
+	}
+
+	cShapeSprites, cShapeChanges, freeShapes := engineShapes(moves, config.CursorShapes)
+	defer freeShapes()
+	var cShapeSpritesPtr *C.CCursorShapeSprite
+	if len(cShapeSprites) > 0 {
+		cShapeSpritesPtr = &cShapeSprites[0]
+	}
+	var cShapeChangesPtr *C.CShapeChange
+	if len(cShapeChanges) > 0 {
+		cShapeChangesPtr = &cShapeChanges[0]
+	}
 
 	// Prepare configuration
 	cConfig := C.VideoProcessingConfig{
@@ -135,6 +160,16 @@ func ProcessVideoWithCursor(
 		smoothness:      C.float(config.Smoothness),
 		frame_rate:      C.int32_t(config.FrameRate),
 		log_level:       C.int32_t(config.LogLevel),
+
+		motion_blur_strength:  C.float(config.MotionBlurStrength),
+		motion_blur_threshold: C.float(config.MotionBlurThreshold),
+
+		cursor_scale:     C.float(config.CursorScale),
+		cursor_hotspot_x: C.float(config.CursorHotspotX),
+		cursor_hotspot_y: C.float(config.CursorHotspotY),
+
+		trail_duration_ms: C.float(durationToMillis(config.TrailDuration)),
+		trail_opacity:     C.float(config.TrailOpacity),
 	}
 
 	// Create progress channel and pin it with a Handle
@@ -153,25 +188,134 @@ func ProcessVideoWithCursor(
 		}
 	}()
 
-	// Call Rust with the context handle
+	// Cancel flag lives in C memory so Rust can poll it while Go flips it
+	cancelFlag := (*C.int32_t)(C.malloc(C.size_t(unsafe.Sizeof(C.int32_t(0)))))
+	defer C.free(unsafe.Pointer(cancelFlag))
+	*cancelFlag = 0
+
+	finished := make(chan struct{})
+	go func() {
+		select {
+		case <-ctx.Done():
+			atomic.StoreInt32((*int32)(unsafe.Pointer(cancelFlag)), 1)
+		case <-finished:
+		}
+	}()
+
+	// Call Rust with the context handle; failures are described in errBuf
+	errBuf := make([]byte, errorBufferSize)
 	result := C.process_video_with_cursor(
 		cInputPath,
 		cOutputPath,
 		cCursorPath,
 		(*C.CPoint)(unsafe.Pointer(&cPoints[0])),
 		C.size_t(len(cPoints)),
+		cClicksPtr,
+		C.size_t(len(cClicks)),
+		cShapeSpritesPtr,
+		C.size_t(len(cShapeSprites)),
+		cShapeChangesPtr,
+		C.size_t(len(cShapeChanges)),
 		&cConfig,
 		C.ProgressCallback(C.goProgressGateway), // Function pointer
 		unsafe.Pointer(handle),                  // Context (the "cookie")
+		(*C.char)(unsafe.Pointer(&errBuf[0])),
+		C.size_t(len(errBuf)),
+		cancelFlag,
 	)
 
 	// Clean up
+	close(finished)
 	close(progressChan)
 	<-done // Wait for goroutine to finish
 
+	if result == errCodeCancelled {
+		if err := os.Remove(outputVideoPath); err != nil && !os.IsNotExist(err) {
+			slog.Warn("Failed to remove partial output", "path", outputVideoPath, "err", err)
+		}
+		return ctx.Err()
+	}
 	if result != 0 {
-		return fmt.Errorf("video processing failed with error code: %d", result)
+		return engineError(int32(result), errBuf)
 	}
 
 	return nil
 }
+
+// engineShapes lists the shape sprites for the engine in a stable order, and
+// the moments the cursor switches sprite, timed from the first movement
+// sample as the engine times the cursor path. Shapes without a sprite use
+// the main one. The returned function frees the sprite paths.
+func engineShapes(moves []tracking.CursorPosition, sprites map[cursorshape.Shape]ShapeSprite) ([]C.CCursorShapeSprite, []C.CShapeChange, func()) {
+	var shapes []cursorshape.Shape
+	for shape := range sprites {
+		if shape != cursorshape.Arrow {
+			shapes = append(shapes, shape)
+		}
+	}
+	if len(shapes) == 0 || len(moves) == 0 {
+		return nil, nil, func() {}
+	}
+	slices.Sort(shapes)
+
+	cSprites := make([]C.CCursorShapeSprite, len(shapes))
+	index := make(map[cursorshape.Shape]int32, len(shapes))
+	for i, shape := range shapes {
+		sprite := sprites[shape]
+		cSprites[i] = C.CCursorShapeSprite{
+			sprite_path: C.CString(sprite.Path),
+			hotspot_x:   C.float(sprite.HotspotX),
+			hotspot_y:   C.float(sprite.HotspotY),
+		}
+		index[shape] = int32(i)
+	}
+
+	var changes []C.CShapeChange
+	current := int32(-1)
+	for _, p := range moves {
+		sprite, ok := index[p.Shape]
+		if !ok {
+			sprite = -1
+		}
+		if sprite != current {
+			changes = append(changes, C.CShapeChange{
+				timestamp_ms: C.double(durationToMillis(p.ClickTimeStamp - moves[0].ClickTimeStamp)),
+				sprite:       C.int32_t(sprite),
+			})
+			current = sprite
+		}
+	}
+
+	free := func() {
+		for _, s := range cSprites {
+			C.free(unsafe.Pointer(s.sprite_path))
+		}
+	}
+	return cSprites, changes, free
+}
+
+// engineError converts a non-zero engine status and its message into an error
+func engineError(code int32, errBuf []byte) error {
+	var sentinel error
+	switch code {
+	case -1, -2:
+		sentinel = ErrInvalidInput
+	case -3:
+		sentinel = ErrSmoothingFailed
+	case -4:
+		sentinel = ErrEncodeFailed
+	case -5:
+		sentinel = ErrCursorSprite
+	default:
+		sentinel = fmt.Errorf("unknown engine error code %d", code)
+	}
+
+	msg := errBuf
+	if i := bytes.IndexByte(msg, 0); i >= 0 {
+		msg = msg[:i]
+	}
+	if len(msg) == 0 {
+		return sentinel
+	}
+	return fmt.Errorf("%w: %s", sentinel, msg)
+}
@@ -0,0 +1,439 @@
+//go:build !rustengine
+
+package video
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/vedantwpatil/Screen-Capture/internal/cursorshape"
+	"github.com/vedantwpatil/Screen-Capture/internal/ffmpegcmd"
+	"github.com/vedantwpatil/Screen-Capture/internal/media"
+	"github.com/vedantwpatil/Screen-Capture/internal/tracking"
+)
+
+// Fallback backend used when the Rust engine is not compiled in (build
+// without -tags rustengine). The cursor path is smoothed in Go and the
+// sprite is drawn by ffmpeg's overlay filter, moved each frame through a
+// sendcmd script. Slower, but needs no CGO.
+
+// Engine names the cursor renderer compiled into this build
+const Engine = "ffmpeg"
+
+// cursorCommandFile is the sendcmd script name inside the job's temp dir
+const cursorCommandFile = "cursor.cmd"
+
+// ProcessVideoWithCursor renders a video with smooth cursor overlay.
+// This function is thread-safe and can be called concurrently.
+// Cancelling ctx stops ffmpeg, removes the partial output and returns ctx.Err().
+// When plan is set its cursor path is drawn instead of smoothing mouseHistory.
+func ProcessVideoWithCursor(
+	ctx context.Context,
+	inputVideoPath string,
+	outputVideoPath string,
+	cursorSpritePath string,
+	mouseHistory []tracking.CursorPosition,
+	plan *CameraPlan,
+	config VideoConfig,
+	progressHandler func(float32),
+) error {
+	if len(mouseHistory) == 0 {
+		return fmt.Errorf("%w: no mouse history provided", ErrInvalidInput)
+	}
+	if err := config.Validate(); err != nil {
+		return err
+	}
+	if err := checkPaths(inputVideoPath, outputVideoPath, cursorSpritePath); err != nil {
+		return err
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if progressHandler == nil {
+		progressHandler = func(float32) {}
+	}
+
+	// Relative paths must survive running ffmpeg from the temp dir
+	inputAbs, err := filepath.Abs(inputVideoPath)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidInput, err)
+	}
+	outputAbs, err := filepath.Abs(outputVideoPath)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidInput, err)
+	}
+	spriteAbs, err := filepath.Abs(cursorSpritePath)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidInput, err)
+	}
+
+	info, err := media.Probe(inputAbs)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidInput, err)
+	}
+	progressHandler(0.05)
+
+	var path []tracking.Vec2
+	pathFPS := float64(config.FrameRate)
+	if plan != nil {
+		path, pathFPS = plan.CursorPath(), plan.FPS
+	} else {
+		// Clicks are not drawn by this backend; only movement shapes the path
+		moves, _ := splitCursorEvents(mouseHistory)
+		frames := int(info.Duration.Seconds()*float64(config.FrameRate)) + 1
+		path = smoothCursorPath(moves, config, frames)
+		if len(path) == 0 {
+			return fmt.Errorf("%w: no points produced from %d raw samples", ErrSmoothingFailed, len(moves))
+		}
+	}
+
+	// Overlay coordinates are the sprite's top-left, so shift by the hotspot
+	scale := config.CursorScale
+	if scale <= 0 {
+		scale = 1.0
+	}
+	hotspot := tracking.Vec2{X: config.CursorHotspotX, Y: config.CursorHotspotY}.Scale(scale)
+	for i := range path {
+		path[i] = path[i].Sub(hotspot)
+	}
+	layers, err := shapeLayers(config.CursorShapes, hotspot, scale)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrCursorSprite, err)
+	}
+	var shapes []cursorshape.Shape
+	if len(layers) > 0 {
+		shapes = frameShapes(mouseHistory, len(path), pathFPS)
+	}
+	progressHandler(0.10)
+
+	workDir, err := os.MkdirTemp("", "focusframe-cursor-*")
+	if err != nil {
+		return fmt.Errorf("failed to create work dir: %w", err)
+	}
+	defer os.RemoveAll(workDir)
+
+	// The trail is drawn in the recording's coordinates like the cursor, so
+	// the camera pass zooms both alike
+	ghosts := trailGhosts(path, pathFPS, config.TrailDuration)
+	blur := blurGhosts(path, config.MotionBlurStrength, config.MotionBlurThreshold)
+	if err := writeCursorCommands(filepath.Join(workDir, cursorCommandFile), path, shapes, layers, ghosts, blur, pathFPS); err != nil {
+		return fmt.Errorf("failed to write cursor commands: %w", err)
+	}
+	progressHandler(0.15)
+
+	// The sendcmd file is referenced relative to the work dir so its path
+	// never needs filtergraph escaping. Trail ghosts and motion blur copies
+	// are copies of the sprite at falling opacity, overlaid farthest first so
+	// the cursor stays on top. Each shape sprite gets its own overlay, which
+	// waits offscreen while the cursor has another shape.
+	var filter strings.Builder
+	fmt.Fprintf(&filter, "[0:v]fps=%d,sendcmd=f=%s[base];[1:v]scale=iw*%g:ih*%g", config.FrameRate, cursorCommandFile, scale, scale)
+	if copies := len(ghosts) + len(blur); copies > 0 {
+		fmt.Fprintf(&filter, ",format=rgba,split=%d[cursor]", copies+1)
+		for k := range copies {
+			fmt.Fprintf(&filter, "[sprite%d]", k)
+		}
+		for k := range ghosts {
+			fmt.Fprintf(&filter, ";[sprite%d]colorchannelmixer=aa=%.3f[ghost%d]", k, trailOpacity(config.TrailOpacity, k, len(ghosts)), k)
+		}
+		for k := range blur {
+			fmt.Fprintf(&filter, ";[sprite%d]colorchannelmixer=aa=%.3f[blur%d]", len(ghosts)+k, blurOpacity(k, len(blur)), k)
+		}
+	} else {
+		filter.WriteString("[cursor]")
+	}
+	below := "base"
+	for k := len(ghosts) - 1; k >= 0; k-- {
+		fmt.Fprintf(&filter, ";[%s][ghost%d]overlay@ghost%d=x=%d:y=%d:shortest=1:eval=frame:format=auto[trail%d]",
+			below, k, k, offscreen, offscreen, k)
+		below = fmt.Sprintf("trail%d", k)
+	}
+	for k := range blur {
+		fmt.Fprintf(&filter, ";[%s][blur%d]overlay@blur%d=x=%d:y=%d:shortest=1:eval=frame:format=auto[blurred%d]",
+			below, k, k, offscreen, offscreen, k)
+		below = fmt.Sprintf("blurred%d", k)
+	}
+	for j := range layers {
+		fmt.Fprintf(&filter, ";[%d:v]scale=iw*%g:ih*%g[shape%d]", j+2, scale, scale, j)
+	}
+	fmt.Fprintf(&filter, ";[%s][cursor]overlay@cursor=x=%.2f:y=%.2f:shortest=1:eval=frame:format=auto", below, path[0].X, path[0].Y)
+	for j, layer := range layers {
+		fmt.Fprintf(&filter, "[shaped%d];[shaped%d][shape%d]overlay@%s=x=%d:y=%d:shortest=1:eval=frame:format=auto",
+			j, j, j, layer.shape, offscreen, offscreen)
+	}
+	filter.WriteString("[out]")
+	encode := media.EncodeOptions{Codec: "libx264", CRF: 18, Preset: "medium", PixFmt: "yuv420p", AudioCopy: true, EvenSize: media.EvenPad}
+	if config.Preview {
+		encode.Preset = "ultrafast"
+	}
+
+	cmd := ffmpegcmd.New().
+		Global("-nostats", "-progress", "pipe:1").
+		Input(inputAbs).
+		Input(spriteAbs, "-loop", "1")
+	for _, layer := range layers {
+		cmd.Input(layer.sprite, "-loop", "1")
+	}
+	// Audio from the recording (e.g. an OBS capture) passes through untouched
+	args := cmd.
+		FilterComplex(filter.String()).
+		Map("[out]", "0:a?").
+		Output(outputAbs, encode).
+		Args()
+
+	run := exec.CommandContext(ctx, "ffmpeg", args...)
+	run.Dir = workDir
+	var stderr bytes.Buffer
+	run.Stderr = &stderr
+	stdout, err := run.StdoutPipe()
+	if err != nil {
+		return fmt.Errorf("failed to attach to ffmpeg: %w", err)
+	}
+
+	slog.Debug("Running ffmpeg cursor overlay", "args", args)
+	if err := run.Start(); err != nil {
+		return fmt.Errorf("%w: failed to start ffmpeg: %w", ErrEncodeFailed, err)
+	}
+	reportFFmpegProgress(stdout, info.Duration, func(p float32) {
+		progressHandler(0.15 + p*0.85)
+	})
+
+	if err := run.Wait(); err != nil {
+		if rmErr := os.Remove(outputAbs); rmErr != nil && !os.IsNotExist(rmErr) {
+			slog.Warn("Failed to remove partial output", "path", outputAbs, "err", rmErr)
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return fmt.Errorf("%w: %w\n%s", ErrEncodeFailed, err, ffmpegcmd.LastLines(stderr.String(), 10))
+	}
+
+	progressHandler(1.0)
+	return nil
+}
+
+// Trail ghosts are spaced at least a frame apart, and at most this many are drawn
+const maxTrailGhosts = 6
+
+// offscreen is where a ghost waits while it would sit under the cursor
+const offscreen = -10000
+
+// trailGhosts returns where each trail ghost is drawn in every frame, nearest
+// first: positions along path up to trail ago, interpolated between frames.
+// A trail shorter than a frame draws nothing.
+func trailGhosts(path []tracking.Vec2, fps float64, trail time.Duration) [][]tracking.Vec2 {
+	span := trail.Seconds() * fps // In frames
+	count := min(int(span), maxTrailGhosts)
+	if count < 1 {
+		return nil
+	}
+	ghosts := make([][]tracking.Vec2, count)
+	for k := range ghosts {
+		back := span * float64(k+1) / float64(count)
+		ghosts[k] = make([]tracking.Vec2, len(path))
+		for i := range path {
+			ghosts[k][i] = pathAt(path, float64(i)-back)
+		}
+	}
+	return ghosts
+}
+
+// pathAt interpolates the per-frame path at a fractional frame, holding the
+// first position before it starts
+func pathAt(path []tracking.Vec2, frame float64) tracking.Vec2 {
+	if frame <= 0 {
+		return path[0]
+	}
+	i := int(frame)
+	if i >= len(path)-1 {
+		return path[len(path)-1]
+	}
+	frac := frame - float64(i)
+	return path[i].Add(path[i+1].Sub(path[i]).Scale(frac))
+}
+
+// trailOpacity fades ghost k of count out linearly from the nearest at opacity
+func trailOpacity(opacity float64, k int, count int) float64 {
+	return opacity * float64(count-k) / float64(count+1)
+}
+
+// Motion blur draws at most this many copies of the sprite, as the Rust engine does
+const maxMotionBlurGhosts = 8
+
+// blurGhosts returns where each motion blur copy is drawn in every frame,
+// oldest first: spread back along the frame's travel over strength of it,
+// in frames moving faster than threshold pixels per frame, and offscreen in
+// the rest. Overlays are fixed for the whole render, so every blurred frame
+// gets as many copies as the fastest frame needs (one per 4px of trail).
+func blurGhosts(path []tracking.Vec2, strength, threshold float64) [][]tracking.Vec2 {
+	if strength <= 0 {
+		return nil
+	}
+	velocity := func(i int) tracking.Vec2 {
+		if i == 0 {
+			return tracking.Vec2{}
+		}
+		return path[i].Sub(path[i-1])
+	}
+	count := 0
+	for i := range path {
+		v := velocity(i)
+		if speed := math.Hypot(v.X, v.Y); speed > threshold {
+			count = max(count, min(int(math.Ceil(speed*strength/4)), maxMotionBlurGhosts))
+		}
+	}
+	if count == 0 {
+		return nil
+	}
+
+	hidden := tracking.Vec2{X: offscreen, Y: offscreen}
+	ghosts := make([][]tracking.Vec2, count)
+	for k := range ghosts {
+		back := float64(count-k) / float64(count) * strength // Of the frame's travel
+		ghosts[k] = make([]tracking.Vec2, len(path))
+		for i, p := range path {
+			v := velocity(i)
+			if math.Hypot(v.X, v.Y) <= threshold {
+				ghosts[k][i] = hidden
+				continue
+			}
+			ghosts[k][i] = p.Sub(v.Scale(back))
+		}
+	}
+	return ghosts
+}
+
+// blurOpacity fades motion blur copy k of count in from the oldest, up to
+// half opaque next to the cursor
+func blurOpacity(k int, count int) float64 {
+	return 0.5 * float64(k+1) / float64(count)
+}
+
+// shapeLayer is a cursor shape's sprite, drawn in place of the main one
+type shapeLayer struct {
+	shape  cursorshape.Shape
+	sprite string        // Absolute path
+	offset tracking.Vec2 // From the main sprite's overlay position to this one's, scaled
+}
+
+// shapeLayers lists the shape sprites in a stable order; hotspot is the main
+// sprite's, already scaled
+func shapeLayers(sprites map[cursorshape.Shape]ShapeSprite, hotspot tracking.Vec2, scale float64) ([]shapeLayer, error) {
+	var layers []shapeLayer
+	for shape, sprite := range sprites {
+		if shape == cursorshape.Arrow {
+			continue
+		}
+		abs, err := filepath.Abs(sprite.Path)
+		if err != nil {
+			return nil, err
+		}
+		own := tracking.Vec2{X: sprite.HotspotX, Y: sprite.HotspotY}.Scale(scale)
+		layers = append(layers, shapeLayer{shape: shape, sprite: abs, offset: hotspot.Sub(own)})
+	}
+	sort.Slice(layers, func(i, j int) bool { return layers[i].shape < layers[j].shape })
+	return layers, nil
+}
+
+// frameShapes is the cursor shape in each of frames frames: that of the last
+// movement sample at or before the frame
+func frameShapes(history []tracking.CursorPosition, frames int, fps float64) []cursorshape.Shape {
+	moves, _ := splitCursorEvents(history)
+	shapes := make([]cursorshape.Shape, frames)
+	next := 0
+	current := cursorshape.Arrow
+	for i := range shapes {
+		at := time.Duration(float64(i) / fps * float64(time.Second))
+		for next < len(moves) && moves[next].ClickTimeStamp <= at {
+			current = moves[next].Shape
+			next++
+		}
+		shapes[i] = current
+	}
+	return shapes
+}
+
+// writeCursorCommands writes a sendcmd script that moves the overlay to each
+// frame's cursor position, and each trail ghost to its own. While the cursor
+// has a shape with its own layer, that layer is moved there instead and the
+// main sprite and trail wait offscreen. Ghosts that would sit under the
+// cursor, as they all do while it rests, are moved offscreen too so they
+// don't darken it. Motion blur copies follow blur, and are hidden with the
+// main sprite. shapes may be nil when there are no layers.
+func writeCursorCommands(path string, positions []tracking.Vec2, shapes []cursorshape.Shape, layers []shapeLayer, ghosts, blur [][]tracking.Vec2, fps float64) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	hidden := tracking.Vec2{X: offscreen, Y: offscreen}
+	w := bufio.NewWriter(f)
+	for i, p := range positions {
+		ts := float64(i) / fps
+		cursor, shaped := p, false
+		var layerAt []tracking.Vec2
+		for _, layer := range layers {
+			at := hidden
+			if layer.shape == shapes[i] {
+				at, cursor, shaped = p.Add(layer.offset), hidden, true
+			}
+			layerAt = append(layerAt, at)
+		}
+
+		fmt.Fprintf(w, "%.4f overlay@cursor x %.2f, overlay@cursor y %.2f", ts, cursor.X, cursor.Y)
+		for j, layer := range layers {
+			fmt.Fprintf(w, ", overlay@%s x %.2f, overlay@%s y %.2f", layer.shape, layerAt[j].X, layer.shape, layerAt[j].Y)
+		}
+		for k, ghost := range ghosts {
+			g := ghost[i]
+			if d := g.Sub(p); shaped || math.Hypot(d.X, d.Y) < 2 {
+				g = hidden
+			}
+			fmt.Fprintf(w, ", overlay@ghost%d x %.2f, overlay@ghost%d y %.2f", k, g.X, k, g.Y)
+		}
+		for k, copies := range blur {
+			b := copies[i]
+			if shaped {
+				b = hidden
+			}
+			fmt.Fprintf(w, ", overlay@blur%d x %.2f, overlay@blur%d y %.2f", k, b.X, k, b.Y)
+		}
+		w.WriteString(";\n")
+	}
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// reportFFmpegProgress reads ffmpeg's -progress output and reports the
+// fraction of total encoded so far
+func reportFFmpegProgress(r io.Reader, total time.Duration, report func(float32)) {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		// out_time_us and out_time_ms both carry microseconds
+		key, value, ok := strings.Cut(scanner.Text(), "=")
+		if !ok || (key != "out_time_us" && key != "out_time_ms") || total <= 0 {
+			continue
+		}
+		us, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || us < 0 {
+			continue
+		}
+		p := float64(us) * float64(time.Microsecond) / float64(total)
+		report(float32(min(p, 1.0)))
+	}
+}
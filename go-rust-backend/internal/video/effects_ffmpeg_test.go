@@ -0,0 +1,200 @@
+//go:build !rustengine
+
+package video
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vedantwpatil/Screen-Capture/internal/cursorshape"
+	"github.com/vedantwpatil/Screen-Capture/internal/tracking"
+)
+
+func TestTrailGhosts(t *testing.T) {
+	path := []tracking.Vec2{{X: 0}, {X: 10}, {X: 20}, {X: 30}}
+	tests := []struct {
+		name  string
+		trail time.Duration
+		want  [][]tracking.Vec2 // Nearest ghost first
+	}{
+		{"shorter than a frame", 50 * time.Millisecond, nil},
+		{
+			// Ghosts before the path starts hold its first position
+			name:  "one ghost per frame back",
+			trail: 200 * time.Millisecond,
+			want: [][]tracking.Vec2{
+				{{X: 0}, {X: 0}, {X: 10}, {X: 20}},
+				{{X: 0}, {X: 0}, {X: 0}, {X: 10}},
+			},
+		},
+		{
+			name:  "between frames",
+			trail: 150 * time.Millisecond,
+			want:  [][]tracking.Vec2{{{X: 0}, {X: 0}, {X: 5}, {X: 15}}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ghosts := trailGhosts(path, 10, tt.trail)
+			if len(ghosts) != len(tt.want) {
+				t.Fatalf("got %d ghosts, want %d", len(ghosts), len(tt.want))
+			}
+			for k := range tt.want {
+				for i := range tt.want[k] {
+					if !near(ghosts[k][i], tt.want[k][i]) {
+						t.Errorf("ghost %d frame %d at %v, want %v", k, i, ghosts[k][i], tt.want[k][i])
+					}
+				}
+			}
+		})
+	}
+
+	// A second at 60fps is capped, with the farthest ghost a full second back
+	long := make([]tracking.Vec2, 120)
+	for i := range long {
+		long[i] = tracking.Vec2{X: float64(i)}
+	}
+	ghosts := trailGhosts(long, 60, time.Second)
+	if len(ghosts) != maxTrailGhosts {
+		t.Fatalf("got %d ghosts, want %d", len(ghosts), maxTrailGhosts)
+	}
+	if got := ghosts[maxTrailGhosts-1][119]; !near(got, tracking.Vec2{X: 59}) {
+		t.Errorf("farthest ghost at %v, want (59, 0)", got)
+	}
+
+	for k, want := range []float64{0.5 * 2 / 3, 0.5 / 3} {
+		if got := trailOpacity(0.5, k, 2); math.Abs(got-want) > 1e-9 {
+			t.Errorf("ghost %d opacity %g, want %g", k, got, want)
+		}
+	}
+}
+
+func TestFrameShapes(t *testing.T) {
+	move := func(ms int, shape cursorshape.Shape) tracking.CursorPosition {
+		return tracking.CursorPosition{ClickTimeStamp: time.Duration(ms) * time.Millisecond, Shape: shape}
+	}
+	history := []tracking.CursorPosition{
+		move(0, cursorshape.Arrow),
+		move(100, cursorshape.IBeam),
+		// Clicks don't carry the shape into the render
+		{Click: true, ClickTimeStamp: 150 * time.Millisecond, Shape: cursorshape.Hand},
+		move(250, cursorshape.Hand),
+	}
+	want := []cursorshape.Shape{cursorshape.Arrow, cursorshape.IBeam, cursorshape.IBeam, cursorshape.Hand, cursorshape.Hand}
+	got := frameShapes(history, len(want), 10)
+	if !slices.Equal(got, want) {
+		t.Errorf("frameShapes = %v, want %v", got, want)
+	}
+
+	// Before the first sample the cursor is an arrow
+	late := []tracking.CursorPosition{move(150, cursorshape.Hand)}
+	if got := frameShapes(late, 3, 10); !slices.Equal(got, []cursorshape.Shape{cursorshape.Arrow, cursorshape.Arrow, cursorshape.Hand}) {
+		t.Errorf("frameShapes before the first sample = %v", got)
+	}
+}
+
+func TestWriteCursorCommands(t *testing.T) {
+	positions := []tracking.Vec2{{X: 0, Y: 0}, {X: 10, Y: 0}, {X: 11, Y: 0}}
+	shapes := []cursorshape.Shape{cursorshape.Arrow, cursorshape.IBeam, cursorshape.Arrow}
+	layers := []shapeLayer{{shape: cursorshape.IBeam, offset: tracking.Vec2{X: 2, Y: 3}}}
+	ghosts := [][]tracking.Vec2{{{X: 0}, {X: 0}, {X: 5}}}
+	file := filepath.Join(t.TempDir(), cursorCommandFile)
+	if err := writeCursorCommands(file, positions, shapes, layers, ghosts, nil, 30); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := strings.Join([]string{
+		// The ghost sits under the resting cursor, so it waits offscreen
+		"0.0000 overlay@cursor x 0.00, overlay@cursor y 0.00, overlay@ibeam x -10000.00, overlay@ibeam y -10000.00, overlay@ghost0 x -10000.00, overlay@ghost0 y -10000.00;",
+		// The I-beam layer takes over, shifted to its own hotspot
+		"0.0333 overlay@cursor x -10000.00, overlay@cursor y -10000.00, overlay@ibeam x 12.00, overlay@ibeam y 3.00, overlay@ghost0 x -10000.00, overlay@ghost0 y -10000.00;",
+		"0.0667 overlay@cursor x 11.00, overlay@cursor y 0.00, overlay@ibeam x -10000.00, overlay@ibeam y -10000.00, overlay@ghost0 x 5.00, overlay@ghost0 y 0.00;",
+	}, "\n") + "\n"
+	if got := string(data); got != want {
+		t.Errorf("sendcmd script:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestBlurGhosts(t *testing.T) {
+	// 20px in frame 1 is fast; 2px in frame 2 is not
+	path := []tracking.Vec2{{X: 0, Y: 0}, {X: 20, Y: 0}, {X: 22, Y: 0}}
+	ghosts := blurGhosts(path, 0.5, 8)
+
+	// A 10px trail is three copies, oldest first, spread back over it
+	hidden := tracking.Vec2{X: offscreen, Y: offscreen}
+	want := [][]tracking.Vec2{
+		{hidden, {X: 10, Y: 0}, hidden},
+		{hidden, {X: 20 - 20.0/3, Y: 0}, hidden},
+		{hidden, {X: 20 - 20.0/6, Y: 0}, hidden},
+	}
+	if len(ghosts) != len(want) {
+		t.Fatalf("got %d copies, want %d", len(ghosts), len(want))
+	}
+	for k := range want {
+		for i := range want[k] {
+			if !near(ghosts[k][i], want[k][i]) {
+				t.Errorf("copy %d frame %d at %v, want %v", k, i, ghosts[k][i], want[k][i])
+			}
+		}
+	}
+	for k, want := range []float64{1.0 / 6, 1.0 / 3, 0.5} {
+		if got := blurOpacity(k, 3); math.Abs(got-want) > 1e-9 {
+			t.Errorf("copy %d opacity %g, want %g", k, got, want)
+		}
+	}
+}
+
+func TestBlurGhostsDisabled(t *testing.T) {
+	path := []tracking.Vec2{{X: 0, Y: 0}, {X: 500, Y: 0}, {X: 501, Y: 0}}
+	tests := []struct {
+		name                string
+		strength, threshold float64
+	}{
+		{"zero strength", 0, 8},
+		{"never faster than the threshold", 1, 500},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if ghosts := blurGhosts(path, tt.strength, tt.threshold); ghosts != nil {
+				t.Errorf("got %d copies, want none", len(ghosts))
+			}
+		})
+	}
+
+	// However fast, at most maxMotionBlurGhosts copies
+	if ghosts := blurGhosts(path, 1, 0); len(ghosts) != maxMotionBlurGhosts {
+		t.Errorf("got %d copies, want %d", len(ghosts), maxMotionBlurGhosts)
+	}
+}
+
+func TestWriteCursorCommandsMovesBlur(t *testing.T) {
+	path := []tracking.Vec2{{X: 0, Y: 0}, {X: 20, Y: 0}}
+	blur := blurGhosts(path, 0.5, 8)
+	file := filepath.Join(t.TempDir(), cursorCommandFile)
+	if err := writeCursorCommands(file, path, nil, nil, nil, blur, 30); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("got %d commands, want 2:\n%s", len(lines), data)
+	}
+	if want := "overlay@blur0 x -10000.00, overlay@blur0 y -10000.00"; !strings.Contains(lines[0], want) {
+		t.Errorf("resting frame %q does not hide the blur (%s)", lines[0], want)
+	}
+	if want := "overlay@blur0 x 10.00, overlay@blur0 y 0.00"; !strings.Contains(lines[1], want) {
+		t.Errorf("fast frame %q does not draw the oldest copy (%s)", lines[1], want)
+	}
+}
@@ -0,0 +1,171 @@
+package video
+
+import (
+	"math"
+	"time"
+
+	"github.com/vedantwpatil/Screen-Capture/internal/config"
+	"github.com/vedantwpatil/Screen-Capture/internal/easing"
+	"github.com/vedantwpatil/Screen-Capture/internal/tracking"
+)
+
+// followLag is the time constant of the camera easing towards where the dead
+// zone wants it; the cursor itself is already spring-smoothed
+const followLag = 300 * time.Millisecond
+
+// releaseTime is how long an early zoom-out takes once the cursor leaves
+// the view after a click
+const releaseTime = 400 * time.Millisecond
+
+// cameraOptions shape the camera for one video
+type cameraOptions struct {
+	Width     int // Frame size in pixels
+	Height    int
+	FPS       float64
+	Zoom      float64       // Magnification between clicks; 1 shows the whole frame
+	ClickZoom float64       // Magnification at a click
+	Window    time.Duration // Around each click the zoom moves to ClickZoom and the dead zone shrinks to nothing
+	DeadZone  float64       // Share of the view the cursor can roam between clicks without a pan
+	Release   float64       // Share of the view the cursor can reach after a click before the zoom eases out early; 0 never does
+	Easing    easing.Curve  // Shapes the zoom into and out of a click
+}
+
+// cameraOptionsFrom reads the follow and zoom effects. The click zoom
+// magnifies on top of the follow zoom; with follow off the camera only moves
+// in around clicks, and with zoom off it keeps the follow zoom throughout.
+func cameraOptionsFrom(cfg *config.Config, width int, height int, fps float64) cameraOptions {
+	opts := cameraOptions{
+		Width:    width,
+		Height:   height,
+		FPS:      fps,
+		Zoom:     1,
+		Window:   time.Duration(cfg.Effects.Follow.Window * float64(time.Second)),
+		DeadZone: cfg.Effects.Follow.DeadZone,
+		Release:  cfg.Effects.Follow.Release,
+		Easing:   easing.Smoothstep,
+	}
+	if curve, err := easing.Parse(cfg.Effects.Zoom.Easing); err == nil {
+		opts.Easing = curve
+	}
+	if cfg.Effects.Follow.Enabled {
+		opts.Zoom = cfg.Effects.Follow.Zoom
+	}
+	opts.ClickZoom = opts.Zoom
+	if cfg.Effects.Zoom.Enabled {
+		opts.ClickZoom = opts.Zoom * cfg.Effects.Zoom.Factor
+	}
+	return opts
+}
+
+// planCamera moves the camera along path, the cursor position per frame.
+// clicks are click times measured from the first frame, in order. The view
+// lags behind the cursor, only pans once the cursor leaves the dead zone,
+// and never leaves the frame. When the cursor runs out of the release share
+// of the view after a click, the click zoom eases out over releaseTime
+// instead of holding for the rest of the window, so the camera doesn't stay
+// on the click while the action moves elsewhere.
+func planCamera(path []tracking.Vec2, clicks []time.Duration, opts cameraOptions) []CameraFrame {
+	if len(path) == 0 || opts.Width <= 0 || opts.Height <= 0 || opts.FPS <= 0 {
+		return nil
+	}
+	baseZoom := min(max(opts.Zoom, 1), maxCameraZoom)
+	ease := opts.Easing
+	if ease == nil {
+		ease = easing.Smoothstep
+	}
+	clickZoom := min(max(opts.ClickZoom, baseZoom), maxCameraZoom)
+
+	// clamp keeps a view of the given zoom, centred on c, inside the frame
+	clamp := func(c tracking.Vec2, zoom float64) tracking.Vec2 {
+		halfW, halfH := float64(opts.Width)/zoom/2, float64(opts.Height)/zoom/2
+		return tracking.Vec2{
+			X: math.Min(math.Max(c.X, halfW), float64(opts.Width)-halfW),
+			Y: math.Min(math.Max(c.Y, halfH), float64(opts.Height)-halfH),
+		}
+	}
+
+	dt := 1 / opts.FPS
+	follow := 1 - math.Exp(-dt/followLag.Seconds())
+	camera := clamp(path[0], baseZoom)
+	frames := make([]CameraFrame, len(path))
+	next := 0 // First click not yet behind the current frame
+	hold, releasing := 1.0, false
+	halfW, halfH := float64(opts.Width)/baseZoom/2, float64(opts.Height)/baseZoom/2
+	for i, cursor := range path {
+		t := time.Duration(float64(i) * dt * float64(time.Second))
+		passed := next
+		for next < len(clicks) && clicks[next] < t {
+			next++
+		}
+		approach, leave := clickTightness(clicks, next, t, opts.Window)
+
+		// Each click starts a fresh hold; it is released once the cursor
+		// leaves the view it zoomed into
+		if next != passed {
+			hold, releasing = 1, false
+		}
+		if opts.Release > 0 && leave > 0 && !releasing {
+			d := cursor.Sub(camera)
+			releasing = math.Abs(d.X) > opts.Release*halfW || math.Abs(d.Y) > opts.Release*halfH
+		}
+		if releasing {
+			hold = max(hold-dt/releaseTime.Seconds(), 0)
+		}
+		tight := max(approach, leave*hold)
+
+		zoom := baseZoom + (clickZoom-baseZoom)*ease(tight)
+		halfW, halfH = float64(opts.Width)/zoom/2, float64(opts.Height)/zoom/2
+		zone := opts.DeadZone * (1 - tight)
+
+		// Where the camera needs to be for the cursor to sit inside the zone
+		target := camera
+		target.X = intoZone(target.X, cursor.X, zone*halfW)
+		target.Y = intoZone(target.Y, cursor.Y, zone*halfH)
+		camera = clamp(camera.Add(target.Sub(camera).Scale(follow)), zoom)
+
+		frames[i] = CameraFrame{
+			CropX:   camera.X - halfW,
+			CropY:   camera.Y - halfH,
+			Zoom:    zoom,
+			CursorX: cursor.X,
+			CursorY: cursor.Y,
+		}
+	}
+	return frames
+}
+
+// clickTightness is 1 at a click, falling linearly to 0 at window away from
+// it: approach for the click ahead of t, leave for the one behind. The
+// nearest click's is the larger. clicks[next] is the first click at or
+// after t.
+func clickTightness(clicks []time.Duration, next int, t time.Duration, window time.Duration) (approach, leave float64) {
+	if window <= 0 {
+		return 0, 0
+	}
+	ramp := func(away time.Duration) float64 {
+		return max(1-float64(away)/float64(window), 0)
+	}
+	if next < len(clicks) {
+		approach = ramp(clicks[next] - t)
+	}
+	if next > 0 {
+		leave = ramp(t - clicks[next-1])
+	}
+	return approach, leave
+}
+
+// intoZone moves center just far enough that point lies within half of it
+func intoZone(center, point, half float64) float64 {
+	switch {
+	case point > center+half:
+		return point - half
+	case point < center-half:
+		return point + half
+	default:
+		return center
+	}
+}
+
+func evenFloor(v float64) int {
+	return int(v) &^ 1
+}
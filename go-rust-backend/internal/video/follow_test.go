@@ -0,0 +1,106 @@
+package video
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/vedantwpatil/Screen-Capture/internal/tracking"
+)
+
+// fleePath holds the cursor at from until frame leave, then jumps to to
+func fleePath(from, to tracking.Vec2, leave, n int) []tracking.Vec2 {
+	return append(stillPath(from, leave), stillPath(to, n-leave)...)
+}
+
+// frameAt is the frame shown at t
+func frameAt(frames []CameraFrame, fps float64, t time.Duration) CameraFrame {
+	return frames[int(math.Round(t.Seconds()*fps))]
+}
+
+func TestPlanCameraRelease(t *testing.T) {
+	opts := testCameraOptions()
+	center := tracking.Vec2{X: 960, Y: 540}
+	corner := tracking.Vec2{X: 1700, Y: 850}
+	const n = 120 // 4s
+	click := []time.Duration{time.Second}
+	fleeAt := 33 // 1.1s
+
+	stay := planCamera(stillPath(center, n), click, opts)
+	flee := planCamera(fleePath(center, corner, fleeAt, n), click, opts)
+	checkInFrame(t, flee, opts)
+
+	// Until the cursor leaves, fleeing changes nothing
+	for i := 0; i < fleeAt; i++ {
+		if flee[i].Zoom != stay[i].Zoom {
+			t.Fatalf("frame %d: zoom %g before the cursor left, want %g", i, flee[i].Zoom, stay[i].Zoom)
+		}
+	}
+	// The held click eases out over releaseTime instead of the rest of the window
+	released := time.Duration(fleeAt)*time.Second/30 + releaseTime + 2*time.Second/30
+	if z := frameAt(flee, opts.FPS, released).Zoom; z != opts.Zoom {
+		t.Errorf("zoom %g at %v, want the base %g once released", z, released, opts.Zoom)
+	}
+	if z := frameAt(stay, opts.FPS, released).Zoom; z <= opts.Zoom+0.1 {
+		t.Errorf("zoom %g at %v with the cursor still, want the click still held", z, released)
+	}
+	// The release never zooms out faster than releaseTime allows
+	for i := fleeAt + 1; i < n; i++ {
+		step := flee[i-1].Zoom - flee[i].Zoom
+		if most := (opts.ClickZoom - opts.Zoom) * 2.0 / (releaseTime.Seconds() * opts.FPS); step > most {
+			t.Fatalf("frame %d: zoom fell %g in one frame", i, step)
+		}
+	}
+	// Past the window both are back at the base zoom
+	if z := flee[n-1].Zoom; z != opts.Zoom {
+		t.Errorf("final zoom %g, want %g", z, opts.Zoom)
+	}
+}
+
+func TestPlanCameraReleaseOff(t *testing.T) {
+	opts := testCameraOptions()
+	opts.Release = 0
+	center := tracking.Vec2{X: 960, Y: 540}
+	click := []time.Duration{time.Second}
+
+	stay := planCamera(stillPath(center, 120), click, opts)
+	flee := planCamera(fleePath(center, tracking.Vec2{X: 1900, Y: 1000}, 33, 120), click, opts)
+	for i := range stay {
+		if flee[i].Zoom != stay[i].Zoom {
+			t.Fatalf("frame %d: zoom %g, want %g; a zero release must always hold", i, flee[i].Zoom, stay[i].Zoom)
+		}
+	}
+}
+
+func TestPlanCameraReleaseSmallMoves(t *testing.T) {
+	// Moving within the release share of the view keeps the hold
+	opts := testCameraOptions()
+	center := tracking.Vec2{X: 960, Y: 540}
+	click := []time.Duration{time.Second}
+	nearby := center.Add(tracking.Vec2{X: 200, Y: 100}) // Well inside 0.8 of a 2.25x view
+
+	stay := planCamera(stillPath(center, 120), click, opts)
+	wander := planCamera(fleePath(center, nearby, 33, 120), click, opts)
+	for i := range stay {
+		if wander[i].Zoom != stay[i].Zoom {
+			t.Fatalf("frame %d: zoom %g, want %g; the cursor never left the view", i, wander[i].Zoom, stay[i].Zoom)
+		}
+	}
+}
+
+func TestPlanCameraNextClickHoldsAgain(t *testing.T) {
+	// After fleeing one click, the next click zooms in and holds as usual
+	opts := testCameraOptions()
+	center := tracking.Vec2{X: 960, Y: 540}
+	corner := tracking.Vec2{X: 1700, Y: 850}
+	clicks := []time.Duration{time.Second, 2500 * time.Millisecond}
+
+	frames := planCamera(fleePath(center, corner, 33, 150), clicks, opts)
+	checkInFrame(t, frames, opts)
+	if z := frameAt(frames, opts.FPS, 2500*time.Millisecond).Zoom; math.Abs(z-opts.ClickZoom) > 1e-9 {
+		t.Errorf("zoom %g at the second click, want %g", z, opts.ClickZoom)
+	}
+	if z := frameAt(frames, opts.FPS, 2700*time.Millisecond).Zoom; z <= opts.Zoom+0.5 {
+		t.Errorf("zoom %g just after the second click, want it still held", z)
+	}
+}
@@ -0,0 +1,86 @@
+package video
+
+import (
+	"context"
+
+	"github.com/vedantwpatil/Screen-Capture/internal/tracking"
+)
+
+// ProcessOptions describes one cursor render for StartProcessing
+type ProcessOptions struct {
+	InputPath        string
+	OutputPath       string
+	CursorSpritePath string
+	MouseHistory     []tracking.CursorPosition
+	Config           VideoConfig
+
+	// Plan, when set, supplies the smoothed cursor path so the cursor lines
+	// up with the camera. The Rust engine smooths the history itself.
+	Plan *CameraPlan
+}
+
+// Job is a render running in the background.
+// Progress is closed when the render ends, after which Done yields its result.
+type Job struct {
+	progress chan float32
+	done     chan error
+	cancel   context.CancelFunc
+}
+
+// progressBuffer bounds how far progress may run ahead of a slow reader;
+// older values are dropped rather than stalling the render
+const progressBuffer = 16
+
+// StartProcessing runs ProcessVideoWithCursor in a goroutine and returns
+// immediately. Cancelling ctx or calling Cancel aborts the render.
+func StartProcessing(ctx context.Context, opts ProcessOptions) (*Job, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	jobCtx, cancel := context.WithCancel(ctx)
+	job := &Job{
+		progress: make(chan float32, progressBuffer),
+		done:     make(chan error, 1),
+		cancel:   cancel,
+	}
+
+	go func() {
+		defer cancel()
+		err := ProcessVideoWithCursor(
+			jobCtx,
+			opts.InputPath,
+			opts.OutputPath,
+			opts.CursorSpritePath,
+			opts.MouseHistory,
+			opts.Plan,
+			opts.Config,
+			func(p float32) {
+				select {
+				case job.progress <- p:
+				default: // Reader is behind; the next update supersedes this one
+				}
+			},
+		)
+		close(job.progress)
+		job.done <- err
+		close(job.done)
+	}()
+
+	return job, nil
+}
+
+// Progress reports completion from 0 to 1
+func (j *Job) Progress() <-chan float32 {
+	return j.progress
+}
+
+// Done yields the render's error (nil on success) once it has finished
+func (j *Job) Done() <-chan error {
+	return j.done
+}
+
+// Cancel aborts the render; Done then yields context.Canceled
+func (j *Job) Cancel() {
+	j.cancel()
+}
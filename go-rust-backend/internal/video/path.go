@@ -0,0 +1,116 @@
+package video
+
+import (
+	"math"
+	"time"
+
+	"github.com/vedantwpatil/Screen-Capture/internal/tracking"
+)
+
+// ClickOffsets returns when each click happens in the rendered video, in
+// order. Frame 0 is the first movement sample, as in the cursor render.
+func ClickOffsets(history []tracking.CursorPosition) []time.Duration {
+	moves, clicks := splitCursorEvents(history)
+	if len(moves) == 0 {
+		return nil
+	}
+	start := moves[0].ClickTimeStamp
+	offsets := make([]time.Duration, 0, len(clicks))
+	for _, click := range clicks {
+		offsets = append(offsets, click.ClickTimeStamp-start)
+	}
+	return offsets
+}
+
+// smoothCursorPath samples the recorded cursor once per output frame along a
+// spline (SmoothingAlpha) and runs it through the same spring model as the
+// Rust engine (tension and friction derived from Responsiveness and
+// Smoothness)
+func smoothCursorPath(history []tracking.CursorPosition, config VideoConfig, frames int) []tracking.Vec2 {
+	if len(history) == 0 || frames <= 0 || config.FrameRate <= 0 {
+		return nil
+	}
+
+	tension := springTension(config.Responsiveness)
+	friction := springFriction(config.Smoothness)
+
+	// Sub-steps keep the explicit integration stable at high tension
+	const subSteps = 4
+	dt := 1.0 / float64(config.FrameRate) / subSteps
+
+	start := history[0].ClickTimeStamp
+	pos := history[0].Position()
+	var vel tracking.Vec2
+
+	path := make([]tracking.Vec2, frames)
+	for i := range path {
+		t := start + time.Duration(float64(i)/float64(config.FrameRate)*float64(time.Second))
+		target := positionAt(history, t, config.SmoothingAlpha)
+		for s := 0; s < subSteps; s++ {
+			force := target.Sub(pos).Scale(tension).Sub(vel.Scale(friction))
+			vel = vel.Add(force.Scale(dt))
+			pos = pos.Add(vel.Scale(dt))
+		}
+		path[i] = pos
+	}
+	return path
+}
+
+// positionAt samples the recorded cursor at time t along a Catmull-Rom
+// spline through the samples, parameterised by alpha (0.5 is centripetal,
+// which can't loop or overshoot at sharp turns)
+func positionAt(history []tracking.CursorPosition, t time.Duration, alpha float64) tracking.Vec2 {
+	if t <= history[0].ClickTimeStamp {
+		return history[0].Position()
+	}
+	for i := 1; i < len(history); i++ {
+		next := history[i]
+		if t > next.ClickTimeStamp {
+			continue
+		}
+		prev := history[i-1]
+		span := next.ClickTimeStamp - prev.ClickTimeStamp
+		if span <= 0 {
+			return next.Position()
+		}
+		// The ends reuse their own sample as the missing neighbour
+		before, after := prev, next
+		if i >= 2 {
+			before = history[i-2]
+		}
+		if i+1 < len(history) {
+			after = history[i+1]
+		}
+		frac := float64(t-prev.ClickTimeStamp) / float64(span)
+		return catmullRom(before.Position(), prev.Position(), next.Position(), after.Position(), frac, alpha)
+	}
+	return history[len(history)-1].Position()
+}
+
+// catmullRom evaluates the segment from p1 to p2 at u in [0, 1], using the
+// Barry-Goldman form so knot spacing can follow the distance between points
+func catmullRom(p0, p1, p2, p3 tracking.Vec2, u float64, alpha float64) tracking.Vec2 {
+	if p1 == p2 {
+		return p1
+	}
+	// Coincident samples would give equal knots; a tiny floor keeps the
+	// divisions below finite
+	knot := func(t float64, a, b tracking.Vec2) float64 {
+		return t + math.Max(math.Pow(math.Hypot(b.X-a.X, b.Y-a.Y), alpha), 1e-4)
+	}
+	t0 := 0.0
+	t1 := knot(t0, p0, p1)
+	t2 := knot(t1, p1, p2)
+	t3 := knot(t2, p2, p3)
+	t := t1 + (t2-t1)*u
+
+	lerp := func(a, b tracking.Vec2, ta, tb float64) tracking.Vec2 {
+		return a.Scale((tb - t) / (tb - ta)).Add(b.Scale((t - ta) / (tb - ta)))
+	}
+	a1 := lerp(p0, p1, t0, t1)
+	a2 := lerp(p1, p2, t1, t2)
+	a3 := lerp(p2, p3, t2, t3)
+	b1 := lerp(a1, a2, t0, t2)
+	b2 := lerp(a2, a3, t1, t3)
+	return lerp(b1, b2, t1, t2)
+}
@@ -0,0 +1,122 @@
+package video
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/vedantwpatil/Screen-Capture/internal/tracking"
+)
+
+func near(a, b tracking.Vec2) bool {
+	return math.Abs(a.X-b.X) < 1e-9 && math.Abs(a.Y-b.Y) < 1e-9
+}
+
+// sample is a movement sample at ms milliseconds
+func sample(ms int, x, y int16) tracking.CursorPosition {
+	return tracking.CursorPosition{X: x, Y: y, ClickTimeStamp: time.Duration(ms) * time.Millisecond}
+}
+
+func TestCatmullRom(t *testing.T) {
+	p0, p1, p2, p3 := tracking.Vec2{X: 0, Y: 0}, tracking.Vec2{X: 1, Y: 1}, tracking.Vec2{X: 3, Y: 1}, tracking.Vec2{X: 4, Y: 0}
+	tests := []struct {
+		name  string
+		u     float64
+		alpha float64
+		want  tracking.Vec2
+	}{
+		{"starts at p1", 0, 0.5, p1},
+		{"ends at p2", 1, 0.5, p2},
+		// Uniform: (-p0 + 9p1 + 9p2 - p3) / 16
+		{"uniform midpoint", 0.5, 0, tracking.Vec2{X: 2, Y: 1.125}},
+		// Knots 0, 2^0.25, 2^0.25 + 2^0.5, 2·2^0.25 + 2^0.5
+		{"centripetal midpoint", 0.5, 0.5, tracking.Vec2{X: 2, Y: 1.1614983745349439}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := catmullRom(p0, p1, p2, p3, tt.u, tt.alpha); !near(got, tt.want) {
+				t.Errorf("catmullRom(u=%g, alpha=%g) = %v, want %v", tt.u, tt.alpha, got, tt.want)
+			}
+		})
+	}
+
+	// Evenly spaced points on a line stay on it at any alpha
+	line := func(x float64) tracking.Vec2 { return tracking.Vec2{X: x} }
+	for _, alpha := range []float64{0, 0.5, 1} {
+		if got := catmullRom(line(0), line(1), line(2), line(3), 0.25, alpha); !near(got, line(1.25)) {
+			t.Errorf("alpha %g: straight line bent to %v", alpha, got)
+		}
+	}
+}
+
+func TestPositionAt(t *testing.T) {
+	history := []tracking.CursorPosition{
+		sample(100, 0, 0),
+		sample(200, 100, 0),
+		sample(300, 200, 100),
+		sample(300, 250, 100), // Same instant as the one before
+		sample(400, 300, 100),
+	}
+	tests := []struct {
+		name string
+		ms   int
+		want tracking.Vec2
+	}{
+		{"before the first sample", 0, tracking.Vec2{X: 0, Y: 0}},
+		{"on a sample", 200, tracking.Vec2{X: 100, Y: 0}},
+		// The first segment reuses (0,0) as its missing neighbour:
+		// (-0 + 9·0 + 9·100 - 200) / 16, (-0 + 0 + 0 - 100) / 16
+		{"first segment", 150, tracking.Vec2{X: 43.75, Y: -6.25}},
+		// (-0 + 9·100 + 9·200 - 250) / 16, (0 + 0 + 900 - 100) / 16
+		{"middle segment", 250, tracking.Vec2{X: 153.125, Y: 50}},
+		// The segment after the repeat starts from the later sample:
+		// (-200 + 9·250 + 9·300 - 300) / 16
+		{"after a repeated timestamp", 350, tracking.Vec2{X: 278.125, Y: 100}},
+		{"after the last sample", 500, tracking.Vec2{X: 300, Y: 100}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Uniform knots, so the expected values can be worked by hand
+			at := time.Duration(tt.ms) * time.Millisecond
+			if got := positionAt(history, at, 0); !near(got, tt.want) {
+				t.Errorf("positionAt(%dms) = %v, want %v", tt.ms, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSmoothCursorPath(t *testing.T) {
+	config := VideoConfig{FrameRate: 10, Responsiveness: 0, Smoothness: 0} // Tension 50, friction 5
+	history := []tracking.CursorPosition{sample(0, 0, 0), sample(100, 100, 0)}
+
+	path := smoothCursorPath(history, config, 40)
+	if len(path) != 40 {
+		t.Fatalf("got %d frames, want 40", len(path))
+	}
+	if !near(path[0], tracking.Vec2{}) {
+		t.Errorf("frame 0 at %v, want the first sample", path[0])
+	}
+	// Four 25ms steps of the spring from rest towards x = 100
+	if want := (tracking.Vec2{X: 26.278972625732422}); !near(path[1], want) {
+		t.Errorf("frame 1 at %v, want %v", path[1], want)
+	}
+	// Four seconds later the spring has settled on the last sample
+	if last := path[len(path)-1]; math.Abs(last.X-100) > 0.5 || math.Abs(last.Y) > 1e-9 {
+		t.Errorf("last frame at %v, want (100, 0)", last)
+	}
+
+	for _, tt := range []struct {
+		name    string
+		history []tracking.CursorPosition
+		config  VideoConfig
+		frames  int
+	}{
+		{"no history", nil, config, 10},
+		{"no frames", history, config, 0},
+		{"no frame rate", history, VideoConfig{}, 10},
+	} {
+		if path := smoothCursorPath(tt.history, tt.config, tt.frames); path != nil {
+			t.Errorf("%s: got %d frames, want none", tt.name, len(path))
+		}
+	}
+}
@@ -1,28 +1,106 @@
 package video
 
-import "github.com/vedantwpatil/Screen-Capture/internal/tracking"
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"time"
 
-// ProcessRecording applies all video effects to a completed recording
+	"github.com/vedantwpatil/Screen-Capture/internal/config"
+	"github.com/vedantwpatil/Screen-Capture/internal/cursorshape"
+	"github.com/vedantwpatil/Screen-Capture/internal/media"
+	"github.com/vedantwpatil/Screen-Capture/internal/tracking"
+)
+
+// ProcessRecording applies all video effects to a completed recording.
+// plan, when set, is the camera plan whose cursor path the overlay follows.
 func ProcessRecording(
+	ctx context.Context,
+	cfg *config.Config,
 	inputVideoPath string,
 	outputVideoPath string,
 	mouseHistory []tracking.CursorPosition,
-	frameRate int16,
+	plan *CameraPlan,
 	progressCallback func(float32),
 ) error {
-	// Set up configuration
-	config := DefaultVideoConfig(int32(frameRate))
+	// Render at the video's own rate, which for another tool's recording or
+	// one made with different settings isn't the configured one
+	fps := cfg.Recording.TargetFPS
+	if info, err := media.Probe(inputVideoPath); err == nil && info.FPS > 0 {
+		fps = int(math.Round(info.FPS))
+	}
+	videoConfig := DefaultVideoConfig(int32(fps))
+	if !slog.Default().Enabled(ctx, slog.LevelInfo) {
+		videoConfig.LogLevel = 2 // Match quiet mode: warnings and errors only
+	}
+
+	smoothing := cfg.Effects.Smoothing
+	videoConfig.SmoothingAlpha = smoothing.SplineAlpha
+	videoConfig.Responsiveness = smoothing.Responsiveness
+	videoConfig.Smoothness = smoothing.Smoothness
+	videoConfig.MotionBlurStrength = cfg.Effects.MotionBlur.Strength
+	videoConfig.MotionBlurThreshold = cfg.Effects.MotionBlur.Threshold
+	videoConfig.Preview = cfg.IsPreview()
+
+	cursor := cfg.Effects.Cursor
+	videoConfig.CursorScale = cursor.Scale
+	videoConfig.CursorHotspotX = float64(cursor.HotspotX)
+	videoConfig.CursorHotspotY = float64(cursor.HotspotY)
+	if cursor.Trail.Enabled {
+		videoConfig.TrailDuration = time.Duration(cursor.Trail.DurationMs) * time.Millisecond
+		videoConfig.TrailOpacity = cursor.Trail.Opacity
+	}
+	if err := videoConfig.Validate(); err != nil {
+		return err
+	}
 
-	// Path to cursor sprite (adjust as needed)
-	cursorSpritePath := "internal/video/cursor-sprite.png"
+	// Resolve the sprite up front so a bad path fails before any decoding
+	cursorSpritePath, spriteSize, cleanup, err := ResolveCursorSprite(cursor.SpritePath)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+	if cursor.HotspotX >= spriteSize.X || cursor.HotspotY >= spriteSize.Y {
+		return fmt.Errorf("%w: hotspot (%d, %d) is outside the %dx%d sprite",
+			ErrCursorSprite, cursor.HotspotX, cursor.HotspotY, spriteSize.X, spriteSize.Y)
+	}
+	for name, sprite := range cursor.Shapes {
+		shape, err := cursorshape.Parse(name)
+		if err != nil {
+			return err
+		}
+		path, size, cleanup, err := ResolveCursorSprite(sprite.SpritePath)
+		if err != nil {
+			return fmt.Errorf("%s cursor: %w", shape, err)
+		}
+		defer cleanup()
+		if sprite.HotspotX >= size.X || sprite.HotspotY >= size.Y {
+			return fmt.Errorf("%w: %s cursor hotspot (%d, %d) is outside the %dx%d sprite",
+				ErrCursorSprite, shape, sprite.HotspotX, sprite.HotspotY, size.X, size.Y)
+		}
+		if videoConfig.CursorShapes == nil {
+			videoConfig.CursorShapes = make(map[cursorshape.Shape]ShapeSprite)
+		}
+		videoConfig.CursorShapes[shape] = ShapeSprite{Path: path, HotspotX: float64(sprite.HotspotX), HotspotY: float64(sprite.HotspotY)}
+	}
 
-	// Process the video
-	return ProcessVideoWithCursor(
-		inputVideoPath,
-		outputVideoPath,
-		cursorSpritePath,
-		mouseHistory,
-		config,
-		progressCallback,
-	)
+	// Process the video, forwarding progress from the background job
+	job, err := StartProcessing(ctx, ProcessOptions{
+		InputPath:        inputVideoPath,
+		OutputPath:       outputVideoPath,
+		CursorSpritePath: cursorSpritePath,
+		MouseHistory:     mouseHistory,
+		Config:           videoConfig,
+		Plan:             plan,
+	})
+	if err != nil {
+		return err
+	}
+	for p := range job.Progress() {
+		if progressCallback != nil {
+			progressCallback(p)
+		}
+	}
+	return <-job.Done()
 }
@@ -0,0 +1,90 @@
+package video
+
+import (
+	_ "embed"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+
+	"github.com/vedantwpatil/Screen-Capture/internal/config"
+)
+
+// defaultCursorSprite is used when no sprite is configured, so fresh installs
+// work regardless of the directory the binary runs from
+//
+//go:embed cursor-sprite.png
+var defaultCursorSprite []byte
+
+// ResolveCursorSprite returns a readable path for the configured sprite along
+// with its dimensions. An empty path writes the built-in sprite to a temp
+// file; call cleanup once rendering is done. Relative paths are tried against
+// the working directory first, then the executable's directory.
+func ResolveCursorSprite(path string) (resolved string, size image.Point, cleanup func(), err error) {
+	cleanup = func() {}
+
+	if path == "" {
+		f, err := os.CreateTemp("", "focusframe-cursor-*.png")
+		if err != nil {
+			return "", image.Point{}, cleanup, fmt.Errorf("failed to write built-in cursor sprite: %w", err)
+		}
+		cleanup = func() { os.Remove(f.Name()) }
+		_, writeErr := f.Write(defaultCursorSprite)
+		if closeErr := f.Close(); writeErr == nil {
+			writeErr = closeErr
+		}
+		if writeErr != nil {
+			cleanup()
+			return "", image.Point{}, func() {}, fmt.Errorf("failed to write built-in cursor sprite: %w", writeErr)
+		}
+		path = f.Name()
+	} else {
+		path = config.ResolvePath(path)
+	}
+
+	size, err = checkCursorSprite(path)
+	if err != nil {
+		cleanup()
+		return "", image.Point{}, func() {}, err
+	}
+	return path, size, cleanup, nil
+}
+
+// checkCursorSprite makes sure the sprite is a PNG with an alpha channel,
+// since an opaque sprite would paint a box over the recording
+func checkCursorSprite(path string) (image.Point, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return image.Point{}, fmt.Errorf("%w: %w", ErrCursorSprite, err)
+	}
+	defer f.Close()
+
+	cfg, err := png.DecodeConfig(f)
+	if err != nil {
+		return image.Point{}, fmt.Errorf("%w: %s is not a PNG: %w", ErrCursorSprite, path, err)
+	}
+
+	switch cfg.ColorModel {
+	case color.NRGBAModel, color.NRGBA64Model, color.RGBAModel, color.RGBA64Model:
+	default:
+		// Paletted PNGs may carry transparency in the palette
+		palette, ok := cfg.ColorModel.(color.Palette)
+		if !ok || !paletteHasAlpha(palette) {
+			return image.Point{}, fmt.Errorf("%w: %s has no alpha channel", ErrCursorSprite, path)
+		}
+	}
+	if cfg.Width == 0 || cfg.Height == 0 {
+		return image.Point{}, fmt.Errorf("%w: %s is empty", ErrCursorSprite, path)
+	}
+	return image.Pt(cfg.Width, cfg.Height), nil
+}
+
+func paletteHasAlpha(palette color.Palette) bool {
+	for _, c := range palette {
+		if _, _, _, a := c.RGBA(); a < 0xffff {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,136 @@
+package video
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/vedantwpatil/Screen-Capture/internal/ffmpegcmd"
+	"github.com/vedantwpatil/Screen-Capture/internal/media"
+)
+
+// Expectations describes how an output is allowed to differ from its input.
+// Zero values mean "same as the input".
+type Expectations struct {
+	// Duration is the expected output duration for renders that trim or retime
+	Duration time.Duration
+
+	// DurationTolerance is how far the output duration may drift (default 500ms)
+	DurationTolerance time.Duration
+
+	// Width and Height are the expected output size for renders that rescale
+	Width  int
+	Height int
+
+	// DropsAudio is set when the renderer intentionally writes no audio track
+	DropsAudio bool
+}
+
+// VerifyOutput compares the probed output against the input and reports
+// every unexpected difference at once
+func VerifyOutput(input media.Info, output media.Info, expectations Expectations) error {
+	var problems []error
+
+	if output.VideoStreams == 0 {
+		problems = append(problems, errors.New("output has no video stream"))
+	}
+
+	wantDuration := expectations.Duration
+	if wantDuration == 0 {
+		wantDuration = input.Duration
+	}
+	tolerance := expectations.DurationTolerance
+	if tolerance == 0 {
+		tolerance = 500 * time.Millisecond
+	}
+	if drift := output.Duration - wantDuration; drift > tolerance || drift < -tolerance {
+		problems = append(problems, fmt.Errorf("output duration %v differs from expected %v by more than %v",
+			output.Duration.Round(time.Millisecond), wantDuration.Round(time.Millisecond), tolerance))
+	}
+
+	wantWidth, wantHeight := expectations.Width, expectations.Height
+	if wantWidth == 0 {
+		wantWidth = input.Width
+	}
+	if wantHeight == 0 {
+		wantHeight = input.Height
+	}
+	if output.Width != wantWidth || output.Height != wantHeight {
+		problems = append(problems, fmt.Errorf("output resolution %dx%d, expected %dx%d",
+			output.Width, output.Height, wantWidth, wantHeight))
+	}
+
+	wantAudio := input.AudioStreams
+	if expectations.DropsAudio {
+		wantAudio = 0
+	}
+	if output.AudioStreams != wantAudio {
+		problems = append(problems, fmt.Errorf("output has %d audio streams, expected %d",
+			output.AudioStreams, wantAudio))
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("output verification failed: %w", errors.Join(problems...))
+	}
+	return nil
+}
+
+// MinIntermediateSSIM is the similarity a render must keep to its source
+// after the intermediate passes. Near-lossless generations stay above it;
+// a few CRF 23 generations fall visibly short.
+const MinIntermediateSSIM = 0.98
+
+// ssimScore matches the overall score in the ssim filter's summary line:
+//
+//	[Parsed_ssim_0 @ 0x7f…] SSIM Y:0.995 (23.01) U:0.998 (27.2) V:0.998 (27.4) All:0.996 (24.1)
+var ssimScore = regexp.MustCompile(`SSIM Y:.* All:([0-9.]+)`)
+
+// VerifySimilarity compares output with reference, frame by frame, and
+// fails when their SSIM is below threshold. Both must have the same frame
+// size.
+func VerifySimilarity(ctx context.Context, reference string, output string, threshold float64) error {
+	args := ffmpegcmd.New().
+		Input(output).
+		Input(reference).
+		FilterComplex("[0:v][1:v]ssim").
+		Output("-", media.EncodeOptions{}, "-f", "null").
+		Args()
+	out, err := exec.CommandContext(ctx, "ffmpeg", args...).CombinedOutput()
+	if err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return fmt.Errorf("ssim comparison failed: %w\n%s", err, ffmpegcmd.LastLines(string(out), 10))
+	}
+	score, err := parseSSIM(string(out))
+	if err != nil {
+		return err
+	}
+	return checkSSIM(score, threshold)
+}
+
+// parseSSIM returns the overall score from ffmpeg's output; the summary is
+// the last line the filter prints
+func parseSSIM(output string) (float64, error) {
+	matches := ssimScore.FindAllStringSubmatch(output, -1)
+	if len(matches) == 0 {
+		return 0, errors.New("ffmpeg printed no SSIM summary")
+	}
+	score, err := strconv.ParseFloat(matches[len(matches)-1][1], 64)
+	if err != nil || score < 0 || score > 1 {
+		return 0, fmt.Errorf("unreadable SSIM score %q", matches[len(matches)-1][1])
+	}
+	return score, nil
+}
+
+// checkSSIM fails a score below threshold
+func checkSSIM(score float64, threshold float64) error {
+	if score < threshold {
+		return fmt.Errorf("output SSIM %.4f is below %.4f: the render lost visible quality", score, threshold)
+	}
+	return nil
+}
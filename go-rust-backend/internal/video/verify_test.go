@@ -0,0 +1,203 @@
+package video
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vedantwpatil/Screen-Capture/internal/atomicfile"
+	"github.com/vedantwpatil/Screen-Capture/internal/ffmpegcmd"
+	"github.com/vedantwpatil/Screen-Capture/internal/media"
+)
+
+func TestVerifyOutput(t *testing.T) {
+	input := media.Info{Width: 1920, Height: 1080, FPS: 60, Duration: 10 * time.Second, VideoStreams: 1}
+	withAudio := input
+	withAudio.AudioStreams = 1
+
+	tests := []struct {
+		name    string
+		input   media.Info
+		output  func(o *media.Info)
+		expect  Expectations
+		wantErr string // Empty when the output should pass
+	}{
+		{name: "identical", input: input},
+		{name: "drift at the default tolerance", input: input, output: func(o *media.Info) { o.Duration += 500 * time.Millisecond }},
+		{name: "drift past the default tolerance", input: input, output: func(o *media.Info) { o.Duration += 501 * time.Millisecond }, wantErr: "duration"},
+		{name: "short at the default tolerance", input: input, output: func(o *media.Info) { o.Duration -= 500 * time.Millisecond }},
+		{name: "short past the default tolerance", input: input, output: func(o *media.Info) { o.Duration -= 501 * time.Millisecond }, wantErr: "duration"},
+		{
+			name:   "drift within a custom tolerance",
+			input:  input,
+			output: func(o *media.Info) { o.Duration += time.Second },
+			expect: Expectations{DurationTolerance: time.Second},
+		},
+		{
+			name:    "drift past a custom tolerance",
+			input:   input,
+			output:  func(o *media.Info) { o.Duration -= time.Second + time.Millisecond },
+			expect:  Expectations{DurationTolerance: time.Second},
+			wantErr: "duration",
+		},
+		{
+			name:   "expected trim",
+			input:  input,
+			output: func(o *media.Info) { o.Duration = 4 * time.Second },
+			expect: Expectations{Duration: 4 * time.Second},
+		},
+		{name: "missing video stream", input: input, output: func(o *media.Info) { o.VideoStreams = 0 }, wantErr: "no video stream"},
+		{name: "wrong size", input: input, output: func(o *media.Info) { o.Width = 1280 }, wantErr: "resolution 1280x1080"},
+		{
+			name:   "expected rescale",
+			input:  input,
+			output: func(o *media.Info) { o.Width, o.Height = 854, 480 },
+			expect: Expectations{Width: 854, Height: 480},
+		},
+		{name: "audio kept", input: withAudio},
+		{name: "audio lost", input: withAudio, output: func(o *media.Info) { o.AudioStreams = 0 }, wantErr: "0 audio streams, expected 1"},
+		{name: "audio appeared", input: input, output: func(o *media.Info) { o.AudioStreams = 1 }, wantErr: "1 audio streams, expected 0"},
+		{
+			name:   "DropsAudio with input audio",
+			input:  withAudio,
+			output: func(o *media.Info) { o.AudioStreams = 0 },
+			expect: Expectations{DropsAudio: true},
+		},
+		{
+			name:   "DropsAudio without input audio",
+			input:  input,
+			expect: Expectations{DropsAudio: true},
+		},
+		{
+			name:    "DropsAudio but audio written",
+			input:   withAudio,
+			expect:  Expectations{DropsAudio: true},
+			wantErr: "1 audio streams, expected 0",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output := tt.input
+			if tt.output != nil {
+				tt.output(&output)
+			}
+			err := VerifyOutput(tt.input, output, tt.expect)
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Errorf("VerifyOutput error = %v, want none", err)
+			case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+				t.Errorf("VerifyOutput error = %v, want one mentioning %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestVerifyOutputReportsEveryProblem(t *testing.T) {
+	input := media.Info{Width: 1920, Height: 1080, Duration: 10 * time.Second, VideoStreams: 1, AudioStreams: 1}
+	err := VerifyOutput(input, media.Info{}, Expectations{})
+	if err == nil {
+		t.Fatal("an empty output passed verification")
+	}
+	for _, want := range []string{"no video stream", "duration", "resolution", "audio"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not mention %q", err, want)
+		}
+	}
+}
+
+func TestParseSSIM(t *testing.T) {
+	const summary = "[Parsed_ssim_0 @ 0x600003d0c000] SSIM Y:0.995162 (23.152434) U:0.998321 (27.750263) V:0.998113 (27.241447) All:0.996189 (24.189079)\n"
+	tests := []struct {
+		name    string
+		output  string
+		want    float64
+		wantErr bool
+	}{
+		{"summary", summary, 0.996189, false},
+		{"after encoder noise", "Input #0, mov,mp4,m4a,3gp,3g2,mj2, from 'a.mp4':\n  Duration: 00:00:03.00\n" + summary + "video:0kB audio:0kB\n", 0.996189, false},
+		{
+			name:   "last summary wins",
+			output: "[Parsed_ssim_0 @ 0x1] SSIM Y:0.5 (3.0) U:0.5 (3.0) V:0.5 (3.0) All:0.500000 (3.0)\n" + summary,
+			want:   0.996189,
+		},
+		{"identical frames", "[Parsed_ssim_0 @ 0x1] SSIM Y:1.000000 (inf) U:1.000000 (inf) V:1.000000 (inf) All:1.000000 (inf)\n", 1, false},
+		{"no summary", "Stream mapping:\n  Stream #0:0 -> #0:0 (h264 -> wrapped_avframe)\n", 0, true},
+		{"score out of range", "SSIM Y:1.2 (inf) All:1.200000 (inf)\n", 0, true},
+		{"unreadable score", "SSIM Y:0.9 (10.0) All:0.9.1 (10.0)\n", 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseSSIM(tt.output)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseSSIM error = %v, want error %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("parseSSIM = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckSSIM(t *testing.T) {
+	tests := []struct {
+		score float64
+		ok    bool
+	}{
+		{1, true},
+		{0.996189, true},
+		{MinIntermediateSSIM, true},
+		{MinIntermediateSSIM - 0.0001, false},
+		{0.9, false},
+	}
+	for _, tt := range tests {
+		err := checkSSIM(tt.score, MinIntermediateSSIM)
+		if (err == nil) != tt.ok {
+			t.Errorf("checkSSIM(%v) = %v, want ok %v", tt.score, err, tt.ok)
+		}
+		if err != nil && !strings.Contains(err.Error(), "SSIM") {
+			t.Errorf("checkSSIM(%v) error %q doesn't name SSIM", tt.score, err)
+		}
+	}
+}
+
+// TestIntermediatePassesKeepQuality re-encodes a test pattern through three
+// near-lossless intermediate passes, as the editing pipeline does, and
+// checks the result still matches the source
+func TestIntermediatePassesKeepQuality(t *testing.T) {
+	if _, err := exec.LookPath("ffmpeg"); err != nil {
+		t.Skip("ffmpeg not installed")
+	}
+	if testing.Short() {
+		t.Skip("encodes video")
+	}
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	source := filepath.Join(dir, "source.mkv")
+	args := ffmpegcmd.New().
+		Device("lavfi", "testsrc2=size=640x360:rate=30:duration=3").
+		Output(source, media.EncodeOptions{Codec: "ffv1", PixFmt: "yuv420p"}).
+		Args()
+	if out, err := exec.Command("ffmpeg", args...).CombinedOutput(); err != nil {
+		t.Fatalf("making the test pattern: %v\n%s", err, out)
+	}
+
+	encode, ext := media.IntermediateEncodeOptions(media.IntermediateNearLossless)
+	input := source
+	for pass := range 3 {
+		output := filepath.Join(dir, fmt.Sprintf("pass%d%s", pass+1, ext))
+		args := ffmpegcmd.New().Input(input).Filter("null").Output(atomicfile.PartialPath(output), encode).Args()
+		if err := ffmpegcmd.Render(ctx, args, atomicfile.PartialPath(output), output); err != nil {
+			t.Fatalf("pass %d: %v", pass+1, err)
+		}
+		input = output
+	}
+
+	if err := VerifySimilarity(ctx, source, input, MinIntermediateSSIM); err != nil {
+		t.Error(err)
+	}
+}
@@ -11,6 +11,29 @@ typedef struct {
   double timestamp_ms;
 } CPoint;
 
+// A mouse button press. Clicks are passed separately from movement samples.
+typedef struct {
+  float x;
+  float y;
+  double timestamp_ms;
+  uint32_t button; // Button id reported by the input hook
+} CClickEvent;
+
+// A cursor shape's sprite, drawn instead of the main one while the recorded
+// cursor has that shape (an I-beam over text, a hand over links)
+typedef struct {
+  const char *sprite_path;
+  float hotspot_x; // Pointer tip in unscaled sprite pixels
+  float hotspot_y;
+} CCursorShapeSprite;
+
+// From timestamp_ms on (timed like the cursor points, from the first one) the
+// cursor is drawn with shape sprite `sprite`, or the main sprite when -1
+typedef struct {
+  double timestamp_ms;
+  int32_t sprite;
+} CShapeChange;
+
 // Smoothed path result
 typedef struct {
   CPoint *points;
@@ -24,6 +47,13 @@ typedef struct {
   float smoothness;      // 0.0 = slight overshoot, 1.0 = no overshoot (0-1)
   int32_t frame_rate;    // Video frame rate (e.g., 60)
   int32_t log_level;     // 0=off, 1=error, 2=warn, 3=info, 4=debug, 5=trace
+  float motion_blur_strength;  // 0 disables, 1 = ghosts span one frame of motion
+  float motion_blur_threshold; // Minimum cursor speed (px/frame) before blurring
+  float cursor_scale;          // Sprite size multiplier (1.0 = native)
+  float cursor_hotspot_x;      // Pointer tip in unscaled sprite pixels
+  float cursor_hotspot_y;
+  float trail_duration_ms;     // How far back the cursor trail reaches; 0 draws none
+  float trail_opacity;         // Opacity of the nearest trail ghost (0-1)
 } VideoProcessingConfig;
 
 // Progress callback function pointer type
@@ -32,20 +62,37 @@ typedef void (*ProgressCallback)(void *user_data, float percent);
 /**
  * Process video with cursor smoothing and overlay in one call.
  *
+ * On failure a human readable message is written to error_buf as a
+ * NUL-terminated string (truncated to error_buf_len). error_buf may be NULL.
+ *
+ * cancel_flag, if not NULL, is polled while frames are processed; storing a
+ * non-zero value (atomically) from another thread aborts the call with -6.
+ * The output file is left partially written and should be removed.
+ *
  * Returns:
  *   0: Success
  *  -1: Null pointer argument
  *  -2: Invalid UTF-8 in path
  *  -3: Cursor path smoothing error
  *  -4: Video rendering error
+ *  -5: Cursor sprite (or a shape sprite) could not be loaded
+ *  -6: Cancelled through cancel_flag
  */
 int32_t process_video_with_cursor(
     const char *input_video_path, const char *output_video_path,
     const char *cursor_sprite_path, const CPoint *raw_cursor_points,
-    size_t raw_cursor_points_len, const VideoProcessingConfig *config,
+    size_t raw_cursor_points_len,
+    const CClickEvent *click_events, // Can be NULL when click_events_len is 0
+    size_t click_events_len,
+    const CCursorShapeSprite *shape_sprites, // Can be NULL when shape_sprites_len is 0
+    size_t shape_sprites_len,
+    const CShapeChange *shape_changes, // In time order; NULL when shape_changes_len is 0
+    size_t shape_changes_len, const VideoProcessingConfig *config,
     ProgressCallback progress_callback, // Can be NULL
-    void *user_data                     // ADDED: Context pointer
-);
+    void *user_data,                    // ADDED: Context pointer
+    char *error_buf,                    // Can be NULL
+    size_t error_buf_len,
+    const int32_t *cancel_flag);        // Can be NULL
 
 /**
  * Smooth cursor path using Catmull-Rom splines.
@@ -7,6 +7,7 @@ mod video;
 use std::ffi::{c_char, c_void, CStr};
 use std::panic::AssertUnwindSafe;
 use std::slice;
+use std::sync::atomic::{AtomicI32, Ordering};
 
 pub use smoothing::CPoint; // Re-export for consistency
 
@@ -28,6 +29,40 @@ pub struct VideoProcessingConfig {
     pub smoothness: f32,
     pub frame_rate: i32,
     pub log_level: i32,
+    pub motion_blur_strength: f32,
+    pub motion_blur_threshold: f32,
+    pub cursor_scale: f32,
+    pub cursor_hotspot_x: f32,
+    pub cursor_hotspot_y: f32,
+    pub trail_duration_ms: f32,
+    pub trail_opacity: f32,
+}
+
+/// A mouse button press, passed separately from the movement samples
+#[repr(C)]
+#[derive(Debug, Clone, Copy)]
+pub struct CClickEvent {
+    pub x: f32,
+    pub y: f32,
+    pub timestamp_ms: f64,
+    pub button: u32,
+}
+
+/// A cursor shape's sprite, drawn instead of the main one while the cursor has that shape
+#[repr(C)]
+#[derive(Debug, Clone, Copy)]
+pub struct CCursorShapeSprite {
+    pub sprite_path: *const c_char,
+    pub hotspot_x: f32,
+    pub hotspot_y: f32,
+}
+
+/// From timestamp_ms on the cursor is drawn with shape sprite `sprite`, or the main one when -1
+#[repr(C)]
+#[derive(Debug, Clone, Copy)]
+pub struct CShapeChange {
+    pub timestamp_ms: f64,
+    pub sprite: i32,
 }
 
 type ProgressCallback = extern "C" fn(*mut c_void, f32);
@@ -39,9 +74,46 @@ type ProgressCallback = extern "C" fn(*mut c_void, f32);
 const SUCCESS: i32 = 0;
 const ERR_NULL_POINTER: i32 = -1;
 const ERR_INVALID_UTF8: i32 = -2;
-#[allow(dead_code)]
 const ERR_SMOOTHING_FAILED: i32 = -3;
 const ERR_RENDERING_FAILED: i32 = -4;
+const ERR_CURSOR_SPRITE: i32 = -5;
+const ERR_CANCELLED: i32 = -6;
+
+/// Failures from the processing pipeline, each mapped to a distinct FFI code
+#[derive(Debug, thiserror::Error)]
+enum ProcessError {
+    #[error("cursor smoothing failed: {0}")]
+    Smoothing(String),
+    #[error("failed to load cursor sprite: {0}")]
+    CursorSprite(Box<dyn std::error::Error>),
+    #[error("video rendering failed: {0}")]
+    Rendering(Box<dyn std::error::Error>),
+    #[error("processing cancelled")]
+    Cancelled,
+}
+
+impl ProcessError {
+    fn code(&self) -> i32 {
+        match self {
+            ProcessError::Smoothing(_) => ERR_SMOOTHING_FAILED,
+            ProcessError::CursorSprite(_) => ERR_CURSOR_SPRITE,
+            ProcessError::Rendering(_) => ERR_RENDERING_FAILED,
+            ProcessError::Cancelled => ERR_CANCELLED,
+        }
+    }
+}
+
+/// Copy an error message into the caller's buffer as a NUL-terminated string.
+/// The message is truncated to fit; a NULL or empty buffer is ignored.
+unsafe fn write_error_message(buf: *mut c_char, buf_len: usize, message: &str) {
+    if buf.is_null() || buf_len == 0 {
+        return;
+    }
+    let bytes = message.as_bytes();
+    let n = bytes.len().min(buf_len - 1);
+    std::ptr::copy_nonoverlapping(bytes.as_ptr(), buf as *mut u8, n);
+    *buf.add(n) = 0;
+}
 
 // ============================================================================
 // Main FFI Entry Point
@@ -54,9 +126,18 @@ pub unsafe extern "C" fn process_video_with_cursor(
     cursor_sprite_path: *const c_char,
     raw_cursor_points: *const CPoint,
     raw_cursor_points_len: usize,
+    click_events: *const CClickEvent,
+    click_events_len: usize,
+    shape_sprites: *const CCursorShapeSprite,
+    shape_sprites_len: usize,
+    shape_changes: *const CShapeChange,
+    shape_changes_len: usize,
     config: *const VideoProcessingConfig,
     progress_callback: Option<ProgressCallback>,
     user_data: *mut c_void,
+    error_buf: *mut c_char,
+    error_buf_len: usize,
+    cancel_flag: *const i32,
 ) -> i32 {
     // 1. SAFETY: Wrap the entire execution in catch_unwind
     // We use AssertUnwindSafe because we are passing raw C pointers into the closure.
@@ -69,7 +150,11 @@ pub unsafe extern "C" fn process_video_with_cursor(
             || cursor_sprite_path.is_null()
             || raw_cursor_points.is_null()
             || config.is_null()
+            || (click_events.is_null() && click_events_len > 0)
+            || (shape_sprites.is_null() && shape_sprites_len > 0)
+            || (shape_changes.is_null() && shape_changes_len > 0)
         {
+            write_error_message(error_buf, error_buf_len, "null pointer argument");
             return ERR_NULL_POINTER;
         }
 
@@ -77,15 +162,57 @@ pub unsafe extern "C" fn process_video_with_cursor(
         // Note: These borrows are valid only within this block
         let input_path = match CStr::from_ptr(input_video_path).to_str() {
             Ok(s) => s,
-            Err(_) => return ERR_INVALID_UTF8,
+            Err(_) => {
+                write_error_message(error_buf, error_buf_len, "input path is not valid UTF-8");
+                return ERR_INVALID_UTF8;
+            }
         };
         let output_path = match CStr::from_ptr(output_video_path).to_str() {
             Ok(s) => s,
-            Err(_) => return ERR_INVALID_UTF8,
+            Err(_) => {
+                write_error_message(error_buf, error_buf_len, "output path is not valid UTF-8");
+                return ERR_INVALID_UTF8;
+            }
         };
         let cursor_path = match CStr::from_ptr(cursor_sprite_path).to_str() {
             Ok(s) => s,
-            Err(_) => return ERR_INVALID_UTF8,
+            Err(_) => {
+                write_error_message(
+                    error_buf,
+                    error_buf_len,
+                    "cursor sprite path is not valid UTF-8",
+                );
+                return ERR_INVALID_UTF8;
+            }
+        };
+
+        let shape_sprites = if shape_sprites_len == 0 {
+            &[][..]
+        } else {
+            slice::from_raw_parts(shape_sprites, shape_sprites_len)
+        };
+        let mut shape_sprite_paths = Vec::with_capacity(shape_sprites.len());
+        for sprite in shape_sprites {
+            if sprite.sprite_path.is_null() {
+                write_error_message(error_buf, error_buf_len, "null shape sprite path");
+                return ERR_NULL_POINTER;
+            }
+            match CStr::from_ptr(sprite.sprite_path).to_str() {
+                Ok(s) => shape_sprite_paths.push((s, sprite.hotspot_x, sprite.hotspot_y)),
+                Err(_) => {
+                    write_error_message(
+                        error_buf,
+                        error_buf_len,
+                        "shape sprite path is not valid UTF-8",
+                    );
+                    return ERR_INVALID_UTF8;
+                }
+            }
+        }
+        let shape_changes = if shape_changes_len == 0 {
+            &[][..]
+        } else {
+            slice::from_raw_parts(shape_changes, shape_changes_len)
         };
 
         // 4. Dereference Config & Slice
@@ -94,6 +221,11 @@ pub unsafe extern "C" fn process_video_with_cursor(
 
         // Create slice from raw parts
         let raw_points = slice::from_raw_parts(raw_cursor_points, raw_cursor_points_len);
+        let clicks = if click_events_len == 0 {
+            &[][..]
+        } else {
+            slice::from_raw_parts(click_events, click_events_len)
+        };
 
         // 5. Setup Progress Callback
         let progress_reporter = ProgressReporter {
@@ -101,19 +233,31 @@ pub unsafe extern "C" fn process_video_with_cursor(
             user_data, // raw pointer, captured by AssertUnwindSafe
         };
 
+        // The flag is owned by the caller and may be flipped from another thread
+        let cancel = if cancel_flag.is_null() {
+            None
+        } else {
+            Some(&*(cancel_flag as *const AtomicI32))
+        };
+
         // 6. Run Internal Logic
         match process_video_internal(
             input_path,
             output_path,
             cursor_path,
             raw_points,
+            clicks,
+            &shape_sprite_paths,
+            shape_changes,
             cfg,
             progress_reporter,
+            cancel,
         ) {
             Ok(_) => SUCCESS,
             Err(e) => {
                 log::error!("Video processing failed: {}", e);
-                ERR_RENDERING_FAILED
+                write_error_message(error_buf, error_buf_len, &e.to_string());
+                e.code()
             }
         }
     }));
@@ -123,13 +267,19 @@ pub unsafe extern "C" fn process_video_with_cursor(
         Ok(return_code) => return_code,
         Err(e) => {
             // Log panic details if possible
-            if let Some(s) = e.downcast_ref::<&str>() {
-                log::error!("CRITICAL RUST PANIC: {}", s);
+            let cause = if let Some(s) = e.downcast_ref::<&str>() {
+                s.to_string()
             } else if let Some(s) = e.downcast_ref::<String>() {
-                log::error!("CRITICAL RUST PANIC: {}", s);
+                s.clone()
             } else {
-                log::error!("CRITICAL RUST PANIC: Unknown cause");
-            }
+                "Unknown cause".to_string()
+            };
+            log::error!("CRITICAL RUST PANIC: {}", cause);
+            write_error_message(
+                error_buf,
+                error_buf_len,
+                &format!("panic in video engine: {}", cause),
+            );
             // Ensure we return a strict error code so Go knows to abort cleanly
             ERR_RENDERING_FAILED
         }
@@ -213,14 +363,28 @@ fn process_video_internal(
     output_path: &str,
     cursor_path: &str,
     raw_points: &[CPoint],
+    clicks: &[CClickEvent],
+    shape_sprite_paths: &[(&str, f32, f32)],
+    shape_changes: &[CShapeChange],
     config: &VideoProcessingConfig,
     progress: ProgressReporter,
-) -> Result<(), Box<dyn std::error::Error>> {
+    cancel: Option<&AtomicI32>,
+) -> Result<(), ProcessError> {
     progress.report(0.05);
     log::info!(
-        "Starting processing with {} raw cursor points",
-        raw_points.len()
+        "Starting processing with {} raw cursor points and {} clicks",
+        raw_points.len(),
+        clicks.len()
     );
+    for click in clicks {
+        log::debug!(
+            "Click: button {} at ({}, {}) t={:.3}ms",
+            click.button,
+            click.x,
+            click.y,
+            click.timestamp_ms
+        );
+    }
 
     // Step 1: Smooth cursor path
     let smoothed_points = smoothing::smooth_cursor_path_dual_pass(
@@ -242,13 +406,33 @@ fn process_video_internal(
             raw_points.len(),
             config
         );
-        return Err("Cursor smoothing produced no points".into());
+        return Err(ProcessError::Smoothing(format!(
+            "no points produced from {} raw samples",
+            raw_points.len()
+        )));
     }
 
     progress.report(0.10);
 
     // Step 2: Load cursor sprite
-    let cursor_sprite = renderer::load_cursor_sprite(cursor_path)?;
+    let cursor_sprite = renderer::load_cursor_sprite(
+        cursor_path,
+        config.cursor_scale,
+        (config.cursor_hotspot_x, config.cursor_hotspot_y),
+    )
+    .map_err(ProcessError::CursorSprite)?;
+    let shape_sprites = shape_sprite_paths
+        .iter()
+        .map(|&(path, hotspot_x, hotspot_y)| {
+            renderer::load_cursor_sprite(path, config.cursor_scale, (hotspot_x, hotspot_y))
+        })
+        .collect::<Result<Vec<_>, _>>()
+        .map_err(ProcessError::CursorSprite)?;
+    let cursor = renderer::CursorArt {
+        main: &cursor_sprite,
+        shapes: &shape_sprites,
+        changes: shape_changes,
+    };
     progress.report(0.15);
 
     // Step 3: Process video
@@ -256,10 +440,18 @@ fn process_video_internal(
         input_path,
         output_path,
         &smoothed_points,
-        &cursor_sprite,
+        &cursor,
         config,
         |p| progress.report(0.15 + p * 0.85),
-    )?;
+        || cancel.is_some_and(|flag| flag.load(Ordering::Relaxed) != 0),
+    )
+    .map_err(|e| {
+        if e.is::<video::Cancelled>() {
+            ProcessError::Cancelled
+        } else {
+            ProcessError::Rendering(e)
+        }
+    })?;
 
     progress.report(1.0);
     Ok(())
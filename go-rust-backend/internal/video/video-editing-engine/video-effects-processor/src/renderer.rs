@@ -1,14 +1,66 @@
+use image::imageops::FilterType;
 use image::GenericImageView;
 use std::error::Error;
 
+use crate::CShapeChange;
+
 pub struct CursorSprite {
     pub data: Vec<u8>, // Raw RGBA8 bytes
     pub width: u32,
     pub height: u32,
+    pub hotspot_x: f32, // Pointer tip in (scaled) sprite pixels
+    pub hotspot_y: f32,
+}
+
+/// Every sprite the cursor may be drawn with, and when it switches between them
+pub struct CursorArt<'a> {
+    pub main: &'a CursorSprite,
+    pub shapes: &'a [CursorSprite],
+    pub changes: &'a [CShapeChange], // In time order
+}
+
+impl CursorArt<'_> {
+    /// The sprite for the cursor at timestamp_ms (relative to the first cursor
+    /// point), and whether it is a shape sprite rather than the main one
+    pub fn sprite_at(&self, timestamp_ms: f64) -> (&CursorSprite, bool) {
+        let n = self
+            .changes
+            .partition_point(|c| c.timestamp_ms <= timestamp_ms);
+        let shape = n
+            .checked_sub(1)
+            .and_then(|i| usize::try_from(self.changes[i].sprite).ok())
+            .and_then(|i| self.shapes.get(i));
+        match shape {
+            Some(sprite) => (sprite, true),
+            None => (self.main, false),
+        }
+    }
 }
 
-pub fn load_cursor_sprite(path: &str) -> Result<CursorSprite, Box<dyn Error>> {
-    let img = image::open(path)?;
+/// Load the sprite, resized by `scale`, with the hotspot given in unscaled pixels
+pub fn load_cursor_sprite(
+    path: &str,
+    scale: f32,
+    hotspot: (f32, f32),
+) -> Result<CursorSprite, Box<dyn Error>> {
+    let mut img = image::open(path)?;
+    if !img.color().has_alpha() {
+        return Err(format!("cursor sprite {} has no alpha channel", path).into());
+    }
+
+    // Treat unset/invalid scale as native size rather than failing the render
+    let scale = if scale.is_finite() && scale > 0.0 {
+        scale
+    } else {
+        1.0
+    };
+    if (scale - 1.0).abs() > f32::EPSILON {
+        let (w, h) = img.dimensions();
+        let scaled_w = ((w as f32 * scale).round() as u32).max(1);
+        let scaled_h = ((h as f32 * scale).round() as u32).max(1);
+        img = img.resize_exact(scaled_w, scaled_h, FilterType::Lanczos3);
+    }
+
     let (width, height) = img.dimensions();
     // Pre-convert to raw RGBA bytes for O(1) access
     let data = img.to_rgba8().into_raw();
@@ -17,6 +69,8 @@ pub fn load_cursor_sprite(path: &str) -> Result<CursorSprite, Box<dyn Error>> {
         data,
         width,
         height,
+        hotspot_x: hotspot.0 * scale,
+        hotspot_y: hotspot.1 * scale,
     })
 }
 
@@ -28,6 +82,7 @@ pub fn composite_cursor_subpixel(
     cursor: &CursorSprite,
     x: f32,
     y: f32,
+    opacity: f32,
 ) {
     // 1. Determine the integer bounding box on the FRAME
     let start_x = x.floor() as i32;
@@ -50,7 +105,7 @@ pub fn composite_cursor_subpixel(
 
             // Bilinear Sample
             if let Some((r, g, b, a)) = sample_bilinear_fast(cursor, src_x, src_y) {
-                let alpha = a as f32 / 255.0;
+                let alpha = a as f32 / 255.0 * opacity;
                 if alpha > 0.0 {
                     let idx = ((dy as u32 * frame_width + dx as u32) * 4) as usize;
                     // Standard Over Operator
@@ -64,6 +119,70 @@ pub fn composite_cursor_subpixel(
     }
 }
 
+/// Composite the cursor with directional motion blur.
+/// Ghost copies are drawn along the motion vector (oldest first, faintest first)
+/// before the sharp cursor at its current position.
+pub fn composite_cursor_motion_blur(
+    frame: &mut [u8],
+    frame_width: u32,
+    frame_height: u32,
+    cursor: &CursorSprite,
+    position: (f32, f32),
+    velocity: (f32, f32), // px per frame
+    strength: f32,
+    threshold: f32,
+) {
+    let (x, y) = position;
+    let (vx, vy) = velocity;
+    let speed = (vx * vx + vy * vy).sqrt();
+
+    if strength > 0.0 && speed > threshold {
+        // Roughly one ghost per 4px of travel, capped to keep per-frame cost bounded
+        let trail = speed * strength;
+        let ghosts = ((trail / 4.0).ceil() as usize).clamp(1, MAX_MOTION_BLUR_GHOSTS);
+
+        for i in (1..=ghosts).rev() {
+            // f = 1.0 is the oldest ghost (a full trail length behind)
+            let f = i as f32 / ghosts as f32;
+            let opacity = 0.5 * (1.0 - f + 1.0 / ghosts as f32);
+            composite_cursor_subpixel(
+                frame,
+                frame_width,
+                frame_height,
+                cursor,
+                x - vx * strength * f,
+                y - vy * strength * f,
+                opacity,
+            );
+        }
+    }
+
+    composite_cursor_subpixel(frame, frame_width, frame_height, cursor, x, y, 1.0);
+}
+
+const MAX_MOTION_BLUR_GHOSTS: usize = 8;
+
+/// Trail ghosts are spaced at least a frame apart, and at most this many are drawn
+const MAX_TRAIL_GHOSTS: usize = 6;
+
+/// How far back (ms) and how opaque each cursor trail ghost is, nearest first.
+/// Ghosts fade out linearly from the nearest at `opacity`; a trail shorter
+/// than a frame draws nothing.
+pub fn trail_ghosts(trail_ms: f64, frame_ms: f64, opacity: f32) -> Vec<(f64, f32)> {
+    if !(trail_ms > 0.0 && frame_ms > 0.0) {
+        return Vec::new();
+    }
+    let span = trail_ms / frame_ms; // In frames
+    let count = (span.floor() as usize).min(MAX_TRAIL_GHOSTS);
+    (0..count)
+        .map(|k| {
+            let back = span * (k + 1) as f64 / count as f64 * frame_ms;
+            let fade = (count - k) as f32 / (count + 1) as f32;
+            (back, opacity * fade)
+        })
+        .collect()
+}
+
 #[inline(always)]
 fn blend(bg: u8, fg: u8, alpha: f32) -> u8 {
     ((bg as f32 * (1.0 - alpha)) + (fg as f32 * alpha)) as u8
@@ -135,3 +254,152 @@ fn sample_bilinear_fast(cursor: &CursorSprite, x: f32, y: f32) -> Option<(u8, u8
         interp(tl.3, tr.3, bl.3, br.3),
     ))
 }
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn trail_ghosts_fade_out_behind_the_cursor() {
+        // 200ms at 30fps spans 6 frames: one ghost per frame back
+        let ghosts = trail_ghosts(200.0, 1000.0 / 30.0, 0.5);
+        assert_eq!(ghosts.len(), 6);
+        for (k, &(back_ms, opacity)) in ghosts.iter().enumerate() {
+            let want_back = (k + 1) as f64 * 1000.0 / 30.0;
+            assert!(
+                (back_ms - want_back).abs() < 1e-9,
+                "ghost {k} {back_ms}ms back"
+            );
+            let want_opacity = 0.5 * (6 - k) as f32 / 7.0;
+            assert!(
+                (opacity - want_opacity).abs() < 1e-6,
+                "ghost {k} opacity {opacity}"
+            );
+        }
+    }
+
+    #[test]
+    fn trail_ghosts_are_capped_and_spread() {
+        // 1s at 60fps would be 60 ghosts; the cap spreads 6 over the whole span
+        let ghosts = trail_ghosts(1000.0, 1000.0 / 60.0, 1.0);
+        assert_eq!(ghosts.len(), MAX_TRAIL_GHOSTS);
+        assert!((ghosts.last().unwrap().0 - 1000.0).abs() < 1e-9);
+    }
+
+    fn solid_sprite(width: u32, height: u32) -> CursorSprite {
+        CursorSprite {
+            data: [255u8, 255, 255, 255].repeat((width * height) as usize),
+            width,
+            height,
+            hotspot_x: 0.0,
+            hotspot_y: 0.0,
+        }
+    }
+
+    /// Red channel of each pixel in the first row of an RGBA frame
+    fn first_row(frame: &[u8], width: u32) -> Vec<u8> {
+        frame[..(width * 4) as usize]
+            .iter()
+            .step_by(4)
+            .copied()
+            .collect()
+    }
+
+    #[test]
+    fn motion_blur_draws_fading_copies_behind_fast_cursor() {
+        let sprite = solid_sprite(2, 2);
+        let (width, height) = (40, 2);
+        let black = [0u8, 0, 0, 255].repeat((width * height) as usize);
+
+        // 20px of travel at strength 0.5 is a 10px trail: three copies, 1/6,
+        // 1/3 and 1/2 opaque, at x = 20, 23.3 and 26.7 behind the cursor at 30
+        let mut frame = black.clone();
+        composite_cursor_motion_blur(
+            &mut frame,
+            width,
+            height,
+            &sprite,
+            (30.0, 0.0),
+            (20.0, 0.0),
+            0.5,
+            8.0,
+        );
+        let row = first_row(&frame, width);
+        for (x, want) in [(10, 0), (20, 42), (24, 85), (26, 0), (27, 127), (30, 255)] {
+            assert!(
+                (row[x] as i32 - want).abs() <= 1,
+                "pixel {x} is {}, want {want}: {row:?}",
+                row[x]
+            );
+        }
+
+        // Below the threshold only the sharp cursor is drawn
+        let mut frame = black.clone();
+        composite_cursor_motion_blur(
+            &mut frame,
+            width,
+            height,
+            &sprite,
+            (30.0, 0.0),
+            (5.0, 0.0),
+            0.5,
+            8.0,
+        );
+        let row = first_row(&frame, width);
+        assert!(row[..30].iter().all(|&v| v == 0), "{row:?}");
+        assert_eq!(row[30], 255);
+    }
+
+    #[test]
+    fn sprite_follows_the_shape_changes() {
+        let main = solid_sprite(1, 1);
+        let shapes = [solid_sprite(2, 2), solid_sprite(3, 3)];
+        let changes = [
+            CShapeChange {
+                timestamp_ms: 100.0,
+                sprite: 0,
+            },
+            CShapeChange {
+                timestamp_ms: 250.0,
+                sprite: -1,
+            },
+            CShapeChange {
+                timestamp_ms: 400.0,
+                sprite: 1,
+            },
+            CShapeChange {
+                timestamp_ms: 500.0,
+                sprite: 7,
+            }, // No such sprite
+        ];
+        let cursor = CursorArt {
+            main: &main,
+            shapes: &shapes,
+            changes: &changes,
+        };
+        for (at, want_width, want_shaped) in [
+            (0.0, 1, false),
+            (100.0, 2, true),
+            (249.9, 2, true),
+            (250.0, 1, false),
+            (450.0, 3, true),
+            (600.0, 1, false),
+            (f64::NAN, 1, false),
+        ] {
+            let (sprite, shaped) = cursor.sprite_at(at);
+            assert_eq!(
+                (sprite.width, shaped),
+                (want_width, want_shaped),
+                "at {at}ms"
+            );
+        }
+    }
+
+    #[test]
+    fn short_or_missing_trail_draws_nothing() {
+        assert!(trail_ghosts(0.0, 1000.0 / 60.0, 0.5).is_empty());
+        assert!(trail_ghosts(10.0, 1000.0 / 60.0, 0.5).is_empty()); // Under a frame
+        assert!(trail_ghosts(f64::NAN, 1000.0 / 60.0, 0.5).is_empty());
+        assert!(trail_ghosts(200.0, 0.0, 0.5).is_empty());
+    }
+}
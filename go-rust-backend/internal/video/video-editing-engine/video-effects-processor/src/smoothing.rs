@@ -1,6 +1,12 @@
 // Dual-pass cursor path smoothing: Physics filtering + Catmull-Rom interpolation
 use std::cmp::Ordering;
 
+/// Samples closer together than this are treated as the same knot
+const MIN_KNOT_SPACING_MS: f64 = 1e-3;
+
+/// Lower bound for centripetal knot intervals so stationary points never divide by zero
+const MIN_KNOT_INTERVAL: f32 = 1e-4;
+
 #[repr(C)]
 #[derive(Debug, Clone, Copy)]
 pub struct CPoint {
@@ -13,6 +19,11 @@ pub struct CPoint {
 // PASS 1: Physics-Based Filtering (Remove Jitter)
 // ============================================================================
 
+/// Largest physics integration step in seconds. Cursor samples can arrive at
+/// anything from a few Hz to 1kHz, so each gap is split into steps no larger
+/// than this to keep the spring stable at any sample rate.
+const MAX_PHYSICS_STEP: f32 = 0.004;
+
 /// Apply spring-damper physics to filter jitter at native sample rate
 pub fn apply_physics_filter(
     raw_points: &[CPoint],
@@ -46,19 +57,25 @@ pub fn apply_physics_filter(
         let target_x = raw_points[i].x;
         let target_y = raw_points[i].y;
 
-        // Spring-damper force calculation
-        let dx = target_x - x;
-        let dy = target_y - y;
-        let fx = tension * dx - friction * vx;
-        let fy = tension * dy - friction * vy;
-
-        // Semi-implicit Euler integration (stable)
-        let ax = fx / mass;
-        let ay = fy / mass;
-        vx += ax * dt;
-        vy += ay * dt;
-        x += vx * dt;
-        y += vy * dt;
+        // Integrate in fixed-size sub-steps so the result depends on elapsed
+        // time rather than on how often the cursor was sampled
+        let steps = (dt / MAX_PHYSICS_STEP).ceil().max(1.0);
+        let step = dt / steps;
+        for _ in 0..steps as usize {
+            // Spring-damper force calculation
+            let dx = target_x - x;
+            let dy = target_y - y;
+            let fx = tension * dx - friction * vx;
+            let fy = tension * dy - friction * vy;
+
+            // Semi-implicit Euler integration (stable)
+            let ax = fx / mass;
+            let ay = fy / mass;
+            vx += ax * step;
+            vy += ay * step;
+            x += vx * step;
+            y += vy * step;
+        }
 
         filtered.push(CPoint {
             x,
@@ -74,11 +91,13 @@ pub fn apply_physics_filter(
 // PASS 2: Catmull-Rom Spline Interpolation (Upsample to Frame Rate)
 // ============================================================================
 
-/// Interpolate sparse points to match video frame rate using Catmull-Rom splines
+/// Interpolate sparse points to match video frame rate using Catmull-Rom splines.
+/// Time picks the segment and how far along it each frame is; within the segment
+/// the curve is parameterised by chord length (alpha = 0.5 is centripetal).
 pub fn interpolate_to_framerate(
     clean_points: &[CPoint],
     frame_rate: i32,
-    _alpha: f32,
+    alpha: f32,
 ) -> Vec<CPoint> {
     // 1. Handle Empty Input
     if clean_points.is_empty() {
@@ -115,19 +134,15 @@ pub fn interpolate_to_framerate(
             break;
         }
 
-        let idx = match clean_points
-            .binary_search_by(|p| p.timestamp_ms.partial_cmp(&t_target).unwrap())
-        {
-            Ok(i) => i,
-            Err(i) => i,
-        };
-
+        // The segment p1..p2 spans t_target; the ends reuse their own sample
+        // as the missing neighbour
         let len = clean_points.len();
-        // Safe indexing with boundary clamping
-        let i1 = idx.min(len - 1);
+        let i1 = find_segment_index(clean_points, t_target)
+            .saturating_sub(1)
+            .min(len - 2);
         let i0 = i1.saturating_sub(1);
-        let i2 = (i1 + 1).min(len - 1);
-        let i3 = (i1 + 2).min(len - 1);
+        let i2 = i1 + 1;
+        let i3 = (i2 + 1).min(len - 1);
 
         let p0 = &clean_points[i0];
         let p1 = &clean_points[i1];
@@ -144,29 +159,9 @@ pub fn interpolate_to_framerate(
             continue;
         }
 
-        let t = t_target as f32;
-        let x = catmull_rom_1d(
-            t,
-            p0.timestamp_ms as f32,
-            p1.timestamp_ms as f32,
-            p2.timestamp_ms as f32,
-            p3.timestamp_ms as f32,
-            p0.x,
-            p1.x,
-            p2.x,
-            p3.x,
-        );
-        let y = catmull_rom_1d(
-            t,
-            p0.timestamp_ms as f32,
-            p1.timestamp_ms as f32,
-            p2.timestamp_ms as f32,
-            p3.timestamp_ms as f32,
-            p0.y,
-            p1.y,
-            p2.y,
-            p3.y,
-        );
+        let u =
+            ((t_target - p1.timestamp_ms) / (p2.timestamp_ms - p1.timestamp_ms)).clamp(0.0, 1.0);
+        let (x, y) = catmull_rom_point(u as f32, p0, p1, p2, p3, alpha);
 
         dense_path.push(CPoint {
             x,
@@ -183,8 +178,7 @@ pub fn interpolate_to_framerate(
     dense_path
 }
 
-/// Evaluate Catmull-Rom spline at parameter t using Barry-Goldman algorithm
-#[allow(dead_code)]
+/// Evaluate Catmull-Rom spline at parameter t (0..1 from p1 to p2) using Barry-Goldman algorithm
 fn catmull_rom_point(
     t: f32,
     p0: &CPoint,
@@ -193,10 +187,11 @@ fn catmull_rom_point(
     p3: &CPoint,
     alpha: f32,
 ) -> (f32, f32) {
-    // Knot intervals based on chord length
-    let d01 = distance(p0, p1).powf(alpha).max(1e-6);
-    let d12 = distance(p1, p2).powf(alpha).max(1e-6);
-    let d23 = distance(p2, p3).powf(alpha).max(1e-6);
+    // Knot intervals based on chord length: |p_i+1 - p_i|^alpha (alpha = 0.5 is centripetal).
+    // Stationary cursors produce zero-length chords, so clamp to a small positive interval.
+    let d01 = distance(p0, p1).powf(alpha).max(MIN_KNOT_INTERVAL);
+    let d12 = distance(p1, p2).powf(alpha).max(MIN_KNOT_INTERVAL);
+    let d23 = distance(p2, p3).powf(alpha).max(MIN_KNOT_INTERVAL);
 
     let t0 = 0.0;
     let t1 = t0 + d01;
@@ -246,14 +241,13 @@ fn catmull_rom_1d(
     safe_lerp(b1, b2, t1, t2, t)
 }
 
-#[allow(dead_code)]
 fn distance(a: &CPoint, b: &CPoint) -> f32 {
     let dx = b.x - a.x;
     let dy = b.y - a.y;
     (dx * dx + dy * dy).sqrt()
 }
 
-#[allow(dead_code)]
+/// Index of the first point at or after timestamp (a NaN timestamp gives 0)
 fn find_segment_index(points: &[CPoint], timestamp: f64) -> usize {
     match points.binary_search_by(|p| {
         if p.timestamp_ms < timestamp {
@@ -283,8 +277,14 @@ pub fn smooth_cursor_path_dual_pass(
         return Vec::new();
     }
 
+    // Drop duplicate/out-of-order samples before any timestamp math
+    let sanitized_points = sanitize_points(raw_points);
+    if sanitized_points.is_empty() {
+        return Vec::new();
+    }
+
     // Normalize timestamps to milliseconds (detect if input is in seconds)
-    let normalized_points = normalize_to_relative_ms(raw_points);
+    let normalized_points = normalize_to_relative_ms(&sanitized_points);
 
     let filtered = apply_physics_filter(&normalized_points, responsiveness, smoothness);
     let upsampled = interpolate_to_framerate(&filtered, frame_rate, spline_alpha);
@@ -292,6 +292,46 @@ pub fn smooth_cursor_path_dual_pass(
     upsampled
 }
 
+/// Prepare raw samples for spline fitting.
+/// Click events are captured on a separate thread, so they can arrive out of order
+/// or share a timestamp with a movement sample. Two knots with the same timestamp
+/// make the spline segment zero-length, so we sort by time and collapse duplicates
+/// (keeping the most recent position). Non-finite samples are dropped entirely.
+fn sanitize_points(points: &[CPoint]) -> Vec<CPoint> {
+    let mut sorted: Vec<CPoint> = points
+        .iter()
+        .filter(|p| p.x.is_finite() && p.y.is_finite() && p.timestamp_ms.is_finite())
+        .copied()
+        .collect();
+
+    // Stable sort keeps capture order for equal timestamps
+    sorted.sort_by(|a, b| {
+        a.timestamp_ms
+            .partial_cmp(&b.timestamp_ms)
+            .unwrap_or(Ordering::Equal)
+    });
+
+    let mut deduped: Vec<CPoint> = Vec::with_capacity(sorted.len());
+    for p in sorted {
+        match deduped.last_mut() {
+            Some(last) if (p.timestamp_ms - last.timestamp_ms).abs() < MIN_KNOT_SPACING_MS => {
+                *last = p;
+            }
+            _ => deduped.push(p),
+        }
+    }
+
+    if deduped.len() < points.len() {
+        log::debug!(
+            "Sanitized cursor samples: {} -> {}",
+            points.len(),
+            deduped.len()
+        );
+    }
+
+    deduped
+}
+
 /// Detect timestamp units and convert to milliseconds if needed.
 /// Heuristic: If the last timestamp is < 10000 and duration < 1000, assume seconds.
 fn normalize_to_relative_ms(points: &[CPoint]) -> Vec<CPoint> {
@@ -333,3 +373,112 @@ fn normalize_to_relative_ms(points: &[CPoint]) -> Vec<CPoint> {
 
     relative_points
 }
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    fn point(x: f32, y: f32, timestamp_ms: f64) -> CPoint {
+        CPoint { x, y, timestamp_ms }
+    }
+
+    fn assert_finite(path: &[CPoint]) {
+        assert!(!path.is_empty());
+        for (i, p) in path.iter().enumerate() {
+            assert!(
+                p.x.is_finite() && p.y.is_finite(),
+                "frame {i} is ({}, {})",
+                p.x,
+                p.y
+            );
+        }
+    }
+
+    #[test]
+    fn stationary_cursor_stays_put() {
+        let raw: Vec<CPoint> = (0..20)
+            .map(|i| point(500.0, 300.0, i as f64 * 50.0))
+            .collect();
+        for alpha in [0.0, 0.5, 1.0] {
+            let path = interpolate_to_framerate(&raw, 60, alpha);
+            assert_finite(&path);
+            for p in &path {
+                assert_eq!((p.x, p.y), (500.0, 300.0));
+            }
+        }
+    }
+
+    #[test]
+    fn pause_between_moves_has_no_nan() {
+        // Repeated positions give zero-length chords on either side of a moving segment
+        let raw = [
+            point(0.0, 0.0, 0.0),
+            point(0.0, 0.0, 100.0),
+            point(0.0, 0.0, 200.0),
+            point(100.0, 0.0, 300.0),
+            point(100.0, 0.0, 400.0),
+            point(100.0, 0.0, 500.0),
+        ];
+        let path = interpolate_to_framerate(&raw, 60, 0.5);
+        assert_finite(&path);
+        assert_eq!((path[0].x, path[0].y), (0.0, 0.0));
+    }
+
+    #[test]
+    fn collinear_points_stay_on_the_line() {
+        // Evenly spaced along y = 2x, so the curve can't leave the line or the samples' span
+        let raw: Vec<CPoint> = (0..10)
+            .map(|i| point(i as f32 * 10.0, i as f32 * 20.0, i as f64 * 100.0))
+            .collect();
+        for alpha in [0.0, 0.5, 1.0] {
+            let path = interpolate_to_framerate(&raw, 30, alpha);
+            assert_finite(&path);
+            for p in &path {
+                assert!(
+                    (p.y - 2.0 * p.x).abs() < 1e-2,
+                    "({}, {}) is off the line",
+                    p.x,
+                    p.y
+                );
+                assert!((-1e-3..=90.001).contains(&p.x), "x {} overshoots", p.x);
+            }
+        }
+    }
+
+    #[test]
+    fn frames_pass_through_the_samples() {
+        let raw = [
+            point(0.0, 0.0, 0.0),
+            point(300.0, 50.0, 100.0),
+            point(320.0, 400.0, 200.0),
+            point(0.0, 420.0, 300.0),
+        ];
+        // At 10fps every frame lands on a sample
+        let path = interpolate_to_framerate(&raw, 10, 0.5);
+        assert_eq!(path.len(), 3);
+        for (p, want) in path.iter().zip(&raw) {
+            assert!(
+                (p.x - want.x).abs() < 1e-3 && (p.y - want.y).abs() < 1e-3,
+                "({}, {}) at {}ms, want ({}, {})",
+                p.x,
+                p.y,
+                p.timestamp_ms,
+                want.x,
+                want.y
+            );
+        }
+    }
+
+    #[test]
+    fn duplicate_timestamps_have_no_nan() {
+        let raw = [
+            point(0.0, 0.0, 0.0),
+            point(10.0, 10.0, 16.0),
+            point(10.0, 10.0, 16.0),
+            point(40.0, 10.0, 33.0),
+            point(f32::NAN, 0.0, 40.0),
+        ];
+        let path = smooth_cursor_path_dual_pass(&raw, 60, 0.5, 0.5, 0.5);
+        assert_finite(&path);
+    }
+}
@@ -1,4 +1,6 @@
-use crate::renderer::{composite_cursor_subpixel, CursorSprite};
+use crate::renderer::{
+    composite_cursor_motion_blur, composite_cursor_subpixel, trail_ghosts, CursorArt, CursorSprite,
+};
 use crate::smoothing::CPoint;
 use crate::VideoProcessingConfig;
 use ffmpeg::format::{input, output, Pixel};
@@ -13,13 +15,26 @@ use std::error::Error;
 // Main Video Processing Function
 // ============================================================================
 
+/// Returned by process_video when the caller asked it to stop early
+#[derive(Debug)]
+pub struct Cancelled;
+
+impl std::fmt::Display for Cancelled {
+    fn fmt(&self, f: &mut std::fmt::Formatter<'_>) -> std::fmt::Result {
+        write!(f, "processing cancelled")
+    }
+}
+
+impl Error for Cancelled {}
+
 pub fn process_video(
     input_path: &str,
     output_path: &str,
     cursor_points: &[CPoint],
-    cursor_sprite: &CursorSprite,
+    cursor: &CursorArt,
     config: &VideoProcessingConfig,
     mut progress_callback: impl FnMut(f32),
+    is_cancelled: impl Fn() -> bool,
 ) -> Result<(), Box<dyn Error>> {
     log::info!(
         "Starting video processing: {} -> {}",
@@ -157,6 +172,11 @@ pub fn process_video(
     let mut frame_count = 0i64;
 
     for (stream, packet) in input_ctx.packets() {
+        // Polled once per packet so an abort lands within a frame or two
+        if is_cancelled() {
+            log::info!("Cancelled after {} frames", frame_count);
+            return Err(Box::new(Cancelled));
+        }
         if stream.index() == video_stream_idx {
             decoder.send_packet(&packet)?;
 
@@ -188,8 +208,9 @@ pub fn process_video(
                         &mut encoder,
                         &mut reverse_scaler,
                         &mut output_ctx,
-                        cursor_sprite,
+                        cursor,
                         &cursor_lookup,
+                        config,
                         frame_count,
                         &mut progress_callback,
                         estimated_total_frames,
@@ -212,8 +233,9 @@ pub fn process_video(
                 &mut encoder,
                 &mut reverse_scaler,
                 &mut output_ctx,
-                cursor_sprite,
+                cursor,
                 &cursor_lookup,
+                config,
                 frame_count,
                 &mut progress_callback,
                 estimated_total_frames,
@@ -231,8 +253,9 @@ pub fn process_video(
             &mut encoder,
             &mut reverse_scaler,
             &mut output_ctx,
-            cursor_sprite,
+            cursor,
             &cursor_lookup,
+            config,
             frame_count,
             &mut progress_callback,
             estimated_total_frames,
@@ -276,8 +299,9 @@ fn process_single_frame(
     encoder: &mut encoder::Video,
     reverse_scaler: &mut ScalerContext,
     output_ctx: &mut ffmpeg::format::context::Output,
-    cursor_sprite: &CursorSprite,
+    cursor: &CursorArt,
     cursor_lookup: &[(f64, f32, f32)],
+    config: &VideoProcessingConfig,
     frame_count: i64,
     progress_callback: &mut impl FnMut(f32),
     total_estimated: u64,
@@ -288,9 +312,34 @@ fn process_single_frame(
         encoder.time_base().numerator() as f64 / encoder.time_base().denominator() as f64;
     let timestamp_ms = frame_count as f64 * time_base_seconds * 1000.0;
 
-    // B. Cursor Overlay
+    // B. Cursor Overlay (velocity is the distance travelled since the previous frame)
     let (cx, cy) = interpolate_cursor_position(cursor_lookup, timestamp_ms);
-    overlay_cursor_on_frame(cfr_frame, cursor_sprite, cx, cy)?;
+    let frame_ms = time_base_seconds * 1000.0;
+    let (px, py) = interpolate_cursor_position(cursor_lookup, timestamp_ms - frame_ms);
+    // A shape sprite (I-beam, hand) replaces the arrow and its trail
+    let (cursor_sprite, shaped) = cursor.sprite_at(timestamp_ms);
+    let trail_ms = if shaped {
+        0.0
+    } else {
+        config.trail_duration_ms as f64
+    };
+    let trail: Vec<((f32, f32), f32)> = trail_ghosts(trail_ms, frame_ms, config.trail_opacity)
+        .into_iter()
+        .map(|(back_ms, opacity)| {
+            (
+                interpolate_cursor_position(cursor_lookup, timestamp_ms - back_ms),
+                opacity,
+            )
+        })
+        .collect();
+    overlay_cursor_on_frame(
+        cfr_frame,
+        cursor_sprite,
+        (cx, cy),
+        (cx - px, cy - py),
+        &trail,
+        config,
+    )?;
 
     // C. Convert to YUV (H.264 format)
     let mut yuv_frame = VideoFrame::empty();
@@ -310,11 +359,14 @@ fn process_single_frame(
     Ok(())
 }
 
+/// Draw the trail ghosts (nearest first in `trail`), then the cursor itself
 fn overlay_cursor_on_frame(
     frame: &mut VideoFrame,
     cursor_sprite: &CursorSprite,
-    x: f32,
-    y: f32,
+    position: (f32, f32),
+    velocity: (f32, f32),
+    trail: &[((f32, f32), f32)],
+    config: &VideoProcessingConfig,
 ) -> Result<(), Box<dyn Error>> {
     // Frame is guaranteed RGBA by filter graph
     let width = frame.width();
@@ -327,7 +379,37 @@ fn overlay_cursor_on_frame(
 
     // Call renderer (Update your renderer.rs to accept stride!)
     // If renderer.rs is not updated, this assumes stride == width * 4 (Risky but common)
-    composite_cursor_subpixel(data, width, height, cursor_sprite, x, y);
+    // Place the sprite so its hotspot, not its top-left corner, sits on the cursor
+    let origin = (
+        position.0 - cursor_sprite.hotspot_x,
+        position.1 - cursor_sprite.hotspot_y,
+    );
+    // Farthest ghost first so nearer ones sit on top. Ghosts under the cursor,
+    // as they all are while it rests, are skipped so they don't darken it.
+    for &((gx, gy), opacity) in trail.iter().rev() {
+        if (gx - position.0).hypot(gy - position.1) < 2.0 {
+            continue;
+        }
+        composite_cursor_subpixel(
+            data,
+            width,
+            height,
+            cursor_sprite,
+            gx - cursor_sprite.hotspot_x,
+            gy - cursor_sprite.hotspot_y,
+            opacity,
+        );
+    }
+    composite_cursor_motion_blur(
+        data,
+        width,
+        height,
+        cursor_sprite,
+        origin,
+        velocity,
+        config.motion_blur_strength,
+        config.motion_blur_threshold,
+    );
 
     Ok(())
 }
@@ -384,7 +466,10 @@ fn encode_and_write(
 
         // Rescale timestamps from encoder time_base to output stream time_base
         let encoder_tb = encoder.time_base();
-        let stream_tb = output_ctx.stream(0).map(|s| s.time_base()).unwrap_or(encoder_tb);
+        let stream_tb = output_ctx
+            .stream(0)
+            .map(|s| s.time_base())
+            .unwrap_or(encoder_tb);
         packet.rescale_ts(encoder_tb, stream_tb);
 
         packet.write_interleaved(output_ctx)?;
@@ -408,10 +493,8 @@ fn interpolate_cursor_position(lookup: &[(f64, f32, f32)], timestamp_ms: f64) ->
         return (0.0, 0.0);
     }
 
-    let idx = match lookup.binary_search_by(|p| p.0.partial_cmp(&timestamp_ms).unwrap()) {
-        Ok(i) => i,
-        Err(i) => i,
-    };
+    // A NaN timestamp compares false and lands on the first sample instead of panicking
+    let idx = lookup.partition_point(|p| p.0 < timestamp_ms);
 
     if idx == 0 {
         return (lookup[0].1, lookup[0].2);
@@ -432,3 +515,29 @@ fn interpolate_cursor_position(lookup: &[(f64, f32, f32)], timestamp_ms: f64) ->
     let t = ((timestamp_ms - t0) / dt) as f32;
     (x0 + (x1 - x0) * t, y0 + (y1 - y0) * t)
 }
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn cursor_lookup_interpolates_between_samples() {
+        let lookup = [(0.0, 0.0, 0.0), (100.0, 100.0, 50.0), (200.0, 100.0, 150.0)];
+        assert_eq!(interpolate_cursor_position(&lookup, 50.0), (50.0, 25.0));
+        assert_eq!(interpolate_cursor_position(&lookup, 100.0), (100.0, 50.0));
+        assert_eq!(interpolate_cursor_position(&lookup, -10.0), (0.0, 0.0));
+        assert_eq!(interpolate_cursor_position(&lookup, 500.0), (100.0, 150.0));
+    }
+
+    #[test]
+    fn cursor_lookup_survives_nan() {
+        let lookup = [
+            (0.0, 10.0, 20.0),
+            (f64::NAN, 30.0, 40.0),
+            (200.0, 50.0, 60.0),
+        ];
+        assert_eq!(interpolate_cursor_position(&lookup, f64::NAN), (10.0, 20.0));
+        // A NaN sample gives a NaN position around it rather than a panic
+        interpolate_cursor_position(&lookup, 150.0);
+    }
+}
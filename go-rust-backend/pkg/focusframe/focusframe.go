@@ -0,0 +1,102 @@
+// Package focusframe records the screen with cursor tracking and renders the
+// cursor-following edit, for embedding in other Go programs.
+//
+// A typical session records, stops, then edits:
+//
+//	cfg := focusframe.DefaultConfig()
+//	cfg.Recording.OutputDir = "/tmp/captures"
+//
+//	recorder, err := focusframe.NewRecorder(cfg, nil)
+//	if err != nil {
+//		return err
+//	}
+//	if err := recorder.Start("demo"); err != nil {
+//		return err
+//	}
+//	time.Sleep(10 * time.Second)
+//	rec, err := recorder.Stop()
+//	if err != nil {
+//		return err
+//	}
+//
+//	pipeline, err := focusframe.NewPipeline(cfg)
+//	if err != nil {
+//		return err
+//	}
+//	edited, err := pipeline.EditRecording(ctx, rec, nil)
+//
+// Nothing in this package exits the process or draws to the terminal;
+// failures come back as errors and progress only goes to the callbacks
+// given. Logging goes to the logger passed in, or slog.Default where none
+// is taken.
+//
+// The exported identifiers here follow semantic versioning. Config is the
+// same structure the focusframe.yaml file decodes into, so its fields are
+// covered too; everything under internal/ is not.
+//
+// Capture currently needs macOS and ffmpeg on PATH. Cursor tracking uses a
+// process-wide input hook, so only one Recorder or Tracker can track at a
+// time.
+package focusframe
+
+import (
+	"github.com/vedantwpatil/Screen-Capture/internal/config"
+	"github.com/vedantwpatil/Screen-Capture/internal/recording"
+	"github.com/vedantwpatil/Screen-Capture/internal/tracking"
+)
+
+// Config holds every recording, effect and export setting
+type Config = config.Config
+
+// CursorSample is one tracked cursor position or click, timed from the
+// start of the recording
+type CursorSample = tracking.CursorPosition
+
+// RecorderEvent is one update from a Recorder: started, periodic progress,
+// a warning, stopped or failed. RecorderStats carries ffmpeg's numbers.
+type (
+	RecorderEvent = recording.Event
+	RecorderStats = recording.Stats
+)
+
+// Kinds of RecorderEvent
+const (
+	EventStarted  = recording.EventStarted
+	EventProgress = recording.EventProgress
+	EventWarning  = recording.EventWarning
+	EventStopped  = recording.EventStopped
+	EventError    = recording.EventError
+)
+
+// ErrInvalidName is returned by Recorder.Start for a name that isn't a plain
+// file name, or that would resolve outside the output directory
+var ErrInvalidName = recording.ErrInvalidName
+
+// ErrTrackerBusy is returned when cursor tracking is already running
+// elsewhere in the process
+var ErrTrackerBusy = tracking.ErrTrackerBusy
+
+// DefaultConfig returns the settings used when no config file is present
+func DefaultConfig() *Config {
+	return config.NewConfig()
+}
+
+// LoadConfig reads a focusframe.yaml file over the defaults and validates
+// the result. An empty path searches the usual locations and falls back to
+// the defaults when none exists.
+func LoadConfig(path string) (*Config, error) {
+	cfg, err := config.Load(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// CursorPath returns where the cursor data for a recording is saved,
+// e.g. demo.cursor.json for demo.mp4
+func CursorPath(videoPath string) string {
+	return tracking.HistoryPath(videoPath)
+}
@@ -0,0 +1,80 @@
+package focusframe
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/vedantwpatil/Screen-Capture/internal/editing"
+)
+
+// Edit describes one render of a finished recording
+type Edit struct {
+	InputPath  string
+	OutputPath string
+	Cursor     []CursorSample
+	StartedAt  time.Time // Wall-clock start of the capture, used by the timecode
+
+	// Progress receives the cursor render's completion from 0 to 1; nil
+	// reports nothing
+	Progress func(percent float32)
+}
+
+// Pipeline renders the configured effects onto recordings
+type Pipeline struct {
+	cfg *Config
+}
+
+// NewPipeline validates cfg and returns a pipeline using it. The config is
+// read on every edit, so it must not be modified while one is running.
+func NewPipeline(cfg *Config) (*Pipeline, error) {
+	if cfg == nil {
+		return nil, fmt.Errorf("focusframe: config is required")
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("focusframe: invalid config: %w", err)
+	}
+	return &Pipeline{cfg: cfg}, nil
+}
+
+// Run renders one edit. Cancelling ctx stops it and removes partial output.
+func (p *Pipeline) Run(ctx context.Context, edit Edit) error {
+	progress := edit.Progress
+	if progress == nil {
+		// editing draws a terminal progress line when given none
+		progress = func(float32) {}
+	}
+	return editing.Edit(ctx, p.cfg, editing.Request{
+		InputPath:    edit.InputPath,
+		OutputPath:   edit.OutputPath,
+		MouseHistory: edit.Cursor,
+		StartTime:    edit.StartedAt,
+		Progress:     progress,
+	})
+}
+
+// EditRecording renders rec to <name>-edited.mp4 next to it, using the
+// cursor data saved with it, and returns the edited file's path
+func (p *Pipeline) EditRecording(ctx context.Context, rec Recording, progress func(percent float32)) (string, error) {
+	cursorPath := rec.CursorPath
+	if cursorPath == "" {
+		cursorPath = CursorPath(rec.VideoPath)
+	}
+	samples, startedAt, err := LoadCursor(cursorPath)
+	if err != nil {
+		return "", err
+	}
+
+	output := editing.EditedPath(rec.VideoPath)
+	err = p.Run(ctx, Edit{
+		InputPath:  rec.VideoPath,
+		OutputPath: output,
+		Cursor:     samples,
+		StartedAt:  startedAt,
+		Progress:   progress,
+	})
+	if err != nil {
+		return "", err
+	}
+	return output, nil
+}
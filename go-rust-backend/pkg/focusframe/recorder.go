@@ -0,0 +1,119 @@
+package focusframe
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"time"
+
+	"github.com/vedantwpatil/Screen-Capture/internal/recording"
+)
+
+// Recording is a finished capture and its cursor data
+type Recording struct {
+	VideoPath  string
+	CursorPath string
+	// ProjectPath is the <name>.focusframe bundle, empty when
+	// Recording.SaveProject is off or bundling failed
+	ProjectPath string
+	StartedAt   time.Time
+	Duration    time.Duration
+	// Warnings raised while capturing, e.g. dropped frames or a filling disk
+	Warnings []string
+}
+
+// Recorder captures the screen to <OutputDir>/<name>.mp4 while tracking the
+// cursor. One Recorder records one capture at a time and can be reused.
+type Recorder struct {
+	recorder *recording.Recorder
+	logger   *slog.Logger
+}
+
+// NewRecorder validates cfg and returns an idle recorder. A nil logger
+// discards log output.
+func NewRecorder(cfg *Config, logger *slog.Logger) (*Recorder, error) {
+	if cfg == nil {
+		return nil, fmt.Errorf("focusframe: config is required")
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("focusframe: invalid config: %w", err)
+	}
+	if logger == nil {
+		logger = slog.New(slog.NewTextHandler(io.Discard, nil))
+	}
+	return &Recorder{recorder: recording.NewRecorder(cfg, logger), logger: logger}, nil
+}
+
+// Start begins capturing under the given base file name, without extension
+func (r *Recorder) Start(name string) error {
+	return r.recorder.Start(name)
+}
+
+// Stop ends the capture, waits for the video to be finalized and saves the
+// cursor data next to it. It also collects a capture that already ended on
+// its own, returning Err if that was a failure.
+func (r *Recorder) Stop() (Recording, error) {
+	if r.recorder.IsRecording() {
+		if err := r.recorder.Stop(); err != nil {
+			return Recording{}, err
+		}
+	}
+	if err := r.recorder.Err(); err != nil {
+		return Recording{}, err
+	}
+	if !r.recorder.IsDone() {
+		return Recording{}, errors.New("focusframe: no recording to stop")
+	}
+	cursorPath, err := r.recorder.SaveCursorHistory()
+	if err != nil {
+		return Recording{}, err
+	}
+	// A recording without its project is still usable, so this isn't fatal
+	projectPath, err := r.recorder.SaveProject()
+	if err != nil {
+		r.logger.Warn("Failed to save project", "err", err)
+	}
+
+	return Recording{
+		VideoPath:   r.recorder.GetOutputPath(),
+		CursorPath:  cursorPath,
+		ProjectPath: projectPath,
+		StartedAt:   r.recorder.GetStartTime(),
+		Duration:    time.Since(r.recorder.GetStartTime()),
+		Warnings:    r.recorder.Warnings(),
+	}, nil
+}
+
+// Discard stops any capture in progress and deletes what it wrote
+func (r *Recorder) Discard() error {
+	return r.recorder.Discard()
+}
+
+// Recording reports whether a capture is in progress
+func (r *Recorder) Recording() bool {
+	return r.recorder.IsRecording()
+}
+
+// Done is closed when the current capture ends, including when ffmpeg fails
+// on its own; Err then says why
+func (r *Recorder) Done() <-chan struct{} {
+	return r.recorder.Done()
+}
+
+// Err reports why the last capture failed, or nil
+func (r *Recorder) Err() error {
+	return r.recorder.Err()
+}
+
+// Subscribe streams the recorder's events until the returned function is
+// called. Events are never waited for; a subscriber that falls behind loses
+// its oldest ones, so read promptly and call the function when done.
+func (r *Recorder) Subscribe() (<-chan RecorderEvent, func()) {
+	return r.recorder.Subscribe()
+}
+
+// CursorSamples returns the cursor data tracked so far
+func (r *Recorder) CursorSamples() []CursorSample {
+	return r.recorder.GetCursorHistory()
+}
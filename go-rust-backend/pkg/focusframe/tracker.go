@@ -0,0 +1,50 @@
+package focusframe
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"time"
+
+	"github.com/vedantwpatil/Screen-Capture/internal/tracking"
+)
+
+// Tracker samples the cursor without recording the screen, for programs
+// that capture video some other way
+type Tracker struct {
+	tracker *tracking.Tracker
+}
+
+// NewTracker returns a tracker sampling sampleHz times a second. A nil
+// logger discards log output.
+func NewTracker(sampleHz int, logger *slog.Logger) (*Tracker, error) {
+	if sampleHz < 1 || sampleHz > 1000 {
+		return nil, fmt.Errorf("focusframe: sample rate must be between 1 and 1000 Hz, got %d", sampleHz)
+	}
+	if logger == nil {
+		logger = slog.New(slog.NewTextHandler(io.Discard, nil))
+	}
+	return &Tracker{tracker: tracking.NewTracker(logger, sampleHz)}, nil
+}
+
+// Run tracks until ctx is cancelled, timing samples from startedAt. It
+// returns ErrTrackerBusy if tracking is already running in this process.
+func (t *Tracker) Run(ctx context.Context, startedAt time.Time) error {
+	return t.tracker.Run(ctx, startedAt)
+}
+
+// Samples returns a copy of everything tracked so far
+func (t *Tracker) Samples() []CursorSample {
+	return t.tracker.Samples()
+}
+
+// SaveCursor writes samples in the format Pipeline.EditRecording reads
+func SaveCursor(path string, startedAt time.Time, samples []CursorSample) error {
+	return tracking.SaveHistory(path, startedAt, samples)
+}
+
+// LoadCursor reads cursor data saved by a Recorder or SaveCursor
+func LoadCursor(path string) ([]CursorSample, time.Time, error) {
+	return tracking.LoadHistory(path)
+}